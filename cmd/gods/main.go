@@ -0,0 +1,46 @@
+// Command gods is a REPL over the gods trees and heap, to explore them or to replay the
+// steps of a bug report without writing a Go program. Commands come from the files given
+// as arguments, or from the standard input:
+//
+//   $ gods
+//   gods> new avl
+//   gods> put 5 five
+//   gods> put 3 three
+//   gods> floor 4
+//   3 three
+//   gods> print
+//
+// Type help for the list of commands.
+package main
+
+import (
+  "fmt"
+  "os"
+)
+
+func main() {
+  r := &repl{out: os.Stdout}
+  if len(os.Args) > 1 {
+    for _, name := range os.Args[1:] {
+      file, err := os.Open(name)
+      if err != nil {
+        fmt.Fprintln(os.Stderr, err)
+        os.Exit(1)
+      }
+      err = r.run(file)
+      file.Close()
+      if err != nil {
+        fmt.Fprintln(os.Stderr, err)
+        os.Exit(1)
+      }
+    }
+    return
+  }
+  if info, err := os.Stdin.Stat(); err == nil && info.Mode()&os.ModeCharDevice != 0 {
+    r.prompt = "gods> "
+  }
+  if err := r.run(os.Stdin); err != nil {
+    fmt.Fprintln(os.Stderr, err)
+    os.Exit(1)
+  }
+}
@@ -0,0 +1,187 @@
+package main
+
+import (
+  "bufio"
+  "errors"
+  "fmt"
+  "io"
+  "os"
+  "strconv"
+  "strings"
+)
+
+const help = `commands:
+  new avl|bst|btree [order]|redblack|heap   start over with an empty structure
+  put KEY [VALUE]    store VALUE under KEY (a BST keeps duplicates, the heap pushes KEY)
+  del [KEY...]       remove keys (the heap pops its top)
+  get KEY            value stored under KEY
+  floor KEY          largest key <= KEY and its value
+  range LO HI        keys between LO and HI, both included
+  height             number of levels of the structure, 0 when empty
+  size               number of keys
+  print              draw the structure
+  json               structure as JSON
+  save FILE          write the JSON into FILE
+  load FILE          replace the contents with the JSON of FILE
+  help               this text
+  quit               leave`
+
+// repl reads commands line by line and runs them on the current structure
+type repl struct {
+  out       io.Writer
+  prompt    string
+  kind      string
+  structure structure
+}
+
+// run executes every line of in, errors are reported and do not stop the session
+func (r *repl) run(in io.Reader) error {
+  scanner := bufio.NewScanner(in)
+  for {
+    fmt.Fprint(r.out, r.prompt)
+    if !scanner.Scan() {
+      break
+    }
+    fields := strings.Fields(scanner.Text())
+    if len(fields) == 0 || strings.HasPrefix(fields[0], "#") {
+      continue
+    }
+    if fields[0] == "quit" || fields[0] == "exit" {
+      return nil
+    }
+    if err := r.exec(fields[0], fields[1:]); err != nil {
+      fmt.Fprintf(r.out, "error: %v\n", err)
+    }
+  }
+  return scanner.Err()
+}
+
+// exec runs a single command
+func (r *repl) exec(command string, args []string) error {
+  switch command {
+  case "help":
+    fmt.Fprintln(r.out, help)
+    return nil
+  case "new":
+    if len(args) == 0 {
+      return errors.New("usage: new avl|bst|btree [order]|redblack|heap")
+    }
+    s, err := newStructure(args[0], args[1:])
+    if err != nil {
+      return err
+    }
+    r.kind, r.structure = args[0], s
+    return nil
+  }
+  if r.structure == nil {
+    return errors.New("no structure yet, start with new avl|bst|btree [order]|redblack|heap")
+  }
+  keys, err := parseKeys(command, args)
+  if err != nil {
+    return err
+  }
+  switch command {
+  case "put":
+    if len(args) == 0 {
+      return errors.New("usage: put KEY [VALUE]")
+    }
+    return r.structure.put(keys[0], strings.Join(args[1:], " "))
+  case "del":
+    top, err := r.structure.del(keys)
+    if top != "" {
+      fmt.Fprintln(r.out, top)
+    }
+    return err
+  case "get":
+    if len(keys) != 1 {
+      return errors.New("usage: get KEY")
+    }
+    value, found, err := r.structure.get(keys[0])
+    if err == nil {
+      r.print(value, found)
+    }
+    return err
+  case "floor":
+    if len(keys) != 1 {
+      return errors.New("usage: floor KEY")
+    }
+    key, value, found, err := r.structure.floor(keys[0])
+    if err == nil {
+      r.print(fmt.Sprint(key, " ", value), found)
+    }
+    return err
+  case "range":
+    if len(keys) != 2 {
+      return errors.New("usage: range LO HI")
+    }
+    lines, err := r.structure.span(keys[0], keys[1])
+    for _, line := range lines {
+      fmt.Fprintln(r.out, line)
+    }
+    return err
+  case "height":
+    fmt.Fprintln(r.out, r.structure.height())
+  case "size":
+    fmt.Fprintln(r.out, r.structure.size())
+  case "print":
+    fmt.Fprint(r.out, r.structure)
+    if s := r.structure.String(); s != "" && !strings.HasSuffix(s, "\n") {
+      fmt.Fprintln(r.out)
+    }
+  case "json":
+    data, err := r.structure.ToJSON()
+    if err != nil {
+      return err
+    }
+    fmt.Fprintln(r.out, string(data))
+  case "save":
+    if len(args) != 1 {
+      return errors.New("usage: save FILE")
+    }
+    data, err := r.structure.ToJSON()
+    if err != nil {
+      return err
+    }
+    return os.WriteFile(args[0], append(data, '\n'), 0644)
+  case "load":
+    if len(args) != 1 {
+      return errors.New("usage: load FILE")
+    }
+    data, err := os.ReadFile(args[0])
+    if err != nil {
+      return err
+    }
+    return r.structure.FromJSON(data)
+  default:
+    return fmt.Errorf("unknown command %q, try help", command)
+  }
+  return nil
+}
+
+func (r *repl) print(line string, found bool) {
+  if found {
+    fmt.Fprintln(r.out, line)
+  } else {
+    fmt.Fprintln(r.out, "not found")
+  }
+}
+
+// parseKeys parses the integer keys of the commands taking keys, put only has one
+func parseKeys(command string, args []string) ([]int, error) {
+  switch command {
+  case "put":
+    args = args[:min(len(args), 1)]
+  case "del", "get", "floor", "range":
+  default:
+    return nil, nil
+  }
+  keys := make([]int, len(args))
+  for i, arg := range args {
+    key, err := strconv.Atoi(arg)
+    if err != nil {
+      return nil, fmt.Errorf("key %q is not an integer", arg)
+    }
+    keys[i] = key
+  }
+  return keys, nil
+}
@@ -0,0 +1,93 @@
+package main
+
+import (
+  "path/filepath"
+  "strings"
+  "testing"
+)
+
+// session runs script in a fresh REPL and returns what it printed
+func session(t *testing.T, script string) string {
+  t.Helper()
+  var out strings.Builder
+  if err := (&repl{out: &out}).run(strings.NewReader(script)); err != nil {
+    t.Fatalf("Got %v expected %v", err, nil)
+  }
+  return out.String()
+}
+
+func TestREPLTrees(t *testing.T) {
+  for _, kind := range []string{"avl", "btree 4", "redblack", "bst"} {
+    actualValue := session(t, `
+new `+kind+`
+put 5 five
+put 3 three
+put 8 eight
+put 9
+# a comment
+get 3
+get 4
+floor 4
+floor 1
+range 4 8
+del 5 9
+size
+`)
+    expectedValue := "three\nnot found\n3 three\nnot found\n5 five\n8 eight\n2\n"
+    if kind == "bst" {
+      expectedValue = "[three]\nnot found\n3 [three]\nnot found\n5 [five]\n8 [eight]\n2\n"
+    }
+    if actualValue != expectedValue {
+      t.Errorf("%v: got %q expected %q", kind, actualValue, expectedValue)
+    }
+  }
+}
+
+func TestREPLHeap(t *testing.T) {
+  actualValue := session(t, "new heap\nput 4\nput 1\nput 7\nheight\ndel\ndel 4\nget 1\njson\n")
+  expectedValue := "2\n1\nerror: the heap only removes its top, use del without a key\nerror: not supported by this structure\n[4,7]\n"
+  if actualValue != expectedValue {
+    t.Errorf("Got %q expected %q", actualValue, expectedValue)
+  }
+}
+
+func TestREPLHeight(t *testing.T) {
+  // every structure counts levels: none when empty, one for a single element
+  for kind, expectedValue := range map[string]string{
+    "avl":      "0\n1\n2\n",
+    "bst":      "0\n1\n3\n", // 1, 2, 3 in order make a chain
+    "btree":    "0\n1\n2\n",
+    "redblack": "0\n1\n2\n",
+    "heap":     "0\n1\n2\n",
+  } {
+    actualValue := session(t, "new "+kind+"\nheight\nput 1 one\nheight\nput 2 two\nput 3 three\nheight\n")
+    if actualValue != expectedValue {
+      t.Errorf("%v: Got %q expected %q", kind, actualValue, expectedValue)
+    }
+  }
+}
+
+func TestREPLSaveAndLoad(t *testing.T) {
+  file := filepath.Join(t.TempDir(), "tree.json")
+  actualValue := session(t, "new redblack\nput 1 a\nput 2 b\nsave "+file+"\nnew avl\nload "+file+"\njson\nrange 0 9\nquit\nput 3 c\n")
+  if expectedValue := "{\"1\":\"a\",\"2\":\"b\"}\n1 a\n2 b\n"; actualValue != expectedValue {
+    t.Errorf("Got %q expected %q", actualValue, expectedValue)
+  }
+}
+
+func TestREPLErrors(t *testing.T) {
+  actualValue := session(t, "get 1\nnew splay\nnew btree 2\nnew avl\nput x 1\nrange 1\nfrobnicate\nload /nonexistent/file.json\n")
+  for _, expectedValue := range []string{
+    "error: no structure yet",
+    `error: unknown structure "splay"`,
+    "error: btree: invalid order 2, should be at least 3",
+    `error: key "x" is not an integer`,
+    "error: usage: range LO HI",
+    `error: unknown command "frobnicate", try help`,
+    "error: open /nonexistent/file.json",
+  } {
+    if !strings.Contains(actualValue, expectedValue) {
+      t.Errorf("Got %q expected it to contain %q", actualValue, expectedValue)
+    }
+  }
+}
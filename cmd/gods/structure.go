@@ -0,0 +1,188 @@
+package main
+
+import (
+  "errors"
+  "fmt"
+  "strconv"
+
+  "github.com/fmorenovr/gods/trees"
+  "github.com/fmorenovr/gods/trees/avltree"
+  "github.com/fmorenovr/gods/trees/binaryheap"
+  "github.com/fmorenovr/gods/trees/bstree"
+  "github.com/fmorenovr/gods/trees/btree"
+  "github.com/fmorenovr/gods/trees/redblacktree"
+  "github.com/fmorenovr/gods/utils"
+)
+
+var errUnsupported = errors.New("not supported by this structure")
+
+// structure is what the REPL drives: one of the trees with int keys and string values, or the heap
+type structure interface {
+  put(key int, value string) error
+  del(keys []int) (string, error) // the heap pops its top and takes no key
+  get(key int) (string, bool, error)
+  floor(key int) (int, string, bool, error)
+  span(lo, hi int) ([]string, error) // "key value" lines
+  height() int // levels, 0 when empty
+  size() int
+  fmt.Stringer
+  utils.JSONSerializer
+  utils.JSONDeserializer
+}
+
+// newStructure builds an empty structure of kind, args are the extra words of the new command
+func newStructure(kind string, args []string) (structure, error) {
+  switch kind {
+  case "avl":
+    t := avltree.NewAVLTree[int, string]()
+    return &ordered[string]{tree: t, insert: t.Insert, levels: t.Height, edges: true}, nil
+  case "bst":
+    t := bstree.NewBSTree[int, string]()
+    return &ordered[[]string]{tree: t, insert: t.Insert, levels: t.Height, edges: true}, nil
+  case "btree":
+    order := 3
+    if len(args) > 0 {
+      var err error
+      if order, err = strconv.Atoi(args[0]); err != nil {
+        return nil, fmt.Errorf("order %q is not an integer", args[0])
+      }
+    }
+    t, err := btree.New[int, string](order, utils.OrderedComparator[int]())
+    if err != nil {
+      return nil, err
+    }
+    return &ordered[string]{tree: t, insert: t.Put, levels: t.Height}, nil
+  case "redblack":
+    t := redblacktree.New[int, string]()
+    return &ordered[string]{tree: t, insert: t.Put, levels: func() int { return redBlackHeight(t.Root) }}, nil
+  case "heap":
+    return &heap{binaryheap.New[int]()}, nil
+  }
+  return nil, fmt.Errorf("unknown structure %q, want avl, bst, btree [order], redblack or heap", kind)
+}
+
+// orderedTree is the public API the REPL uses on the four trees
+type orderedTree[V any] interface {
+  gotree.RangeMap[int, V]
+  fmt.Stringer
+  utils.JSONSerializer
+  utils.JSONDeserializer
+}
+
+// ordered adapts a tree, insert stores a value under a key (a BST adds a duplicate)
+type ordered[V any] struct {
+  tree   orderedTree[V]
+  insert func(key int, value string)
+  levels func() int
+  edges  bool // levels counts edges, as Height of AVLTree and BSTree: -1 when empty, 0 for one node
+}
+
+func (o *ordered[V]) put(key int, value string) error {
+  o.insert(key, value)
+  return nil
+}
+
+func (o *ordered[V]) del(keys []int) (string, error) {
+  if len(keys) == 0 {
+    return "", errors.New("missing key")
+  }
+  for _, key := range keys {
+    o.tree.Remove(key)
+  }
+  return "", nil
+}
+
+func (o *ordered[V]) get(key int) (string, bool, error) {
+  value, found := o.tree.Get(key)
+  return fmt.Sprint(value), found, nil
+}
+
+func (o *ordered[V]) floor(key int) (int, string, bool, error) {
+  floor, value, found := o.tree.FloorEntry(key)
+  return floor, fmt.Sprint(value), found, nil
+}
+
+func (o *ordered[V]) span(lo, hi int) ([]string, error) {
+  var lines []string
+  for it := o.tree.RangeIterator(lo, hi, gotree.IncludeBoth); it.Next(); {
+    lines = append(lines, fmt.Sprint(it.Key(), " ", it.Value()))
+  }
+  return lines, nil
+}
+
+func (o *ordered[V]) height() int {
+  if o.edges {
+    return o.levels() + 1
+  }
+  return o.levels()
+}
+
+func (o *ordered[V]) size() int {
+  return o.tree.Len()
+}
+
+func (o *ordered[V]) String() string {
+  return o.tree.String()
+}
+
+func (o *ordered[V]) ToJSON() ([]byte, error) {
+  return o.tree.ToJSON()
+}
+
+func (o *ordered[V]) FromJSON(data []byte) error {
+  return o.tree.FromJSON(data)
+}
+
+// redBlackHeight counts the levels under node, the tree has no Height of its own
+func redBlackHeight(node *redblacktree.Node[int, string]) int {
+  if node == nil {
+    return 0
+  }
+  return 1 + max(redBlackHeight(node.Left), redBlackHeight(node.Right))
+}
+
+// heap adapts a min-heap of ints, put pushes the key and ignores the value
+type heap struct {
+  *binaryheap.Heap[int]
+}
+
+func (h *heap) put(key int, value string) error {
+  h.Push(key)
+  return nil
+}
+
+func (h *heap) del(keys []int) (string, error) {
+  if len(keys) > 0 {
+    return "", errors.New("the heap only removes its top, use del without a key")
+  }
+  top, ok := h.Pop()
+  if !ok {
+    return "", errors.New("empty heap")
+  }
+  return strconv.Itoa(top), nil
+}
+
+func (h *heap) get(key int) (string, bool, error) {
+  return "", false, errUnsupported
+}
+
+func (h *heap) floor(key int) (int, string, bool, error) {
+  return 0, "", false, errUnsupported
+}
+
+func (h *heap) span(lo, hi int) ([]string, error) {
+  return nil, errUnsupported
+}
+
+// height counts the levels of the implicit tree
+func (h *heap) height() int {
+  levels := 0
+  for n := h.Size(); n > 0; n >>= 1 {
+    levels++
+  }
+  return levels
+}
+
+func (h *heap) size() int {
+  return h.Size()
+}
@@ -0,0 +1,87 @@
+package main
+
+import (
+  "errors"
+  "flag"
+  "fmt"
+  "io"
+
+  "github.com/fmorenovr/gods/kv"
+)
+
+const help = `commands:
+  get KEY                     print the value of KEY
+  put KEY VALUE               set the value of KEY
+  del KEY...                  delete the keys
+  scan [--from K] [--to K]    print the keys from K included to K excluded, with their values
+  dump FILE                   write a snapshot of the store to FILE
+  load FILE                   replace the store with the snapshot in FILE`
+
+// errNotFound is the error of get on a missing key
+var errNotFound = errors.New("not found")
+
+// run opens the store in dir, runs the command of args on it and closes it
+func run(dir string, args []string, out io.Writer) error {
+  store, err := kv.Open(dir, kv.Options{})
+  if err != nil {
+    return err
+  }
+  err = command(store, args, out)
+  if closeErr := store.Close(); err == nil {
+    err = closeErr
+  }
+  return err
+}
+
+// command runs the command of args on store
+func command(store *kv.Store, args []string, out io.Writer) error {
+  switch args[0] {
+  case "get":
+    if len(args) != 2 {
+      return errors.New("usage: get KEY")
+    }
+    value, found := store.Get(args[1])
+    if !found {
+      return fmt.Errorf("%v: %w", args[1], errNotFound)
+    }
+    fmt.Fprintf(out, "%s\n", value)
+  case "put":
+    if len(args) != 3 {
+      return errors.New("usage: put KEY VALUE")
+    }
+    return store.Put(args[1], []byte(args[2]))
+  case "del":
+    if len(args) < 2 {
+      return errors.New("usage: del KEY...")
+    }
+    var batch kv.Batch
+    for _, key := range args[1:] {
+      batch.Delete(key)
+    }
+    return store.Write(&batch)
+  case "scan":
+    flags := flag.NewFlagSet("scan", flag.ContinueOnError)
+    flags.SetOutput(io.Discard)
+    from := flags.String("from", "", "first key of the range")
+    to := flags.String("to", "", "end of the range, excluded, none when empty")
+    if err := flags.Parse(args[1:]); err != nil || flags.NArg() != 0 {
+      return errors.New("usage: scan [--from KEY] [--to KEY]")
+    }
+    for key, value := range store.Scan(*from, *to) {
+      fmt.Fprintf(out, "%v\t%s\n", key, value)
+    }
+  case "dump":
+    if len(args) != 2 {
+      return errors.New("usage: dump FILE")
+    }
+    return store.Snapshot(args[1])
+  case "load":
+    if len(args) != 2 {
+      return errors.New("usage: load FILE")
+    }
+    return store.Restore(args[1])
+  default:
+    return fmt.Errorf("unknown command %q\n%v", args[0], help)
+  }
+  return nil
+}
@@ -0,0 +1,74 @@
+package main
+
+import (
+  "errors"
+  "path/filepath"
+  "strings"
+  "testing"
+)
+
+// call runs one godskv command on the store in dir and returns what it printed
+func call(t *testing.T, dir string, args ...string) (string, error) {
+  t.Helper()
+  var out strings.Builder
+  err := run(dir, args, &out)
+  return out.String(), err
+}
+
+func TestCommands(t *testing.T) {
+  dir := t.TempDir()
+  for _, args := range [][]string{
+    {"put", "user/1", "ada"},
+    {"put", "user/2", "alan"},
+    {"put", "user/3", "grace"},
+    {"put", "zone", "eu"},
+    {"del", "user/2", "missing"},
+  } {
+    if _, err := call(t, dir, args...); err != nil {
+      t.Errorf("%v: Got %v expected %v", args, err, nil)
+    }
+  }
+  tests := []struct {
+    args     []string
+    expected string
+  }{
+    {[]string{"get", "user/1"}, "ada\n"},
+    {[]string{"scan"}, "user/1\tada\nuser/3\tgrace\nzone\teu\n"},
+    {[]string{"scan", "--from", "user/2", "--to", "zone"}, "user/3\tgrace\n"},
+    {[]string{"scan", "-to=user/3"}, "user/1\tada\n"},
+  }
+  for _, test := range tests {
+    if actualValue, err := call(t, dir, test.args...); actualValue != test.expected || err != nil {
+      t.Errorf("%v: Got %q %v expected %q", test.args, actualValue, err, test.expected)
+    }
+  }
+
+  if _, err := call(t, dir, "get", "user/2"); !errors.Is(err, errNotFound) {
+    t.Errorf("Got %v expected %v", err, errNotFound)
+  }
+  for _, args := range [][]string{{"get"}, {"put", "k"}, {"scan", "--since", "a"}, {"scan", "a"}, {"dump"}, {"drop"}} {
+    if _, err := call(t, dir, args...); err == nil {
+      t.Errorf("%v: Got %v expected an error", args, err)
+    }
+  }
+}
+
+func TestDumpLoad(t *testing.T) {
+  dir, other := t.TempDir(), t.TempDir()
+  path := filepath.Join(t.TempDir(), "backup.snapshot")
+  call(t, dir, "put", "a", "1")
+  call(t, dir, "put", "b", "2")
+  if _, err := call(t, dir, "dump", path); err != nil {
+    t.Errorf("Got %v expected %v", err, nil)
+  }
+  call(t, other, "put", "c", "3")
+  if _, err := call(t, other, "load", path); err != nil {
+    t.Errorf("Got %v expected %v", err, nil)
+  }
+  if actualValue, _ := call(t, other, "scan"); actualValue != "a\t1\nb\t2\n" {
+    t.Errorf("Got %q expected %q", actualValue, "a\t1\nb\t2\n")
+  }
+  if _, err := call(t, other, "load", filepath.Join(dir, "missing")); err == nil {
+    t.Errorf("Got %v expected an error", err)
+  }
+}
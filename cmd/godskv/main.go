@@ -0,0 +1,35 @@
+// Command godskv reads and writes a kv store from the shell, one command per call:
+//
+//   $ godskv -dir data put user/1 ada
+//   $ godskv -dir data get user/1
+//   ada
+//   $ godskv -dir data scan --from user/ --to user0
+//   user/1	ada
+//   $ godskv -dir data dump backup.snapshot
+//
+// The store lives in the directory of -dir, ./godskv by default. Run godskv without arguments
+// for the list of commands.
+package main
+
+import (
+  "flag"
+  "fmt"
+  "os"
+)
+
+func main() {
+  dir := flag.String("dir", "godskv", "directory of the store")
+  flag.Usage = func() {
+    fmt.Fprintf(flag.CommandLine.Output(), "usage: godskv [-dir DIR] COMMAND [ARGS]\n\n%v\n", help)
+    flag.PrintDefaults()
+  }
+  flag.Parse()
+  if flag.NArg() == 0 {
+    flag.Usage()
+    os.Exit(2)
+  }
+  if err := run(*dir, flag.Args(), os.Stdout); err != nil {
+    fmt.Fprintln(os.Stderr, "godskv:", err)
+    os.Exit(1)
+  }
+}
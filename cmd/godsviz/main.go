@@ -0,0 +1,44 @@
+// Command godsviz serves a tree of the chosen kind on a local web page, to watch it
+// balance while keys are inserted and removed.
+//
+//   godsviz -kind btree -order 4 -keys 1,2,3,4,5 -addr localhost:8080
+package main
+
+import (
+  "flag"
+  "fmt"
+  "log"
+  "net/http"
+  "os"
+  "strconv"
+  "strings"
+
+  "github.com/fmorenovr/gods/trees/treeviz"
+)
+
+func main() {
+  kind := flag.String("kind", "avl", "structure to host: "+strings.Join(treeviz.Kinds, ", "))
+  order := flag.Int("order", 3, "order of the btree")
+  keys := flag.String("keys", "", "comma separated keys inserted at start")
+  addr := flag.String("addr", "localhost:8080", "address to listen on")
+  flag.Parse()
+
+  handler, err := treeviz.NewHandler(*kind, *order)
+  if err != nil {
+    fmt.Fprintln(os.Stderr, err)
+    os.Exit(2)
+  }
+  for _, field := range strings.Split(*keys, ",") {
+    if field = strings.TrimSpace(field); field == "" {
+      continue
+    }
+    key, err := strconv.Atoi(field)
+    if err != nil {
+      fmt.Fprintf(os.Stderr, "godsviz: key %q is not an integer\n", field)
+      os.Exit(2)
+    }
+    handler.Insert(key)
+  }
+  log.Printf("godsviz: serving %v on http://%v/", *kind, *addr)
+  log.Fatal(http.ListenAndServe(*addr, handler))
+}
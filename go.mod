@@ -0,0 +1,3 @@
+module github.com/fmorenovr/gods
+
+go 1.23
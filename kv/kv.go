@@ -0,0 +1,208 @@
+package kv
+
+import (
+  "errors"
+  "fmt"
+  "iter"
+  "os"
+  "slices"
+  "strings"
+  "time"
+
+  "github.com/fmorenovr/gods/trees/btree"
+  "github.com/fmorenovr/gods/trees/wal"
+  "github.com/fmorenovr/gods/utils"
+)
+
+// Defaults of the zero fields of Options
+const (
+  DefaultOrder        = 32
+  DefaultCompactAfter = 10000
+)
+
+// ErrClosed is returned by every write on a closed store
+var ErrClosed = errors.New("kv: store is closed")
+
+// Options of Open, zero fields take the defaults
+type Options struct {
+  Order        int            // Order of the btree, DefaultOrder when zero
+  Sync         wal.SyncPolicy // When writes are flushed, wal.SyncAlways by default
+  Interval     time.Duration  // Flush interval of wal.SyncInterval, wal.DefaultInterval when zero
+  CompactAfter int            // Log records that trigger a compaction, DefaultCompactAfter when zero, never when negative
+}
+
+// Store is a sorted key-value store backed by a directory
+type Store struct {
+  tree    *btree.BTree[string, []byte]
+  log     *wal.Log[string, []byte]
+  options Options
+  err     error // set when memory and disk may disagree, every later write returns it
+}
+
+// Open opens the store in the directory dir, creating it if it does not exist, and loads its
+// entries in memory.
+func Open(dir string, options Options) (*Store, error) {
+  if options.Order == 0 {
+    options.Order = DefaultOrder
+  }
+  if options.CompactAfter == 0 {
+    options.CompactAfter = DefaultCompactAfter
+  }
+  tree, err := btree.New[string, []byte](options.Order, utils.OrderedComparator[string]())
+  if err != nil {
+    return nil, fmt.Errorf("kv: %w", err)
+  }
+  log, err := wal.Open(dir, wal.Tree[string, []byte](tree), wal.Options[string, []byte]{Sync: options.Sync, Interval: options.Interval})
+  if err != nil {
+    return nil, fmt.Errorf("kv: %w", err)
+  }
+  return &Store{tree: tree, log: log, options: options}, nil
+}
+
+// Len returns the number of entries in the store
+func (s *Store) Len() int {
+  return s.tree.Len()
+}
+
+// Get returns the value of key, found is false if the store does not hold it. The value is
+// shared with the store and must not be modified.
+func (s *Store) Get(key string) (value []byte, found bool) {
+  return s.tree.Get(key)
+}
+
+// Put sets the value of key, the store keeps a copy of value
+func (s *Store) Put(key string, value []byte) error {
+  if s.err != nil {
+    return s.err
+  }
+  if err := s.log.Put(key, slices.Clone(value)); err != nil {
+    return s.fail(err)
+  }
+  return s.compact()
+}
+
+// Delete removes key from the store, deleting a missing key is not an error
+func (s *Store) Delete(key string) error {
+  if s.err != nil {
+    return s.err
+  }
+  if err := s.log.Remove(key); err != nil {
+    return s.fail(err)
+  }
+  return s.compact()
+}
+
+// Write applies the operations of batch in order, atomically: after a crash either all of them
+// are in the store or none. The batch can be reused once written.
+func (s *Store) Write(batch *Batch) error {
+  if s.err != nil {
+    return s.err
+  }
+  if err := s.log.Apply(batch.ops...); err != nil {
+    return s.fail(err)
+  }
+  return s.compact()
+}
+
+// Scan returns the entries whose keys are between from, included, and to, excluded, in order of
+// keys. An empty to leaves the range open at the end. The values are shared with the store and
+// must not be modified; the store must not be written during the walk.
+func (s *Store) Scan(from, to string) iter.Seq2[string, []byte] {
+  return func(yield func(string, []byte) bool) {
+    iterator := s.tree.IteratorAt(from)
+    for iterator.Next() {
+      if to != "" && iterator.Key() >= to {
+        return
+      }
+      if !yield(iterator.Key(), iterator.Value()) {
+        return
+      }
+    }
+  }
+}
+
+// Prefix returns the entries whose keys start with prefix, in order of keys, as Scan does
+func (s *Store) Prefix(prefix string) iter.Seq2[string, []byte] {
+  return func(yield func(string, []byte) bool) {
+    for key, value := range s.Scan(prefix, "") {
+      if !strings.HasPrefix(key, prefix) || !yield(key, value) {
+        return
+      }
+    }
+  }
+}
+
+// Snapshot writes every entry of the store to the file at path, replacing it atomically
+func (s *Store) Snapshot(path string) error {
+  data, err := s.tree.MarshalBinary()
+  if err != nil {
+    return err
+  }
+  return wal.WriteFile(path, data)
+}
+
+// Restore replaces the contents of the store with the snapshot in the file at path. A corrupt
+// snapshot is an error wrapping gotree.ErrCorruptData and leaves the store untouched.
+func (s *Store) Restore(path string) error {
+  if s.err != nil {
+    return s.err
+  }
+  data, err := os.ReadFile(path)
+  if err != nil {
+    return err
+  }
+  if err := s.tree.UnmarshalBinary(data); err != nil {
+    return fmt.Errorf("kv: %v: %w", path, err)
+  }
+  // the restored entries are only durable once they are the snapshot of the log
+  if err := s.log.Compact(); err != nil {
+    return s.fail(err)
+  }
+  return nil
+}
+
+// Compact writes the entries as the snapshot of the directory and empties its log
+func (s *Store) Compact() error {
+  if s.err != nil {
+    return s.err
+  }
+  if err := s.log.Compact(); err != nil {
+    return s.fail(err)
+  }
+  return nil
+}
+
+// Sync flushes the writes to stable storage
+func (s *Store) Sync() error {
+  if s.err != nil {
+    return s.err
+  }
+  return s.log.Sync()
+}
+
+// Close flushes and closes the store, it can still be read
+func (s *Store) Close() error {
+  if s.err == ErrClosed {
+    return ErrClosed
+  }
+  err := s.log.Close()
+  s.err = ErrClosed
+  return err
+}
+
+// compact compacts the log once it holds options.CompactAfter records
+func (s *Store) compact() error {
+  if s.options.CompactAfter < 0 || s.log.Records() < s.options.CompactAfter {
+    return nil
+  }
+  return s.Compact()
+}
+
+// fail makes err the sticky error of the store: after a failed write the entries in memory may
+// not be those on disk, the store must be opened again
+func (s *Store) fail(err error) error {
+  if s.err == nil {
+    s.err = err
+  }
+  return err
+}
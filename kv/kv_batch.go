@@ -0,0 +1,32 @@
+package kv
+
+import (
+  "slices"
+
+  "github.com/fmorenovr/gods/trees/wal"
+)
+
+// Batch collects writes that Store.Write applies atomically, the zero value is an empty batch
+type Batch struct {
+  ops []wal.Op[string, []byte]
+}
+
+// Put adds the write of key and value to the batch, the batch keeps a copy of value
+func (b *Batch) Put(key string, value []byte) {
+  b.ops = append(b.ops, wal.Op[string, []byte]{Key: key, Value: slices.Clone(value)})
+}
+
+// Delete adds the removal of key to the batch
+func (b *Batch) Delete(key string) {
+  b.ops = append(b.ops, wal.Op[string, []byte]{Key: key, Remove: true})
+}
+
+// Len returns the number of writes in the batch
+func (b *Batch) Len() int {
+  return len(b.ops)
+}
+
+// Reset empties the batch
+func (b *Batch) Reset() {
+  b.ops = b.ops[:0]
+}
@@ -0,0 +1,22 @@
+// Package kv is an embedded, sorted key-value store of string keys and byte values.
+//
+// A Store keeps its entries in a btree.BTree in memory and makes every write durable with the
+// write-ahead log of package wal, in the directory given to Open:
+//
+//   store, err := kv.Open("data", kv.Options{})
+//   if err != nil {
+//     return err
+//   }
+//   defer store.Close()
+//   store.Put("user/1", []byte("ada"))
+//   for key, value := range store.Prefix("user/") {
+//     fmt.Println(key, string(value))
+//   }
+//
+// Keys are ordered byte by byte. Scan and Prefix walk a range of them in order; a Batch groups
+// writes that reach the log as a single record, all applied after a crash or none. Snapshot
+// writes the whole store to a single file that Restore loads back, in the MarshalBinary form of
+// the BTree.
+//
+// Structure is not thread safe.
+package kv
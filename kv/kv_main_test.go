@@ -0,0 +1,159 @@
+package kv
+
+import (
+  "errors"
+  "os"
+  "path/filepath"
+  "slices"
+  "strconv"
+  "testing"
+
+  "github.com/fmorenovr/gods/trees"
+  "github.com/fmorenovr/gods/trees/wal"
+)
+
+func openStore(t *testing.T, dir string, options Options) *Store {
+  t.Helper()
+  s, err := Open(dir, options)
+  if err != nil {
+    t.Fatalf("Got %v expected %v", err, nil)
+  }
+  t.Cleanup(func() { s.Close() })
+  return s
+}
+
+func keys(entries func(func(string, []byte) bool)) (keys []string) {
+  for key := range entries {
+    keys = append(keys, key)
+  }
+  return keys
+}
+
+func TestStorePutGetDelete(t *testing.T) {
+  dir := t.TempDir()
+  s := openStore(t, dir, Options{Order: 3})
+  value := []byte("one")
+  s.Put("1", value)
+  value[0] = 'x'
+  s.Put("2", []byte("two"))
+  s.Put("3", []byte("three"))
+  if err := s.Delete("2"); err != nil {
+    t.Errorf("Got %v expected %v", err, nil)
+  }
+  if err := s.Delete("missing"); err != nil {
+    t.Errorf("Got %v expected %v", err, nil)
+  }
+  if actualValue, found := s.Get("1"); string(actualValue) != "one" || !found {
+    t.Errorf("Got %s %v expected %v %v", actualValue, found, "one", true)
+  }
+  if _, found := s.Get("2"); found {
+    t.Errorf("Got %v expected %v", found, false)
+  }
+  s.Close()
+  if err := s.Put("4", nil); !errors.Is(err, ErrClosed) {
+    t.Errorf("Got %v expected %v", err, ErrClosed)
+  }
+
+  s = openStore(t, dir, Options{Order: 3})
+  if actualValue, expectedValue := keys(s.Scan("", "")), []string{"1", "3"}; !slices.Equal(actualValue, expectedValue) {
+    t.Errorf("Got %v expected %v", actualValue, expectedValue)
+  }
+  if _, err := Open(t.TempDir(), Options{Order: 2}); err == nil || err.Error() != "kv: btree: invalid order 2, should be at least 3" {
+    t.Errorf("Got %v expected an invalid order", err)
+  }
+}
+
+func TestStoreScan(t *testing.T) {
+  s := openStore(t, t.TempDir(), Options{Order: 3, Sync: wal.SyncNever})
+  for _, key := range []string{"a", "ab", "abc", "abd", "b", "ba", "c"} {
+    s.Put(key, []byte(key))
+  }
+  tests := []struct {
+    name     string
+    entries  func(func(string, []byte) bool)
+    expected []string
+  }{
+    {"all", s.Scan("", ""), []string{"a", "ab", "abc", "abd", "b", "ba", "c"}},
+    {"range", s.Scan("ab", "b"), []string{"ab", "abc", "abd"}},
+    {"from", s.Scan("abca", ""), []string{"abd", "b", "ba", "c"}},
+    {"to", s.Scan("", "ab"), []string{"a"}},
+    {"empty", s.Scan("c", "c"), nil},
+    {"prefix", s.Prefix("ab"), []string{"ab", "abc", "abd"}},
+    {"prefix all", s.Prefix(""), []string{"a", "ab", "abc", "abd", "b", "ba", "c"}},
+    {"prefix none", s.Prefix("bb"), nil},
+  }
+  for _, test := range tests {
+    if actualValue := keys(test.entries); !slices.Equal(actualValue, test.expected) {
+      t.Errorf("%v: Got %v expected %v", test.name, actualValue, test.expected)
+    }
+  }
+  for key, value := range s.Prefix("a") {
+    if key != string(value) {
+      t.Errorf("Got %s expected %v", value, key)
+    }
+    if key == "ab" {
+      break
+    }
+  }
+}
+
+func TestStoreBatch(t *testing.T) {
+  dir := t.TempDir()
+  s := openStore(t, dir, Options{})
+  s.Put("a", []byte("1"))
+  var batch Batch
+  batch.Put("b", []byte("2"))
+  batch.Delete("a")
+  batch.Put("c", []byte("3"))
+  if actualValue, expectedValue := batch.Len(), 3; actualValue != expectedValue {
+    t.Errorf("Got %v expected %v", actualValue, expectedValue)
+  }
+  if err := s.Write(&batch); err != nil {
+    t.Errorf("Got %v expected %v", err, nil)
+  }
+  batch.Reset()
+  if err := s.Write(&batch); err != nil {
+    t.Errorf("Got %v expected %v", err, nil)
+  }
+  s.Close()
+
+  s = openStore(t, dir, Options{})
+  if actualValue, expectedValue := keys(s.Scan("", "")), []string{"b", "c"}; !slices.Equal(actualValue, expectedValue) {
+    t.Errorf("Got %v expected %v", actualValue, expectedValue)
+  }
+}
+
+func TestStoreSnapshotRestore(t *testing.T) {
+  dir := t.TempDir()
+  s := openStore(t, dir, Options{Order: 4, CompactAfter: 50})
+  for i := 0; i < 120; i++ {
+    s.Put(strconv.Itoa(i), []byte(strconv.Itoa(i*i)))
+  }
+  if info, _ := os.Stat(filepath.Join(dir, wal.SnapshotFile)); info == nil {
+    t.Errorf("Got no snapshot expected the log compacted")
+  }
+  path := filepath.Join(t.TempDir(), "store.snapshot")
+  if err := s.Snapshot(path); err != nil {
+    t.Errorf("Got %v expected %v", err, nil)
+  }
+
+  other := openStore(t, t.TempDir(), Options{})
+  other.Put("gone", nil)
+  if err := other.Restore(path); err != nil {
+    t.Errorf("Got %v expected %v", err, nil)
+  }
+  if actualValue, expectedValue := keys(other.Scan("", "")), keys(s.Scan("", "")); !slices.Equal(actualValue, expectedValue) {
+    t.Errorf("Got %v expected %v", actualValue, expectedValue)
+  }
+  if actualValue, _ := other.Get("11"); string(actualValue) != "121" {
+    t.Errorf("Got %s expected %v", actualValue, "121")
+  }
+
+  os.WriteFile(path, []byte("not a snapshot"), 0o644)
+  if err := other.Restore(path); !errors.Is(err, gotree.ErrCorruptData) {
+    t.Errorf("Got %v expected %v", err, gotree.ErrCorruptData)
+  }
+  if actualValue, expectedValue := other.Len(), 120; actualValue != expectedValue {
+    t.Errorf("Got %v expected %v", actualValue, expectedValue)
+  }
+}
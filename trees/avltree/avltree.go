@@ -1,162 +1,261 @@
 package avltree
 
 import (
+  "cmp";
   "fmt";
-  "github.com/jenazads/goutils";
+  "io";
+  "iter";
+  "github.com/fmorenovr/gods/trees";
+  "github.com/fmorenovr/gods/utils";
 )
 
 // AVLTree object
-type AVLTree struct {
-  Root       *AVLNode                // Root node
-  comparator goutils.TypeComparator  // Key comparator
-  operator   goutils.TypeOperator    // Type Operator
+type AVLTree[K comparable, V any] struct {
+  Root       *AVLNode[K, V]     // Root node
+  comparator utils.Comparator[K]  // Key comparator
+  operator   utils.Operator[K]    // Key operator, used by SumNodes
+  size       int                  // Number of nodes
+  tracer     gotree.Tracer        // Receives operations and rotations, nil when not tracing
+  keyCodec   utils.Codec[K]       // Encodes keys in the JSON and binary forms, nil for the default
+  valueCodec utils.Codec[V]       // Encodes values in the JSON and binary forms, nil for the default
 }
 
 // Node 
-type AVLNode struct {
-  Key      interface{}
-  Value    interface{}
-  Parent   *AVLNode    // Parent node
-  Children [2]*AVLNode // Children nodes, 0-> left, 1-> right
-  bf       int         // balance factor
+type AVLNode[K comparable, V any] struct {
+  Key      K
+  Value    V
+  Parent   *AVLNode[K, V]    // Parent node
+  Children [2]*AVLNode[K, V] // Children nodes, 0-> left, 1-> right
+  bf       int               // balance factor
+  height   int               // height of the subtree rooted here, cached
+  size     int               // number of nodes in the subtree rooted here, cached
 }
 
-// New AVL Tree
-func NewAVLTree(comp goutils.TypeComparator, op goutils.TypeOperator) (*AVLTree) {
-  return &AVLTree{comparator: comp, operator: op}
+// New AVL Tree with the natural order of K, SumNodes adds keys with +
+func NewAVLTree[K cmp.Ordered, V any]() (*AVLTree[K, V]) {
+  return NewAVLTreeWith[K, V](utils.OrderedComparator[K](), utils.AddOperator[K]())
+}
+
+// New AVL Tree with a custom comparator and operator
+func NewAVLTreeWith[K comparable, V any](comp utils.Comparator[K], op utils.Operator[K]) (*AVLTree[K, V]) {
+  return &AVLTree[K, V]{comparator: comp, operator: op}
 }
 
 // New AVL Node
-func NewAVLNode(key interface{}, value interface{}, p *AVLNode) (*AVLNode) {
-  return &AVLNode{Key: key, Value: value, Parent: p, bf: 0}
+func NewAVLNode[K comparable, V any](key K, value V, p *AVLNode[K, V]) (*AVLNode[K, V]) {
+  return &AVLNode[K, V]{Key: key, Value: value, Parent: p, bf: 0, size: 1}
 }
 
 // IsEmpty, true if tree doesnt have nodes
-func (t *AVLTree) IsEmpty() (bool) {
+func (t *AVLTree[K, V]) IsEmpty() (bool) {
   return (t.Root == nil)
 }
 
 // Return true if the node is leaf
-func IsLeaf(node *AVLNode) (bool) {
+func IsLeaf[K comparable, V any](node *AVLNode[K, V]) (bool) {
   return (node.Children[0]==nil && node.Children[1]==nil);
 }
 
 // Removes all nodes
-func (t *AVLTree) Clear() {
+func (t *AVLTree[K, V]) Clear() {
   t.Root = nil
+  t.size = 0
 }
 
 // Insert New Node by Key
-func (t *AVLTree) Insert(key interface{}, value interface{}) {
-  t.Root = avlInsert(t.Root, key, value, nil, t.comparator)
+func (t *AVLTree[K, V]) Insert(key K, value V) {
+  if utils.Debug {
+    defer t.debugCheck("Insert", key)
+  }
+  if t.tracer != nil {
+    t.tracer.Trace(gotree.Operation("avltree", "Insert", key).WithValue(value))
+  }
+  if avlSearch(t.Root, key, t.comparator) == nil {
+    t.size++
+  }
+  t.Root = avlInsert(t.Root, key, value, nil, t.comparator, t.tracer)
+}
+
+// Put inserts key-value pair node into the tree, same as Insert
+func (t *AVLTree[K, V]) Put(key K, value V) {
+  t.Insert(key, value)
 }
 
 // Remove Node by key
-func (t *AVLTree) Remove(key interface{}) {
-  t.Root = avlRemove(t.Root, key, t.comparator)
+func (t *AVLTree[K, V]) Remove(key K) {
+  if utils.Debug {
+    defer t.debugCheck("Remove", key)
+  }
+  if t.tracer != nil {
+    t.tracer.Trace(gotree.Operation("avltree", "Remove", key))
+  }
+  if avlSearch(t.Root, key, t.comparator) == nil {
+    return
+  }
+  t.size--
+  t.Root = avlRemove(t.Root, key, t.comparator, t.tracer)
+}
+
+// SetTracer sends every Insert and Remove, and the rotations they make, to tracer; nil stops tracing
+func (t *AVLTree[K, V]) SetTracer(tracer gotree.Tracer) {
+  t.tracer = tracer
 }
 
 // Search Value, return the node
-func (t *AVLTree) Search(key interface{}) (*AVLNode) {
+func (t *AVLTree[K, V]) Search(key K) (*AVLNode[K, V]) {
   return avlSearch(t.Root, key, t.comparator)
 }
 
-// Get Value
-func (t *AVLTree) Get(key interface{}) (interface{}) {
+// Get Value, found is false if the key is not in the tree
+func (t *AVLTree[K, V]) Get(key K) (value V, found bool) {
   node := avlSearch(t.Root, key, t.comparator)
-  if node.Value == nil {
-    return nil
+  if node == nil {
+    return value, false
   }
-  return node.Value
+  return node.Value, true
+}
+
+// Contains returns true if the key is in the tree
+func (t *AVLTree[K, V]) Contains(key K) (bool) {
+  return avlSearch(t.Root, key, t.comparator) != nil
 }
 
 // return AVL Tree Height
-func (t *AVLTree) Height() (int) {
+func (t *AVLTree[K, V]) Height() (int) {
   return avlHeight(t.Root)
 }
 
 // Return Size of tree
-func (t *AVLTree) Size() (int) {
-  return avlSize(t.Root)
+func (t *AVLTree[K, V]) Size() (int) {
+  return t.size
+}
+
+// Len returns the number of keys in the tree, same as Size
+func (t *AVLTree[K, V]) Len() (int) {
+  return t.Size()
+}
+
+// Select returns the node with the k-th smallest key, counting from 0
+func (t *AVLTree[K, V]) Select(k int) (node *AVLNode[K, V], found bool) {
+  node = avlSelect(t.Root, k)
+  return node, node != nil
+}
+
+// Rank returns the number of keys smaller than key
+func (t *AVLTree[K, V]) Rank(key K) (int) {
+  return avlRank(t.Root, key, false, t.comparator)
+}
+
+// CountRange returns the number of keys between lo and hi, both included
+func (t *AVLTree[K, V]) CountRange(lo, hi K) (int) {
+  if t.comparator(lo, hi) > 0 {
+    return 0
+  }
+  return avlRank(t.Root, hi, true, t.comparator) - avlRank(t.Root, lo, false, t.comparator)
 }
 
 // Return number of Leaf
-func (t *AVLTree) LeafCount() (int) {
+func (t *AVLTree[K, V]) LeafCount() (int) {
   return avlLeafCount(t.Root)
 }
 
 // Return minimum element
-func (t *AVLTree) Left() (*AVLNode) {
+func (t *AVLTree[K, V]) Left() (*AVLNode[K, V]) {
   return avlFindNode(t.Root, 0)
 }
 
 // Return maximum element
-func (t *AVLTree) Right() (*AVLNode) {
+func (t *AVLTree[K, V]) Right() (*AVLNode[K, V]) {
   return avlFindNode(t.Root, 1)
 }
 
+// Min returns the minimum key and its value
+func (t *AVLTree[K, V]) Min() (key K, value V, found bool) {
+  return avlEntry(t.Left())
+}
+
+// Max returns the maximum key and its value
+func (t *AVLTree[K, V]) Max() (key K, value V, found bool) {
+  return avlEntry(t.Right())
+}
+
 // Return sum of all nodes
-func (t *AVLTree) SumNodes() (interface{}) {
+func (t *AVLTree[K, V]) SumNodes() (K) {
   return avlSumNodes(t.Root, t.operator)
 }
 
-// Return the height of a specific node
-func (t *AVLTree) HeightOfNode(key interface{}) (int) {
+// HeightOfNode returns the depth of the node of key, 0 for the root; found is false if the key
+// is not in the tree
+func (t *AVLTree[K, V]) HeightOfNode(key K) (height int, found bool) {
   return avlHeightOfNode(t.Root, key, t.comparator)
 }
 
-// print preorder
-func (t *AVLTree) PrintPreOrder() {
-  avlPrintPreorder(t.Root)
-  fmt.Println()
+// Walk visits every node in the given order until visitor returns false
+func (t *AVLTree[K, V]) Walk(order gotree.TraversalOrder, visitor func(key K, value V) bool) {
+  if order == gotree.LevelOrder {
+    avlWalkLevels(t.Root, visitor)
+  } else {
+    avlWalk(t.Root, order, visitor)
+  }
+}
+
+// Traverse returns the key/value pairs in the given order as a range-over-func sequence
+func (t *AVLTree[K, V]) Traverse(order gotree.TraversalOrder) iter.Seq2[K, V] {
+  return func(yield func(K, V) bool) {
+    t.Walk(order, yield)
+  }
+}
+
+// print preorder into w
+func (t *AVLTree[K, V]) PrintPreOrder(w io.Writer) {
+  avlPrintOrder(w, t, gotree.PreOrder)
 }
 
-// print inorder
-func (t *AVLTree) PrintInOrder() {
-  avlPrintInorder(t.Root)
-  fmt.Println()
+// print inorder into w
+func (t *AVLTree[K, V]) PrintInOrder(w io.Writer) {
+  avlPrintOrder(w, t, gotree.InOrder)
 }
 
-// print postorder
-func (t *AVLTree) PrintPostOrder() {
-  avlPrintPostorder(t.Root)
-  fmt.Println()
+// print postorder into w
+func (t *AVLTree[K, V]) PrintPostOrder(w io.Writer) {
+  avlPrintOrder(w, t, gotree.PostOrder)
 }
 
-// Return the parent node of a specific value
-func (t *AVLTree) Parent(key interface{}) (*AVLNode) {
+// Parent returns the parent node of key, nil for the root; found is false if the key is not in the tree
+func (t *AVLTree[K, V]) Parent(key K) (parent *AVLNode[K, V], found bool) {
   return getParentNode(t.Root, key, t.comparator)
 }
 
-// Return the brother of a specific value
-func (t *AVLTree) Brother(key interface{}) (*AVLNode) {
+// Brother returns the other child of the parent of key, nil if it has none or key is the root;
+// found is false if the key is not in the tree
+func (t *AVLTree[K, V]) Brother(key K) (brother *AVLNode[K, V], found bool) {
   return getBrotherNode(t.Root, key, t.comparator)
 }
 
 // Return previous or predecessor node
-func (node *AVLNode) Prev() (*AVLNode) {
+func (node *AVLNode[K, V]) Prev() (*AVLNode[K, V]) {
   return avlFindNeighbourNode(node, 0)
 }
 
 // Return next or sucessor node
-func (node *AVLNode) Next() (*AVLNode) {
+func (node *AVLNode[K, V]) Next() (*AVLNode[K, V]) {
   return avlFindNeighbourNode(node, 1)
 }
 
 // Return avl mirror
-func (t *AVLTree) Mirror() {
+func (t *AVLTree[K, V]) Mirror() {
   avlMirror(t.Root)
 }
 
 // Compare 2 AVLTree
-func (t *AVLTree) IsSameAs(otherTree *AVLTree) (bool) {
+func (t *AVLTree[K, V]) IsSameAs(otherTree *AVLTree[K, V]) (bool) {
   return avlIsSameAs(t.Root, otherTree.Root)
 }
 
 // Keys returns all keys in-order
-func (t *AVLTree) Keys() ([]interface{}) {
+func (t *AVLTree[K, V]) Keys() ([]K) {
   size:=t.Size()
-  keys := make([]interface{}, size)
+  keys := make([]K, size)
   it := t.Iterator()
   for i := 0; it.Next(); i++ {
     keys[i] = it.Key()
@@ -165,9 +264,9 @@ func (t *AVLTree) Keys() ([]interface{}) {
 }
 
 // Values returns all values in-order based on the key.
-func (t *AVLTree) Values() ([]interface{}) {
+func (t *AVLTree[K, V]) Values() ([]V) {
   size:=t.Size()
-  values := make([]interface{}, size)
+  values := make([]V, size)
   it := t.Iterator()
   for i := 0; it.Next(); i++ {
     values[i] = it.Value()
@@ -175,13 +274,13 @@ func (t *AVLTree) Values() ([]interface{}) {
   return values
 }
 
-// Print the AVLTree
-func (t *AVLTree) Print() {
-  avlTreePrintLevels(t.Root)
+// Print the AVLTree level by level into w
+func (t *AVLTree[K, V]) Print(w io.Writer) {
+  avlTreePrintLevels(w, t.Root)
 }
 
 // Print Tree with fmt.Print*
-func (t *AVLTree) String() (string) {
+func (t *AVLTree[K, V]) String() (string) {
   str := ""
 	if !t.IsEmpty() {
 		avlTreePrint(t.Root, "", false, &str)
@@ -190,18 +289,42 @@ func (t *AVLTree) String() (string) {
 }
 
 // Print Node with fmt.Print*
-func (node *AVLNode) String() (string) {
+func (node *AVLNode[K, V]) String() (string) {
   return fmt.Sprintf("%v(%v)", node.Key, node.bf)
 }
 
-// Return the largest node that is smaller than or equal to the given node.
-func (t *AVLTree) Floor(key interface{}) (*AVLNode) {
-  node := t.Search(key)
-  return node.Next()
+// Return the largest node that is smaller than or equal to the given key, found is false if there is none
+func (t *AVLTree[K, V]) Floor(key K) (floor *AVLNode[K, V], found bool) {
+  floor = avlFloor(t.Root, key, true, t.comparator)
+  return floor, floor != nil
+}
+
+// Return the smallest node that is larger than or equal to the given key, found is false if there is none
+func (t *AVLTree[K, V]) Ceiling(key K) (ceiling *AVLNode[K, V], found bool) {
+  ceiling = avlCeiling(t.Root, key, true, t.comparator)
+  return ceiling, ceiling != nil
+}
+
+// Return the largest node that is strictly smaller than the given key, found is false if there is none
+func (t *AVLTree[K, V]) Lower(key K) (lower *AVLNode[K, V], found bool) {
+  lower = avlFloor(t.Root, key, false, t.comparator)
+  return lower, lower != nil
+}
+
+// Return the smallest node that is strictly larger than the given key, found is false if there is none
+func (t *AVLTree[K, V]) Higher(key K) (higher *AVLNode[K, V], found bool) {
+  higher = avlCeiling(t.Root, key, false, t.comparator)
+  return higher, higher != nil
+}
+
+// FloorEntry returns the largest key smaller than or equal to the given key and its value
+func (t *AVLTree[K, V]) FloorEntry(key K) (floor K, value V, found bool) {
+  node, _ := t.Floor(key)
+  return avlEntry(node)
 }
 
-// Return the smallest node that is larger than or equal to the given node.
-func (t *AVLTree) Ceiling(key interface{}) (*AVLNode) {
-  node := t.Search(key)
-  return node.Prev()
+// CeilingEntry returns the smallest key larger than or equal to the given key and its value
+func (t *AVLTree[K, V]) CeilingEntry(key K) (ceiling K, value V, found bool) {
+  node, _ := t.Ceiling(key)
+  return avlEntry(node)
 }
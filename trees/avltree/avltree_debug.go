@@ -0,0 +1,29 @@
+package avltree
+
+import (
+  "fmt";
+  "github.com/fmorenovr/gods/utils";
+)
+
+// debugCheck runs after every mutation in godsdebug builds (see utils.Debug): it
+// spot-checks the comparator on key and the keys along its search path down to a
+// leaf, then re-validates the tree
+func (t *AVLTree[K, V]) debugCheck(operation string, key K) {
+  keys := []K{key}
+  for node := t.Root; node != nil; {
+    keys = append(keys, node.Key)
+    // keep going past an equal key, its subtree is where a bad comparator hurts
+    if t.comparator(key, node.Key) < 0 {
+      node = node.Children[0]
+    } else {
+      node = node.Children[1]
+    }
+  }
+  err := utils.CheckComparator(t.comparator, keys...)
+  if err == nil {
+    err = t.Validate()
+  }
+  if err != nil {
+    utils.DebugFailure("AVLTree", fmt.Sprintf("%v(%v)", operation, key), err, t)
+  }
+}
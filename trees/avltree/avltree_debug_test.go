@@ -0,0 +1,45 @@
+//go:build godsdebug
+
+package avltree_test
+
+import (
+  "strings"
+  "testing"
+  "github.com/fmorenovr/gods/trees/avltree"
+  "github.com/fmorenovr/gods/utils"
+)
+
+func TestAVLTreeDebugBadComparator(t *testing.T) {
+  // keys closer than 2 compare equal to each other but not to their other neighbours
+  tolerant := func(a, b int) int {
+    if a-b <= 1 && b-a <= 1 {
+      return 0
+    }
+    return a - b
+  }
+  tree := avltree.NewAVLTreeWith[int, string](tolerant, utils.AddOperator[int]())
+  defer func() {
+    err, _ := recover().(error)
+    if err == nil || !strings.Contains(err.Error(), "AVLTree.Insert(") || !strings.Contains(err.Error(), "comparator") {
+      t.Errorf("Got %v expected a comparator report from Insert", err)
+    }
+  }()
+  for _, key := range []int{10, 12, 11} {
+    tree.Insert(key, "")
+  }
+}
+
+func TestAVLTreeDebugCorruption(t *testing.T) {
+  tree := avltree.NewAVLTree[int, string]()
+  for key := 1; key <= 7; key++ {
+    tree.Insert(key, "")
+  }
+  tree.Root.Children[0].Key = 100
+  defer func() {
+    err, _ := recover().(error)
+    if err == nil || !strings.Contains(err.Error(), "AVLTree.Remove(7)") || !strings.Contains(err.Error(), "100") {
+      t.Errorf("Got %v expected a report naming node 100", err)
+    }
+  }()
+  tree.Remove(7)
+}
@@ -0,0 +1,47 @@
+package avltree
+
+import (
+  "fmt";
+  "io";
+  "github.com/fmorenovr/gods/trees";
+  "github.com/fmorenovr/gods/utils";
+)
+
+// WriteDOT writes the tree in Graphviz DOT, every node labeled with its key and balance factor
+func (t *AVLTree[K, V]) WriteDOT(w io.Writer, opts gotree.ExportOptions[K]) error {
+  return t.Graph(opts).WriteDOT(w)
+}
+
+// WriteMermaid writes the tree as a Mermaid flowchart, every node labeled with its key and balance factor
+func (t *AVLTree[K, V]) WriteMermaid(w io.Writer, opts gotree.ExportOptions[K]) error {
+  return t.Graph(opts).WriteMermaid(w)
+}
+
+// Graph returns the drawing that WriteDOT and WriteMermaid render
+func (t *AVLTree[K, V]) Graph(opts gotree.ExportOptions[K]) (*gotree.Graph) {
+  g := gotree.NewGraph(opts.Name, false)
+  avlGraph(g, t.Root, opts, t.comparator)
+  return g
+}
+
+// avlGraph adds the subtree of node to g and returns the id of node
+func avlGraph[K comparable, V any](g *gotree.Graph, node *AVLNode[K, V], opts gotree.ExportOptions[K], comp utils.Comparator[K]) (string) {
+  if node == nil {
+    return ""
+  }
+  id := g.AddNode(gotree.GraphNode{
+    Labels:    []string{fmt.Sprintf("%v", node.Key), fmt.Sprintf("(%d)", node.bf)},
+    Highlight: opts.Highlighted(node.Key, comp),
+  })
+  if IsLeaf(node) {
+    return id
+  }
+  for _, child := range node.Children {
+    if child == nil {
+      g.AddEdge(id, g.AddNode(gotree.GraphNode{Invisible: true}), 0)
+    } else {
+      g.AddEdge(id, avlGraph(g, child, opts, comp), 0)
+    }
+  }
+  return id
+}
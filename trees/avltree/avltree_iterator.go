@@ -1,39 +1,89 @@
 package avltree
 
 import(
-  "github.com/jenazads/goutils";
+  "iter";
+  "github.com/fmorenovr/gods/trees";
+  "github.com/fmorenovr/gods/utils";
 )
 
 func assertIteratorImplementation() {
-  var _ goutils.ReverseIteratorKey = (*Iterator)(nil)
+  var _ utils.ReverseIteratorWithKey[string, int] = (*Iterator[string, int])(nil)
 }
 
 // Iterator
-type Iterator struct {
-  tree     *AVLTree
-  node     *AVLNode
+type Iterator[K comparable, V any] struct {
+  tree     *AVLTree[K, V]
+  node     *AVLNode[K, V]
   position position
+  bounded  bool           // true for range iterators
+  low      *AVLNode[K, V] // first node of the range, nil if the range is empty
+  high     *AVLNode[K, V] // last node of the range, nil if the range is empty
 }
 
 type position byte
 
+// before means the iterator sits just before node, see IteratorAt
 const (
-  begin, between, end position = 0, 1, 2
+  begin, between, end, before position = 0, 1, 2, 3
 )
 
 // Iterator returns a stateful iterator whose elements are key/value pairs.
-func (t *AVLTree) Iterator() goutils.ReverseIteratorKey {
-  return &Iterator{tree: t, node: nil, position: begin}
+func (t *AVLTree[K, V]) Iterator() utils.ReverseIteratorWithKey[K, V] {
+  return &Iterator[K, V]{tree: t, node: nil, position: begin}
+}
+
+// RangeIterator returns a stateful iterator over the keys between lo and hi,
+// inclusivity tells whether lo and hi themselves are part of the range.
+// Begin/End and First/Last refer to the bounds of the range, not of the tree.
+func (t *AVLTree[K, V]) RangeIterator(lo, hi K, inclusivity gotree.Inclusivity) utils.ReverseIteratorWithKey[K, V] {
+  low := avlCeiling(t.Root, lo, inclusivity.IncludesLow(), t.comparator)
+  high := avlFloor(t.Root, hi, inclusivity.IncludesHigh(), t.comparator)
+  if low == nil || high == nil || t.comparator(low.Key, high.Key) > 0 {
+    low, high = nil, nil
+  }
+  return &Iterator[K, V]{tree: t, node: nil, position: begin, bounded: true, low: low, high: high}
+}
+
+// IteratorAt returns a stateful iterator placed just before the smallest key
+// larger than or equal to key: Next moves to that key, Prev to the one before it.
+func (t *AVLTree[K, V]) IteratorAt(key K) utils.ReverseIteratorWithKey[K, V] {
+  node := avlCeiling(t.Root, key, true, t.comparator)
+  if node == nil {
+    return &Iterator[K, V]{tree: t, node: nil, position: end}
+  }
+  return &Iterator[K, V]{tree: t, node: node, position: before}
+}
+
+// first node the iterator can reach
+func (iterator *Iterator[K, V]) lowest() *AVLNode[K, V] {
+  if iterator.bounded {
+    return iterator.low
+  }
+  return iterator.tree.Left()
+}
+
+// last node the iterator can reach
+func (iterator *Iterator[K, V]) highest() *AVLNode[K, V] {
+  if iterator.bounded {
+    return iterator.high
+  }
+  return iterator.tree.Right()
 }
 
 // Moves to the next element
-func (iterator *Iterator) Next() bool {
+func (iterator *Iterator[K, V]) Next() bool {
   switch iterator.position {
   case begin:
     iterator.position = between
-    iterator.node = iterator.tree.Left()
+    iterator.node = iterator.lowest()
+  case before:
+    iterator.position = between
   case between:
-    iterator.node = iterator.node.Next()
+    if iterator.bounded && iterator.node == iterator.high {
+      iterator.node = nil
+    } else {
+      iterator.node = iterator.node.Next()
+    }
   }
 
   if iterator.node == nil {
@@ -44,13 +94,20 @@ func (iterator *Iterator) Next() bool {
 }
 
 // Move to the Prev element
-func (iterator *Iterator) Prev() bool {
+func (iterator *Iterator[K, V]) Prev() bool {
   switch iterator.position {
   case end:
     iterator.position = between
-    iterator.node = iterator.tree.Right()
-  case between:
+    iterator.node = iterator.highest()
+  case before:
+    iterator.position = between
     iterator.node = iterator.node.Prev()
+  case between:
+    if iterator.bounded && iterator.node == iterator.low {
+      iterator.node = nil
+    } else {
+      iterator.node = iterator.node.Prev()
+    }
   }
 
   if iterator.node == nil {
@@ -61,41 +118,88 @@ func (iterator *Iterator) Prev() bool {
 }
 
 // Return current value
-func (iterator *Iterator) Value() interface{} {
-  if iterator.node == nil {
-    return nil
+func (iterator *Iterator[K, V]) Value() (value V) {
+  if iterator.node == nil || iterator.position != between {
+    return value
   }
   return iterator.node.Value
 }
 
 // Return current Key
-func (iterator *Iterator) Key() interface{} {
-  if iterator.node == nil {
-    return nil
+func (iterator *Iterator[K, V]) Key() (key K) {
+  if iterator.node == nil || iterator.position != between {
+    return key
   }
   return iterator.node.Key
 }
 
 // Set pointer to Begin state
-func (iterator *Iterator) Begin() {
+func (iterator *Iterator[K, V]) Begin() {
   iterator.node = nil
   iterator.position = begin
 }
 
 // Set pointer to last state
-func (iterator *Iterator) End() {
+func (iterator *Iterator[K, V]) End() {
   iterator.node = nil
   iterator.position = end
 }
 
 // Moves to the first element
-func (iterator *Iterator) First() bool {
+func (iterator *Iterator[K, V]) First() bool {
   iterator.Begin()
   return iterator.Next()
 }
 
 // Moves to the Last element
-func (iterator *Iterator) Last() bool {
+func (iterator *Iterator[K, V]) Last() bool {
   iterator.End()
   return iterator.Prev()
 }
+
+// All returns a sequence of the key/value pairs in key order, for use with range
+func (t *AVLTree[K, V]) All() iter.Seq2[K, V] {
+  return func(yield func(K, V) bool) {
+    it := t.Iterator()
+    for it.Next() {
+      if !yield(it.Key(), it.Value()) {
+        return
+      }
+    }
+  }
+}
+
+// Backward returns a sequence of the key/value pairs in reverse key order
+func (t *AVLTree[K, V]) Backward() iter.Seq2[K, V] {
+  return func(yield func(K, V) bool) {
+    it := t.Iterator()
+    it.End()
+    for it.Prev() {
+      if !yield(it.Key(), it.Value()) {
+        return
+      }
+    }
+  }
+}
+
+// KeysSeq returns a sequence of the keys in order, the lazy form of Keys
+func (t *AVLTree[K, V]) KeysSeq() iter.Seq[K] {
+  return func(yield func(K) bool) {
+    for key := range t.All() {
+      if !yield(key) {
+        return
+      }
+    }
+  }
+}
+
+// ValuesSeq returns a sequence of the values in key order, the lazy form of Values
+func (t *AVLTree[K, V]) ValuesSeq() iter.Seq[V] {
+  return func(yield func(V) bool) {
+    for _, value := range t.All() {
+      if !yield(value) {
+        return
+      }
+    }
+  }
+}
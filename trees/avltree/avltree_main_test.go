@@ -1,13 +1,646 @@
 package avltree_test
 
 import (
+  "bytes"
+  "encoding/json"
+  "errors"
   "fmt"
-  "github.com/jenazads/gods/trees/avltree"
-  "github.com/jenazads/goutils"
+  "os"
+  "slices"
+  "strconv"
+  "strings"
+  "testing"
+  "github.com/fmorenovr/gods/trees"
+  "github.com/fmorenovr/gods/trees/avltree"
+  "github.com/fmorenovr/gods/trees/treetest"
+  "github.com/fmorenovr/gods/utils"
 )
 
+func TestAVLTreeOrderedMap(t *testing.T) {
+  treetest.Run(t, func() gotree.OrderedMap[int, string] {
+    return avltree.NewAVLTree[int, string]()
+  }, strconv.Itoa)
+}
+
+func TestAVLTreeRangeIterator(t *testing.T) {
+  treetest.RunRange(t, func() gotree.RangeMap[int, string] {
+    return avltree.NewAVLTree[int, string]()
+  }, strconv.Itoa)
+}
+
+func TestAVLTreeSequences(t *testing.T) {
+  tree := avltree.NewAVLTree[int, string]()
+  for _, key := range []int{5, 1, 4, 2, 3} {
+    tree.Insert(key, strconv.Itoa(key))
+  }
+  if actualValue, expectedValue := slices.Collect(tree.KeysSeq()), tree.Keys(); !slices.Equal(actualValue, expectedValue) {
+    t.Errorf("Got %v expected %v", actualValue, expectedValue)
+  }
+  if actualValue, expectedValue := slices.Collect(tree.ValuesSeq()), tree.Values(); !slices.Equal(actualValue, expectedValue) {
+    t.Errorf("Got %v expected %v", actualValue, expectedValue)
+  }
+  var keys []int
+  for key := range tree.Backward() {
+    if key == 2 {
+      break
+    }
+    keys = append(keys, key)
+  }
+  if actualValue, expectedValue := keys, []int{5, 4, 3}; !slices.Equal(actualValue, expectedValue) {
+    t.Errorf("Got %v expected %v", actualValue, expectedValue)
+  }
+}
+
+func TestAVLTreeWalk(t *testing.T) {
+  tree := avltree.NewAVLTree[int, string]()
+  for _, key := range []int{4, 2, 6, 1, 3, 5, 7} {
+    tree.Insert(key, strconv.Itoa(key))
+  }
+  tests := []struct {
+    order    gotree.TraversalOrder
+    expected []int
+  }{
+    {gotree.PreOrder, []int{4, 2, 1, 3, 6, 5, 7}},
+    {gotree.InOrder, []int{1, 2, 3, 4, 5, 6, 7}},
+    {gotree.PostOrder, []int{1, 3, 2, 5, 7, 6, 4}},
+    {gotree.LevelOrder, []int{4, 2, 6, 1, 3, 5, 7}},
+  }
+  for _, test := range tests {
+    var keys []int
+    tree.Walk(test.order, func(key int, _ string) bool {
+      keys = append(keys, key)
+      return true
+    })
+    if !slices.Equal(keys, test.expected) {
+      t.Errorf("Walk(%v) got %v expected %v", test.order, keys, test.expected)
+    }
+    keys = keys[:0]
+    for key := range tree.Traverse(test.order) {
+      if len(keys) == 3 {
+        break
+      }
+      keys = append(keys, key)
+    }
+    if !slices.Equal(keys, test.expected[:3]) {
+      t.Errorf("Traverse(%v) got %v expected %v", test.order, keys, test.expected[:3])
+    }
+  }
+  var buffer bytes.Buffer
+  tree.PrintPreOrder(&buffer)
+  tree.PrintInOrder(&buffer)
+  tree.PrintPostOrder(&buffer)
+  if actualValue, expectedValue := buffer.String(), "4 2 1 3 6 5 7 \n1 2 3 4 5 6 7 \n1 3 2 5 7 6 4 \n"; actualValue != expectedValue {
+    t.Errorf("Got %q expected %q", actualValue, expectedValue)
+  }
+}
+
+func TestAVLTreeMissingKeys(t *testing.T) {
+  tree := avltree.NewAVLTree[int, string]()
+  assertMissing := func(key int) {
+    t.Helper()
+    if _, found := tree.Get(key); found {
+      t.Errorf("Got found for %v expected not found", key)
+    }
+    if node, found := tree.Parent(key); node != nil || found {
+      t.Errorf("Got %v, %v expected no parent for %v", node, found, key)
+    }
+    if node, found := tree.Brother(key); node != nil || found {
+      t.Errorf("Got %v, %v expected no brother for %v", node, found, key)
+    }
+    if height, found := tree.HeightOfNode(key); height != -1 || found {
+      t.Errorf("Got %v, %v expected no height for %v", height, found, key)
+    }
+    if node := tree.Search(key); node != nil {
+      t.Errorf("Got %v expected no node for %v", node, key)
+    }
+    tree.Remove(key)
+  }
+  assertMissing(1)
+  if _, _, found := tree.Min(); found {
+    t.Errorf("Got found expected an empty tree to have no minimum")
+  }
+  for _, lookup := range []func(int) (*avltree.AVLNode[int, string], bool){tree.Floor, tree.Ceiling, tree.Lower, tree.Higher} {
+    if node, found := lookup(1); node != nil || found {
+      t.Errorf("Got %v, %v expected nothing in an empty tree", node, found)
+    }
+  }
+  if node, found := tree.Select(0); node != nil || found {
+    t.Errorf("Got %v, %v expected nothing in an empty tree", node, found)
+  }
+
+  for _, key := range []int{2, 1, 3} {
+    tree.Insert(key, strconv.Itoa(key))
+  }
+  assertMissing(0)
+  assertMissing(4)
+  if value, found := tree.Get(2); !found || value != "2" {
+    t.Errorf("Got %v, %v expected %v", value, found, "2")
+  }
+  if node, found := tree.Parent(2); node != nil || !found {
+    t.Errorf("Got %v, %v expected the root to have no parent", node, found)
+  }
+  if node, found := tree.Parent(3); node == nil || node.Key != 2 || !found {
+    t.Errorf("Got %v, %v expected %v", node, found, 2)
+  }
+  if node, found := tree.Brother(3); node == nil || node.Key != 1 || !found {
+    t.Errorf("Got %v, %v expected %v", node, found, 1)
+  }
+  if height, found := tree.HeightOfNode(1); height != 1 || !found {
+    t.Errorf("Got %v, %v expected %v", height, found, 1)
+  }
+  if node, found := tree.Floor(0); node != nil || found {
+    t.Errorf("Got %v, %v expected no floor below the minimum", node, found)
+  }
+  if node, found := tree.Ceiling(4); node != nil || found {
+    t.Errorf("Got %v, %v expected no ceiling above the maximum", node, found)
+  }
+  if actualValue, expectedValue := tree.Size(), 3; actualValue != expectedValue {
+    t.Errorf("Got %v expected %v after removing missing keys", actualValue, expectedValue)
+  }
+}
+
+func TestAVLTreeValidate(t *testing.T) {
+  tree := avltree.NewAVLTree[int, string]()
+  if err := tree.Validate(); err != nil {
+    t.Errorf("Got %v expected %v", err, nil)
+  }
+  for _, key := range []int{4, 2, 6, 1, 3, 5, 7} {
+    tree.Insert(key, strconv.Itoa(key))
+  }
+  if err := tree.Validate(); err != nil {
+    t.Errorf("Got %v expected %v", err, nil)
+  }
+  tree.Root.Children[0].Key = 100
+  if err := tree.Validate(); err == nil || !strings.Contains(err.Error(), "100") {
+    t.Errorf("Got %v expected an error naming node 100", err)
+  }
+  tree.Root.Children[0].Key = 2
+  tree.Root.Children[1].Children[1].Parent = tree.Root
+  if err := tree.Validate(); err == nil || !strings.Contains(err.Error(), "7") {
+    t.Errorf("Got %v expected an error naming node 7", err)
+  }
+  tree.Root.Children[1].Children[1].Parent = tree.Root.Children[1]
+  tree.Root.Children[1].Children[1] = nil
+  if err := tree.Validate(); err == nil || !strings.Contains(err.Error(), "6") {
+    t.Errorf("Got %v expected an error naming node 6", err)
+  }
+}
+
+func TestAVLTreeExport(t *testing.T) {
+  tree := avltree.NewAVLTree[int, string]()
+  for _, key := range []int{2, 1, 3, 4} {
+    tree.Insert(key, strconv.Itoa(key))
+  }
+  var dot bytes.Buffer
+  if err := tree.WriteDOT(&dot, gotree.ExportOptions[int]{Name: "avl", Highlight: []int{3}}); err != nil {
+    t.Errorf("Got %v expected %v", err, nil)
+  }
+  for _, expectedValue := range []string{`digraph "avl" {`, `n0 [label="2 (1)"];`, `n2 [label="3 (1)", color="#ff8c00", penwidth=3];`, `n3 [style=invis, label=""];`, "n2 -> n3 [style=invis];", "n2 -> n4;"} {
+    if actualValue := dot.String(); !strings.Contains(actualValue, expectedValue) {
+      t.Errorf("Got %v expected it to contain %v", actualValue, expectedValue)
+    }
+  }
+  var mermaid bytes.Buffer
+  if err := tree.WriteMermaid(&mermaid, gotree.ExportOptions[int]{Highlight: []int{3, 4}}); err != nil {
+    t.Errorf("Got %v expected %v", err, nil)
+  }
+  for _, expectedValue := range []string{"graph TD", `n1(("1 (0)"))`, "n2 --> n4", "class n2,n4 highlight"} {
+    if actualValue := mermaid.String(); !strings.Contains(actualValue, expectedValue) {
+      t.Errorf("Got %v expected it to contain %v", actualValue, expectedValue)
+    }
+  }
+  if actualValue := mermaid.String(); strings.Contains(actualValue, "n3") {
+    t.Errorf("Got %v expected no placeholder node", actualValue)
+  }
+}
+
+func TestAVLTreeTracer(t *testing.T) {
+  tree := avltree.NewAVLTree[int, string]()
+  var trace bytes.Buffer
+  tracer := gotree.NewJSONTracer(&trace)
+  tree.SetTracer(tracer)
+  for _, key := range []int{1, 2, 3, 5, 4} {
+    tree.Insert(key, strconv.Itoa(key))
+  }
+  tree.Remove(1)
+  tree.SetTracer(nil)
+  tree.Insert(6, "6")
+  if tracer.Err() != nil {
+    t.Errorf("Got %v expected %v", tracer.Err(), nil)
+  }
+  events, err := gotree.ReadTrace(&trace)
+  if err != nil {
+    t.Errorf("Got %v expected %v", err, nil)
+  }
+  var steps []string
+  for _, event := range events {
+    if event.Kind == gotree.StepEvent {
+      steps = append(steps, fmt.Sprint(event.Name, event.Keys))
+    }
+  }
+  if actualValue, expectedValue := steps, []string{"rotateLeft[1 2]", "rotateRight[5 4]", "rotateLeft[3 4]", "rotateLeft[2 4]"}; !slices.Equal(actualValue, expectedValue) {
+    t.Errorf("Got %v expected %v", actualValue, expectedValue)
+  }
+  replayed := avltree.NewAVLTree[int, string]()
+  if err := gotree.Replay[int, string](events, replayed); err != nil {
+    t.Errorf("Got %v expected %v", err, nil)
+  }
+  if actualValue, expectedValue := replayed.Keys(), []int{2, 3, 4, 5}; !slices.Equal(actualValue, expectedValue) {
+    t.Errorf("Got %v expected %v", actualValue, expectedValue)
+  }
+  if value, _ := replayed.Get(4); value != "4" {
+    t.Errorf("Got %v expected %v", value, "4")
+  }
+}
+
+// point is a key without a natural order nor a JSON map key encoding
+type point struct {
+  X, Y int
+}
+
+func comparePoints(a, b point) int {
+  if a.X != b.X {
+    return a.X - b.X
+  }
+  return a.Y - b.Y
+}
+
+func TestAVLTreeMarshalJSON(t *testing.T) {
+  tree := avltree.NewAVLTree[int, string]()
+  for _, key := range []int{3, 1, 2} {
+    tree.Insert(key, strconv.Itoa(key))
+  }
+  data, err := json.Marshal(struct{ Index *avltree.AVLTree[int, string] }{tree})
+  if err != nil {
+    t.Errorf("Got %v expected %v", err, nil)
+  }
+  if actualValue, expectedValue := string(data), `{"Index":[{"key":1,"value":"1"},{"key":2,"value":"2"},{"key":3,"value":"3"}]}`; actualValue != expectedValue {
+    t.Errorf("Got %v expected %v", actualValue, expectedValue)
+  }
+  var decoded struct{ Index *avltree.AVLTree[int, string] }
+  if err := json.Unmarshal(data, &decoded); err != nil {
+    t.Errorf("Got %v expected %v", err, nil)
+  }
+  if actualValue, expectedValue := decoded.Index.Keys(), []int{1, 2, 3}; !slices.Equal(actualValue, expectedValue) {
+    t.Errorf("Got %v expected %v", actualValue, expectedValue)
+  }
+  decoded.Index.Insert(0, "0")
+  if err := decoded.Index.Validate(); err != nil {
+    t.Errorf("Got %v expected %v", err, nil)
+  }
+
+  points := avltree.NewAVLTreeWith[point, int](comparePoints, nil)
+  points.Insert(point{1, 2}, 12)
+  points.Insert(point{0, 5}, 5)
+  data, err = json.Marshal(points)
+  if actualValue, expectedValue := string(data), `[{"key":{"X":0,"Y":5},"value":5},{"key":{"X":1,"Y":2},"value":12}]`; err != nil || actualValue != expectedValue {
+    t.Errorf("Got %v, %v expected %v", actualValue, err, expectedValue)
+  }
+  if err := json.Unmarshal(data, new(avltree.AVLTree[point, int])); err == nil || !strings.Contains(err.Error(), "NewAVLTreeWith") {
+    t.Errorf("Got %v expected an error asking for a comparator", err)
+  }
+  decodedPoints := avltree.NewAVLTreeWith[point, int](comparePoints, nil)
+  if err := json.Unmarshal(data, decodedPoints); err != nil {
+    t.Errorf("Got %v expected %v", err, nil)
+  }
+  if value, found := decodedPoints.Get(point{1, 2}); !found || value != 12 {
+    t.Errorf("Got %v expected %v", value, 12)
+  }
+
+  if err := tree.UnmarshalJSON([]byte(`[{"key":"4","value":"4"}]`)); err == nil || !strings.Contains(err.Error(), "entry 0") {
+    t.Errorf("Got %v expected an error naming entry 0", err)
+  }
+  if actualValue, expectedValue := tree.Keys(), []int{1, 2, 3}; !slices.Equal(actualValue, expectedValue) {
+    t.Errorf("Got %v expected %v untouched by a failed decode", actualValue, expectedValue)
+  }
+}
+
+func TestAVLTreeCodecs(t *testing.T) {
+  tree := avltree.NewAVLTree[int, string]()
+  tree.Insert(255, "ff")
+  hex := utils.CodecFuncs[int]{
+    EncodeFunc: func(key int) ([]byte, error) { return json.Marshal(strconv.FormatInt(int64(key), 16)) },
+    DecodeFunc: func(data []byte) (int, error) {
+      var s string
+      if err := json.Unmarshal(data, &s); err != nil {
+        return 0, err
+      }
+      key, err := strconv.ParseInt(s, 16, 64)
+      return int(key), err
+    },
+  }
+  tree.SetCodecs(hex, nil)
+  data, err := json.Marshal(tree)
+  if actualValue, expectedValue := string(data), `[{"key":"ff","value":"ff"}]`; err != nil || actualValue != expectedValue {
+    t.Errorf("Got %v, %v expected %v", actualValue, err, expectedValue)
+  }
+  if err := json.Unmarshal([]byte(`[{"key":"10","value":"sixteen"}]`), tree); err != nil {
+    t.Errorf("Got %v expected %v", err, nil)
+  }
+  if value, found := tree.Get(16); !found || value != "sixteen" {
+    t.Errorf("Got %v expected %v", value, "sixteen")
+  }
+}
+
+func TestAVLTreeMarshalBinary(t *testing.T) {
+  tree := avltree.NewAVLTree[int, string]()
+  for _, key := range []int{5, 9, 1, 7, 3, 8, 2, 6, 4, 0} {
+    tree.Insert(key, strconv.Itoa(key))
+  }
+  data, err := tree.MarshalBinary()
+  if err != nil {
+    t.Errorf("Got %v expected %v", err, nil)
+  }
+  decoded := new(avltree.AVLTree[int, string])
+  if err := decoded.UnmarshalBinary(data); err != nil {
+    t.Errorf("Got %v expected %v", err, nil)
+  }
+  if actualValue, expectedValue := decoded.Keys(), tree.Keys(); !slices.Equal(actualValue, expectedValue) {
+    t.Errorf("Got %v expected %v", actualValue, expectedValue)
+  }
+  if value, found := decoded.Get(7); !found || value != "7" {
+    t.Errorf("Got %v expected %v", value, "7")
+  }
+  if err := decoded.Validate(); err != nil {
+    t.Errorf("Got %v expected %v", err, nil)
+  }
+  decoded.Insert(10, "10")
+  decoded.Remove(5)
+  if err := decoded.Validate(); err != nil {
+    t.Errorf("Got %v expected %v", err, nil)
+  }
+
+  corrupt := bytes.Clone(data)
+  corrupt[len(corrupt)/2] ^= 0xff
+  if err := decoded.UnmarshalBinary(corrupt); err == nil || !strings.Contains(err.Error(), "checksum") {
+    t.Errorf("Got %v expected a checksum error", err)
+  }
+  if actualValue, expectedValue := decoded.Size(), 10; actualValue != expectedValue {
+    t.Errorf("Got %v expected %v untouched by a failed decode", actualValue, expectedValue)
+  }
+  if err := decoded.UnmarshalBinary(data[:len(data)-1]); err == nil {
+    t.Errorf("Got %v expected an error for truncated data", err)
+  }
+  reversed := avltree.NewAVLTreeWith[int, string](func(a, b int) int { return b - a }, nil)
+  if err := reversed.UnmarshalBinary(data); err == nil || !strings.Contains(err.Error(), "comparator") {
+    t.Errorf("Got %v expected an error about the order of keys", err)
+  }
+  other, _ := gotree.MarshalBinaryEntries[int, string](gotree.BinaryHeader{Kind: "btree", Order: 3}, func(func(int, string) bool) {}, nil, nil)
+  if err := decoded.UnmarshalBinary(other); err == nil || !strings.Contains(err.Error(), "btree") {
+    t.Errorf("Got %v expected an error naming the kind", err)
+  }
+  empty, _ := avltree.NewAVLTree[int, string]().MarshalBinary()
+  if err := decoded.UnmarshalBinary(empty); err != nil || !decoded.IsEmpty() {
+    t.Errorf("Got %v, %v expected an empty tree", decoded.Keys(), err)
+  }
+}
+
+func TestAVLTreeMarshalShape(t *testing.T) {
+  tree := avltree.NewAVLTree[int, string]()
+  for _, key := range []int{2, 1, 4, 3} {
+    tree.Insert(key, strconv.Itoa(key))
+  }
+  data, err := tree.MarshalShape()
+  expected := `[{"key":2,"value":"2","bf":1},{"key":1,"value":"1","bf":0},{"key":4,"value":"4","bf":-1},null,null,{"key":3,"value":"3","bf":0}]`
+  if actualValue := string(data); err != nil || actualValue != expected {
+    t.Errorf("Got %v, %v expected %v", actualValue, err, expected)
+  }
+  decoded := new(avltree.AVLTree[int, string])
+  if err := decoded.UnmarshalShape(data); err != nil {
+    t.Errorf("Got %v expected %v", err, nil)
+  }
+  if !decoded.IsSameAs(tree) {
+    t.Errorf("Got %v expected the layout of %v", decoded, tree)
+  }
+  if actualValue, expectedValue := decoded.Size(), 4; actualValue != expectedValue {
+    t.Errorf("Got %v expected %v", actualValue, expectedValue)
+  }
+  if err := decoded.UnmarshalShape([]byte(`[{"key":2},{"key":1},{"key":3}]`)); err != nil {
+    t.Errorf("Got %v expected balance factors to be optional", err)
+  }
+  for _, data := range []string{
+    `[{"key":2,"bf":1},{"key":1},{"key":3}]`,
+    `[{"key":1},null,{"key":2},null,{"key":3}]`,
+    `[{"key":2},{"key":3}]`,
+    `[{"key":2},null,null,{"key":3}]`,
+  } {
+    if err := decoded.UnmarshalShape([]byte(data)); !errors.Is(err, gotree.ErrCorruptData) {
+      t.Errorf("Got %v expected %v for %v", err, gotree.ErrCorruptData, data)
+    }
+  }
+  if actualValue, expectedValue := decoded.Keys(), []int{1, 2, 3}; !slices.Equal(actualValue, expectedValue) {
+    t.Errorf("Got %v expected %v untouched by a failed decode", actualValue, expectedValue)
+  }
+}
+
+func TestAVLTreeEncodeDecode(t *testing.T) {
+  tree := avltree.NewAVLTree[int, string]()
+  for i := 0; i < 100; i++ {
+    tree.Insert(i*7%100, strconv.Itoa(i))
+  }
+  var buffer bytes.Buffer
+  if err := tree.Encode(&buffer); err != nil {
+    t.Errorf("Got %v expected %v", err, nil)
+  }
+  if data, _ := json.Marshal(tree); buffer.String() != string(data) {
+    t.Errorf("Got %v expected %s", buffer.String(), data)
+  }
+  decoded := new(avltree.AVLTree[int, string])
+  if err := decoded.Decode(&buffer); err != nil {
+    t.Errorf("Got %v expected %v", err, nil)
+  }
+  if actualValue, expectedValue := decoded.Keys(), tree.Keys(); !slices.Equal(actualValue, expectedValue) {
+    t.Errorf("Got %v expected %v", actualValue, expectedValue)
+  }
+  if err := decoded.Validate(); err != nil {
+    t.Errorf("Got %v expected %v", err, nil)
+  }
+  unsorted := `[{"key":1,"value":"a"},{"key":5,"value":"b"},{"key":3,"value":"c"},{"key":5,"value":"d"},{"key":0,"value":"e"}]`
+  if err := decoded.Decode(strings.NewReader(unsorted)); err != nil {
+    t.Errorf("Got %v expected %v", err, nil)
+  }
+  if actualValue, expectedValue := decoded.Keys(), []int{0, 1, 3, 5}; !slices.Equal(actualValue, expectedValue) {
+    t.Errorf("Got %v expected %v", actualValue, expectedValue)
+  }
+  if value, _ := decoded.Get(5); value != "d" {
+    t.Errorf("Got %v expected %v", value, "d")
+  }
+  if err := decoded.Validate(); err != nil {
+    t.Errorf("Got %v expected %v", err, nil)
+  }
+  if err := decoded.Decode(strings.NewReader(`[{"key":7,"value":"x"},{"key":`)); err == nil || decoded.Size() != 4 {
+    t.Errorf("Got %v expected an error and the tree untouched", err)
+  }
+}
+
+// height recomputes the height of node from scratch, failing t when unbalanced
+func height(t *testing.T, node *avltree.AVLNode[int, string]) int {
+  if node == nil {
+    return -1
+  }
+  left, right := height(t, node.Children[0]), height(t, node.Children[1])
+  if left-right > 1 || right-left > 1 {
+    t.Errorf("node %v unbalanced: left %v right %v", node.Key, left, right)
+  }
+  return max(left, right) + 1
+}
+
+func TestAVLTreeCachedHeight(t *testing.T) {
+  tree := avltree.NewAVLTree[int, string]()
+  if actualValue, expectedValue := tree.Height(), -1; actualValue != expectedValue {
+    t.Errorf("Got %v expected %v", actualValue, expectedValue)
+  }
+  for key := 1; key <= 1000; key++ {
+    tree.Insert(key, strconv.Itoa(key))
+  }
+  for key := 2000; key > 1000; key-- {
+    tree.Insert(key, strconv.Itoa(key))
+  }
+  if actualValue, expectedValue := tree.Height(), height(t, tree.Root); actualValue != expectedValue {
+    t.Errorf("Got %v expected %v", actualValue, expectedValue)
+  }
+  for key := 1; key <= 2000; key += 3 {
+    tree.Remove(key)
+  }
+  if actualValue, expectedValue := tree.Height(), height(t, tree.Root); actualValue != expectedValue {
+    t.Errorf("Got %v expected %v", actualValue, expectedValue)
+  }
+  if actualValue := tree.Height(); actualValue > 15 {
+    t.Errorf("Got height %v for %v nodes", actualValue, tree.Size())
+  }
+}
+
+func TestAVLTreeSize(t *testing.T) {
+  tree := avltree.NewAVLTree[int, string]()
+  assertSize := func(expectedValue int) {
+    t.Helper()
+    if actualValue := tree.Size(); actualValue != expectedValue {
+      t.Errorf("Got %v expected %v", actualValue, expectedValue)
+    }
+    if actualValue := len(tree.Keys()); actualValue != expectedValue {
+      t.Errorf("Got %v expected %v", actualValue, expectedValue)
+    }
+  }
+  assertSize(0)
+  for key := 1; key <= 10; key++ {
+    tree.Insert(key, strconv.Itoa(key))
+  }
+  assertSize(10)
+  tree.Insert(5, "five")
+  assertSize(10)
+  tree.Remove(5)
+  tree.Remove(5)
+  tree.Remove(42)
+  assertSize(9)
+  data, err := tree.ToJSON()
+  if err != nil {
+    t.Fatalf("Got error %v", err)
+  }
+  tree.Clear()
+  assertSize(0)
+  if err := tree.FromJSON(data); err != nil {
+    t.Fatalf("Got error %v", err)
+  }
+  assertSize(9)
+}
+
+func TestAVLTreeSelectRankCountRange(t *testing.T) {
+  tree := avltree.NewAVLTree[int, string]()
+  if node, found := tree.Select(0); node != nil || found {
+    t.Errorf("Got %v expected %v", node, nil)
+  }
+  if actualValue := tree.Rank(1); actualValue != 0 {
+    t.Errorf("Got %v expected %v", actualValue, 0)
+  }
+  // even keys 0..398 inserted in a scrambled order, then every third removed
+  var keys []int
+  for i := 0; i < 200; i++ {
+    tree.Insert((i*67%200)*2, strconv.Itoa(i))
+  }
+  for key := 0; key < 400; key += 2 {
+    if key%3 == 0 {
+      tree.Remove(key)
+    } else {
+      keys = append(keys, key)
+    }
+  }
+  for i, key := range keys {
+    if node, found := tree.Select(i); !found || node.Key != key {
+      t.Errorf("Select(%v) got %v expected %v", i, node, key)
+    }
+    if actualValue := tree.Rank(key); actualValue != i {
+      t.Errorf("Rank(%v) got %v expected %v", key, actualValue, i)
+    }
+    if actualValue := tree.Rank(key + 1); actualValue != i+1 {
+      t.Errorf("Rank(%v) got %v expected %v", key+1, actualValue, i+1)
+    }
+  }
+  for _, k := range []int{-1, len(keys)} {
+    if node, found := tree.Select(k); node != nil || found {
+      t.Errorf("Select(%v) got %v expected %v", k, node, nil)
+    }
+  }
+  tests := [][]int{
+    {-10, 500, len(keys)},
+    {2, 4, 2},
+    {3, 5, 1},
+    {6, 6, 0},
+    {8, 8, 1},
+    {10, 2, 0},
+    {398, 1000, 1},
+  }
+  for _, test := range tests {
+    if actualValue := tree.CountRange(test[0], test[1]); actualValue != test[2] {
+      t.Errorf("CountRange(%v, %v) got %v expected %v", test[0], test[1], actualValue, test[2])
+    }
+  }
+}
+
+func TestAVLTreeFloorCeilingLowerHigher(t *testing.T) {
+  tree := avltree.NewAVLTree[int, string]()
+  if node, found := tree.Floor(1); node != nil || found {
+    t.Errorf("Got %v expected %v", node, nil)
+  }
+  if node, found := tree.Higher(1); node != nil || found {
+    t.Errorf("Got %v expected %v", node, nil)
+  }
+  for key := 2; key <= 20; key += 2 {
+    tree.Insert(key, strconv.Itoa(key))
+  }
+  key := func(node *avltree.AVLNode[int, string], found bool) int {
+    if !found {
+      return -1
+    }
+    return node.Key
+  }
+  // key, floor, ceiling, lower, higher (-1 is not found)
+  tests := [][]int{
+    {1, -1, 2, -1, 2},
+    {2, 2, 2, -1, 4},
+    {3, 2, 4, 2, 4},
+    {10, 10, 10, 8, 12},
+    {11, 10, 12, 10, 12},
+    {20, 20, 20, 18, -1},
+    {21, 20, -1, 20, -1},
+  }
+  for _, test := range tests {
+    if actual := key(tree.Floor(test[0])); actual != test[1] {
+      t.Errorf("Floor(%v) got %v expected %v", test[0], actual, test[1])
+    }
+    if actual := key(tree.Ceiling(test[0])); actual != test[2] {
+      t.Errorf("Ceiling(%v) got %v expected %v", test[0], actual, test[2])
+    }
+    if actual := key(tree.Lower(test[0])); actual != test[3] {
+      t.Errorf("Lower(%v) got %v expected %v", test[0], actual, test[3])
+    }
+    if actual := key(tree.Higher(test[0])); actual != test[4] {
+      t.Errorf("Higher(%v) got %v expected %v", test[0], actual, test[4])
+    }
+  }
+}
+
 func Example_avlTree() {
-  tree := avltree.NewAVLTree(goutils.IntComparator, goutils.IntOperator)
+  tree := avltree.NewAVLTree[int, string]()
 
   tree.Insert(1, "x")
   tree.Insert(2, "b")
@@ -19,13 +652,13 @@ func Example_avlTree() {
   tree.Insert(7, "g")
   tree.Insert(8, "h")
 
-  tree.PrintPreOrder()
-  tree.PrintInOrder()
-  tree.PrintPostOrder()
+  tree.PrintPreOrder(os.Stdout)
+  tree.PrintInOrder(os.Stdout)
+  tree.PrintPostOrder(os.Stdout)
 
   fmt.Println(tree.Ceiling(4))
   fmt.Println(tree.Floor(5))
-  tree.Print()
+  tree.Print(os.Stdout)
 
   fmt.Println(tree.Values())
   fmt.Println(tree.Keys())
@@ -52,3 +685,147 @@ func Example_avlTree() {
   tree.IsEmpty()
   tree.Size()
 }
+
+func benchmarkGet(b *testing.B, tree *avltree.AVLTree[int, struct{}], size int) {
+  for i := 0; i < b.N; i++ {
+    for n := 0; n < size; n++ {
+      tree.Get(n)
+    }
+  }
+}
+
+func benchmarkInsert(b *testing.B, tree *avltree.AVLTree[int, struct{}], size int) {
+  for i := 0; i < b.N; i++ {
+    for n := 0; n < size; n++ {
+      tree.Insert(n, struct{}{})
+    }
+  }
+}
+
+func benchmarkRemove(b *testing.B, tree *avltree.AVLTree[int, struct{}], size int) {
+  for i := 0; i < b.N; i++ {
+    for n := 0; n < size; n++ {
+      tree.Remove(n)
+    }
+  }
+}
+
+func BenchmarkAVLTreeGet100(b *testing.B) {
+  b.StopTimer()
+  size := 100
+  tree := avltree.NewAVLTree[int, struct{}]()
+  for n := 0; n < size; n++ {
+    tree.Insert(n, struct{}{})
+  }
+  b.StartTimer()
+  benchmarkGet(b, tree, size)
+}
+
+func BenchmarkAVLTreeGet1000(b *testing.B) {
+  b.StopTimer()
+  size := 1000
+  tree := avltree.NewAVLTree[int, struct{}]()
+  for n := 0; n < size; n++ {
+    tree.Insert(n, struct{}{})
+  }
+  b.StartTimer()
+  benchmarkGet(b, tree, size)
+}
+
+func BenchmarkAVLTreeGet10000(b *testing.B) {
+  b.StopTimer()
+  size := 10000
+  tree := avltree.NewAVLTree[int, struct{}]()
+  for n := 0; n < size; n++ {
+    tree.Insert(n, struct{}{})
+  }
+  b.StartTimer()
+  benchmarkGet(b, tree, size)
+}
+
+func BenchmarkAVLTreeGet100000(b *testing.B) {
+  b.StopTimer()
+  size := 100000
+  tree := avltree.NewAVLTree[int, struct{}]()
+  for n := 0; n < size; n++ {
+    tree.Insert(n, struct{}{})
+  }
+  b.StartTimer()
+  benchmarkGet(b, tree, size)
+}
+
+func BenchmarkAVLTreeInsert100(b *testing.B) {
+  b.StopTimer()
+  size := 100
+  tree := avltree.NewAVLTree[int, struct{}]()
+  b.StartTimer()
+  benchmarkInsert(b, tree, size)
+}
+
+func BenchmarkAVLTreeInsert1000(b *testing.B) {
+  b.StopTimer()
+  size := 1000
+  tree := avltree.NewAVLTree[int, struct{}]()
+  b.StartTimer()
+  benchmarkInsert(b, tree, size)
+}
+
+func BenchmarkAVLTreeInsert10000(b *testing.B) {
+  b.StopTimer()
+  size := 10000
+  tree := avltree.NewAVLTree[int, struct{}]()
+  b.StartTimer()
+  benchmarkInsert(b, tree, size)
+}
+
+func BenchmarkAVLTreeInsert100000(b *testing.B) {
+  b.StopTimer()
+  size := 100000
+  tree := avltree.NewAVLTree[int, struct{}]()
+  b.StartTimer()
+  benchmarkInsert(b, tree, size)
+}
+
+func BenchmarkAVLTreeRemove100(b *testing.B) {
+  b.StopTimer()
+  size := 100
+  tree := avltree.NewAVLTree[int, struct{}]()
+  for n := 0; n < size; n++ {
+    tree.Insert(n, struct{}{})
+  }
+  b.StartTimer()
+  benchmarkRemove(b, tree, size)
+}
+
+func BenchmarkAVLTreeRemove1000(b *testing.B) {
+  b.StopTimer()
+  size := 1000
+  tree := avltree.NewAVLTree[int, struct{}]()
+  for n := 0; n < size; n++ {
+    tree.Insert(n, struct{}{})
+  }
+  b.StartTimer()
+  benchmarkRemove(b, tree, size)
+}
+
+func BenchmarkAVLTreeRemove10000(b *testing.B) {
+  b.StopTimer()
+  size := 10000
+  tree := avltree.NewAVLTree[int, struct{}]()
+  for n := 0; n < size; n++ {
+    tree.Insert(n, struct{}{})
+  }
+  b.StartTimer()
+  benchmarkRemove(b, tree, size)
+}
+
+func BenchmarkAVLTreeRemove100000(b *testing.B) {
+  b.StopTimer()
+  size := 100000
+  tree := avltree.NewAVLTree[int, struct{}]()
+  for n := 0; n < size; n++ {
+    tree.Insert(n, struct{}{})
+  }
+  b.StartTimer()
+  benchmarkRemove(b, tree, size)
+}
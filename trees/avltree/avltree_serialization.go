@@ -1,28 +1,36 @@
 package avltree
 
 import (
+  "encoding";
   "encoding/json";
-  "github.com/jenazads/goutils";
+  "fmt";
+  "io";
+  "github.com/fmorenovr/gods/trees";
+  "github.com/fmorenovr/gods/utils";
 )
 
 func assertSerializationImplementation() {
-  var _ goutils.JSONSerializer = (*AVLTree)(nil)
-  var _ goutils.JSONDeserializer = (*AVLTree)(nil)
+  var _ utils.JSONSerializer = (*AVLTree[string, int])(nil)
+  var _ utils.JSONDeserializer = (*AVLTree[string, int])(nil)
+  var _ json.Marshaler = (*AVLTree[string, int])(nil)
+  var _ json.Unmarshaler = (*AVLTree[string, int])(nil)
+  var _ encoding.BinaryMarshaler = (*AVLTree[string, int])(nil)
+  var _ encoding.BinaryUnmarshaler = (*AVLTree[string, int])(nil)
 }
 
 // ToJSON return JSON format of elements
-func (t *AVLTree) ToJSON() ([]byte, error) {
-  elements := make(map[string]interface{})
+func (t *AVLTree[K, V]) ToJSON() ([]byte, error) {
+  elements := make(map[K]V)
   it := t.Iterator()
   for it.Next() {
-    elements[goutils.ToString(it.Key())] = it.Value()
+    elements[it.Key()] = it.Value()
   }
   return json.Marshal(&elements)
 }
 
 // FromJSON Convert to JSON format the elements
-func (t *AVLTree) FromJSON(data []byte) error {
-  elements := make(map[string]interface{})
+func (t *AVLTree[K, V]) FromJSON(data []byte) error {
+  elements := make(map[K]V)
   err := json.Unmarshal(data, &elements)
   if err == nil {
     t.Clear()
@@ -30,5 +38,185 @@ func (t *AVLTree) FromJSON(data []byte) error {
       t.Insert(key, value)
     }
   }
-  return err
+  return gotree.CorruptJSON(err)
+}
+
+// SetCodecs sets how the JSON and binary forms encode keys and values, nil keeps the defaults
+// (encoding/json and utils.BinaryCodec). In JSON the codecs must write JSON values.
+func (t *AVLTree[K, V]) SetCodecs(keys utils.Codec[K], values utils.Codec[V]) {
+  t.keyCodec, t.valueCodec = keys, values
+}
+
+// MarshalJSON writes the tree as [{"key":..,"value":..}, ...] in key order, keys keep their JSON type
+func (t *AVLTree[K, V]) MarshalJSON() ([]byte, error) {
+  return gotree.MarshalEntries(t.All(), t.keyCodec, t.valueCodec)
+}
+
+// UnmarshalJSON replaces the contents of the tree with the entries written by MarshalJSON.
+// A zero-value tree, as json.Unmarshal allocates it, orders keys with utils.DefaultComparator.
+func (t *AVLTree[K, V]) UnmarshalJSON(data []byte) error {
+  comparator := t.comparator
+  if comparator == nil {
+    if comparator = utils.DefaultComparator[K](); comparator == nil {
+      return fmt.Errorf("avltree: no natural order for keys of type %T, build the tree with NewAVLTreeWith", *new(K))
+    }
+  }
+  keys, values, err := gotree.UnmarshalEntries(data, t.keyCodec, t.valueCodec)
+  if err != nil {
+    return err
+  }
+  t.comparator = comparator
+  t.Clear()
+  for i, key := range keys {
+    t.Insert(key, values[i])
+  }
+  return nil
+}
+
+// MarshalBinary writes the tree in the binary form of gotree.MarshalBinaryEntries, in key order
+func (t *AVLTree[K, V]) MarshalBinary() ([]byte, error) {
+  return gotree.MarshalBinaryEntries(gotree.BinaryHeader{Kind: "avltree", Count: t.size}, t.All(), t.keyCodec, t.valueCodec)
+}
+
+// UnmarshalBinary replaces the contents of the tree with the entries written by MarshalBinary,
+// building the balanced tree from the sorted entries in O(n). Corrupt data is an error and
+// leaves the tree untouched. A zero-value tree orders keys with utils.DefaultComparator.
+func (t *AVLTree[K, V]) UnmarshalBinary(data []byte) error {
+  comparator := t.comparator
+  if comparator == nil {
+    if comparator = utils.DefaultComparator[K](); comparator == nil {
+      return fmt.Errorf("avltree: no natural order for keys of type %T, build the tree with NewAVLTreeWith", *new(K))
+    }
+  }
+  _, keys, values, err := gotree.UnmarshalBinaryEntries(data, "avltree", comparator, t.keyCodec, t.valueCodec)
+  if err != nil {
+    return err
+  }
+  t.comparator = comparator
+  nodes := make([]*AVLNode[K, V], len(keys))
+  for i, key := range keys {
+    nodes[i] = NewAVLNode(key, values[i], nil)
+  }
+  t.Root = avlBuild(nodes, nil)
+  t.size = len(nodes)
+  return nil
+}
+
+// Encode streams the tree to w in the JSON form of MarshalJSON, one entry at a time
+func (t *AVLTree[K, V]) Encode(w io.Writer) error {
+  return gotree.EncodeEntries(w, t.All(), t.keyCodec, t.valueCodec)
+}
+
+// Decode replaces the contents of the tree with the JSON form of MarshalJSON read from r one entry
+// at a time. Entries in key order, as Encode writes them, become nodes linked into a balanced tree
+// at the end without rebalancing; from the first entry out of order on, the rest is inserted one by
+// one. An error leaves the tree untouched. A zero-value tree orders keys with utils.DefaultComparator.
+func (t *AVLTree[K, V]) Decode(r io.Reader) error {
+  comparator := t.comparator
+  if comparator == nil {
+    if comparator = utils.DefaultComparator[K](); comparator == nil {
+      return fmt.Errorf("avltree: no natural order for keys of type %T, build the tree with NewAVLTreeWith", *new(K))
+    }
+  }
+  loaded := &AVLTree[K, V]{comparator: comparator, operator: t.operator}
+  var nodes []*AVLNode[K, V]
+  err := gotree.DecodeEntries(r, t.keyCodec, t.valueCodec, func(key K, value V) error {
+    if loaded.Root == nil && (len(nodes) == 0 || comparator(nodes[len(nodes)-1].Key, key) < 0) {
+      nodes = append(nodes, NewAVLNode(key, value, nil))
+      return nil
+    }
+    if loaded.Root == nil {
+      loaded.Root, loaded.size = avlBuild(nodes, nil), len(nodes)
+    }
+    loaded.Insert(key, value)
+    return nil
+  })
+  if err != nil {
+    return err
+  }
+  if loaded.Root == nil {
+    loaded.Root, loaded.size = avlBuild(nodes, nil), len(nodes)
+  }
+  t.Root, t.size, t.comparator = loaded.Root, loaded.size, comparator
+  return nil
+}
+
+// MarshalShape writes the exact layout of the tree as the level-order JSON array of
+// gotree.MarshalShape, every node with its balance factor: [{"key":2,"value":..,"bf":0},{..},null,..]
+func (t *AVLTree[K, V]) MarshalShape() ([]byte, error) {
+  return gotree.MarshalShape(t.Root, t.shape(nil), t.keyCodec, t.valueCodec)
+}
+
+// UnmarshalShape replaces the tree with the layout written by MarshalShape. The rebuilt tree must
+// be a valid AVL tree and match the balance factors of the data, which may be left out, otherwise
+// it is an error and the tree is left untouched. A zero-value tree orders keys with
+// utils.DefaultComparator.
+func (t *AVLTree[K, V]) UnmarshalShape(data []byte) error {
+  comparator := t.comparator
+  if comparator == nil {
+    if comparator = utils.DefaultComparator[K](); comparator == nil {
+      return fmt.Errorf("avltree: no natural order for keys of type %T, build the tree with NewAVLTreeWith", *new(K))
+    }
+  }
+  balances := make(map[*AVLNode[K, V]]int)
+  root, size, err := gotree.UnmarshalShape(data, t.shape(balances), t.keyCodec, t.valueCodec)
+  if err != nil {
+    return err
+  }
+  loaded := &AVLTree[K, V]{Root: root, comparator: comparator, size: size}
+  if err := avlRestore(root, balances); err != nil {
+    return gotree.CorruptEntry(-1, -1, err)
+  }
+  if err := loaded.Validate(); err != nil {
+    return gotree.CorruptEntry(-1, -1, err)
+  }
+  t.Root, t.size, t.comparator = loaded.Root, loaded.size, comparator
+  return nil
+}
+
+// shape returns how gotree walks and rebuilds the nodes of the tree, balances collects the
+// balance factors of the data
+func (t *AVLTree[K, V]) shape(balances map[*AVLNode[K, V]]int) gotree.Shape[*AVLNode[K, V], K, V] {
+  return gotree.Shape[*AVLNode[K, V], K, V]{
+    Children: func(node *AVLNode[K, V]) (*AVLNode[K, V], *AVLNode[K, V]) {
+      return node.Children[0], node.Children[1]
+    },
+    Node: func(node *AVLNode[K, V]) gotree.ShapeNode[K, V] {
+      bf := node.bf
+      return gotree.ShapeNode[K, V]{Key: node.Key, Value: node.Value, Balance: &bf}
+    },
+    New: func(s gotree.ShapeNode[K, V]) (*AVLNode[K, V], error) {
+      node := NewAVLNode(s.Key, s.Value, nil)
+      if s.Balance != nil {
+        balances[node] = *s.Balance
+      }
+      return node, nil
+    },
+    Link: func(parent, left, right *AVLNode[K, V]) {
+      parent.Children = [2]*AVLNode[K, V]{left, right}
+      for _, child := range parent.Children {
+        if child != nil {
+          child.Parent = parent
+        }
+      }
+    },
+  }
+}
+
+// avlRestore recomputes the cached fields under node and checks them against the balance
+// factors read with its shape
+func avlRestore[K comparable, V any](node *AVLNode[K, V], balances map[*AVLNode[K, V]]int) error {
+  if node == nil {
+    return nil
+  }
+  for _, child := range node.Children {
+    if err := avlRestore(child, balances); err != nil {
+      return err
+    }
+  }
+  avlUpdate(node)
+  if bf, ok := balances[node]; ok && bf != node.bf {
+    return fmt.Errorf("avltree: node %v has balance factor %v, its shape gives %v", node.Key, bf, node.bf)
+  }
+  return nil
 }
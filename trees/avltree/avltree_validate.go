@@ -0,0 +1,62 @@
+package avltree
+
+import (
+  "fmt";
+)
+
+// Validate checks the AVL invariants: key order, parent pointers, cached heights,
+// sizes and balance factors, and the number of nodes. It returns nil for a sound tree
+// or an error naming the first offending node.
+func (t *AVLTree[K, V]) Validate() error {
+  if t.Root != nil && t.Root.Parent != nil {
+    return fmt.Errorf("avltree: root %v has parent %v", t.Root.Key, t.Root.Parent.Key)
+  }
+  if _, err := t.validate(t.Root, nil, nil); err != nil {
+    return err
+  }
+  if size := avlSize(t.Root); size != t.size {
+    return fmt.Errorf("avltree: tree size is %v but it holds %v nodes", t.size, size)
+  }
+  return nil
+}
+
+// validate checks the subtree of node, whose keys must lie strictly between low and high
+// (nil is unbounded), and returns its height
+func (t *AVLTree[K, V]) validate(node, low, high *AVLNode[K, V]) (int, error) {
+  if node == nil {
+    return -1, nil
+  }
+  if low != nil && t.comparator(node.Key, low.Key) <= 0 {
+    return 0, fmt.Errorf("avltree: node %v is not greater than its ancestor %v", node.Key, low.Key)
+  }
+  if high != nil && t.comparator(node.Key, high.Key) >= 0 {
+    return 0, fmt.Errorf("avltree: node %v is not smaller than its ancestor %v", node.Key, high.Key)
+  }
+  for _, child := range node.Children {
+    if child != nil && child.Parent != node {
+      return 0, fmt.Errorf("avltree: node %v has child %v whose parent is not %v", node.Key, child.Key, node.Key)
+    }
+  }
+  left, err := t.validate(node.Children[0], low, node)
+  if err != nil {
+    return 0, err
+  }
+  right, err := t.validate(node.Children[1], node, high)
+  if err != nil {
+    return 0, err
+  }
+  height := max(left, right) + 1
+  if node.height != height {
+    return 0, fmt.Errorf("avltree: node %v caches height %v but has height %v", node.Key, node.height, height)
+  }
+  if size := avlSize(node.Children[0]) + avlSize(node.Children[1]) + 1; node.size != size {
+    return 0, fmt.Errorf("avltree: node %v caches size %v but has size %v", node.Key, node.size, size)
+  }
+  if node.bf != right-left {
+    return 0, fmt.Errorf("avltree: node %v has balance factor %v but its subtrees differ by %v", node.Key, node.bf, right-left)
+  }
+  if node.bf < -1 || node.bf > 1 {
+    return 0, fmt.Errorf("avltree: node %v is unbalanced, balance factor %v", node.Key, node.bf)
+  }
+  return height, nil
+}
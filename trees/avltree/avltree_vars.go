@@ -2,23 +2,48 @@ package avltree
 
 import (
   "fmt";
-  "github.com/jenazads/goutils";
-  "github.com/jenazads/gods/trees";
+  "io";
+  "github.com/fmorenovr/gods/utils";
+  "github.com/fmorenovr/gods/trees";
 )
 
 func assertTreeImplementation() {
-  var _ gotree.GoTree = new(AVLTree)
+  var _ gotree.GoTree[int] = new(AVLTree[string, int])
+  var _ gotree.RangeMap[string, int] = new(AVLTree[string, int])
 }
 
-func avlBalanceFactor(node *AVLNode) int {
+func avlBalanceFactor[K comparable, V any](node *AVLNode[K, V]) int {
   if node == nil {
     return 0;
   }
   return avlHeight(node.Children[1]) - avlHeight(node.Children[0]);
 }
 
-func avlRightRotate(y *AVLNode) *AVLNode {
+// avlUpdate recomputes the cached height, size and balance factor of node from its children
+func avlUpdate[K comparable, V any](node *AVLNode[K, V]) {
+  left, right := avlHeight(node.Children[0]), avlHeight(node.Children[1])
+  node.height = max(left, right) + 1
+  node.size = avlSize(node.Children[0]) + avlSize(node.Children[1]) + 1
+  node.bf = right - left
+}
+
+// avlBuild links the sorted nodes into a balanced tree, the middle node at the root, and returns it
+func avlBuild[K comparable, V any](nodes []*AVLNode[K, V], parent *AVLNode[K, V]) *AVLNode[K, V] {
+  if len(nodes) == 0 {
+    return nil
+  }
+  middle := len(nodes) / 2
+  node := nodes[middle]
+  node.Parent = parent
+  node.Children[0] = avlBuild(nodes[:middle], node)
+  node.Children[1] = avlBuild(nodes[middle+1:], node)
+  avlUpdate(node)
+  return node
+}
+
+func avlRightRotate[K comparable, V any](y *AVLNode[K, V], tracer gotree.Tracer) *AVLNode[K, V] {
   x:=y.Children[0];
+  avlTrace(tracer, "rotateRight", y.Key, x.Key)
   T2:=x.Children[1];
   
   x.Children[1]=y;
@@ -29,14 +54,15 @@ func avlRightRotate(y *AVLNode) *AVLNode {
     T2.Parent=y;
   }
   
-  y.bf=avlBalanceFactor(y);
-  x.bf=avlBalanceFactor(x);
+  avlUpdate(y);
+  avlUpdate(x);
   
   return x;
 }
 
-func avlLeftRotate(x *AVLNode) *AVLNode {
+func avlLeftRotate[K comparable, V any](x *AVLNode[K, V], tracer gotree.Tracer) *AVLNode[K, V] {
   y:=x.Children[1]
+  avlTrace(tracer, "rotateLeft", x.Key, y.Key)
   T2:=y.Children[0];
   
   y.Children[0]=x;
@@ -47,73 +73,80 @@ func avlLeftRotate(x *AVLNode) *AVLNode {
     T2.Parent=x;
   }
   
-  y.bf=avlBalanceFactor(y);
-  x.bf=avlBalanceFactor(x);
+  avlUpdate(x);
+  avlUpdate(y);
   
   return y;
 }
 
-func avlLeftRightRotate(root *AVLNode) *AVLNode {
-  root.Children[0] = avlLeftRotate(root.Children[0]);
-  return avlRightRotate(root);
+func avlLeftRightRotate[K comparable, V any](root *AVLNode[K, V], tracer gotree.Tracer) *AVLNode[K, V] {
+  root.Children[0] = avlLeftRotate(root.Children[0], tracer);
+  return avlRightRotate(root, tracer);
 }
 
-func avlRightLeftRotate(root *AVLNode) *AVLNode {
-  root.Children[1] = avlRightRotate(root.Children[1]);
-  return avlLeftRotate(root);
+func avlRightLeftRotate[K comparable, V any](root *AVLNode[K, V], tracer gotree.Tracer) *AVLNode[K, V] {
+  root.Children[1] = avlRightRotate(root.Children[1], tracer);
+  return avlLeftRotate(root, tracer);
 }
 
-func bstTransplant(u, v *AVLNode) (*AVLNode) {
+// avlTrace sends the step name touching the nodes of keys to tracer, if any
+func avlTrace[K comparable](tracer gotree.Tracer, name string, keys ...K) {
+  if tracer != nil {
+    tracer.Trace(gotree.Step("avltree", name, keys...))
+  }
+}
+
+func bstTransplant[K comparable, V any](u, v *AVLNode[K, V]) (*AVLNode[K, V]) {
   v.Parent = u.Parent
   u=v
   return u;
 }
 
-func avlInsert(root *AVLNode, key interface{}, value interface{}, parent *AVLNode, comp goutils.TypeComparator) *AVLNode {
+func avlInsert[K comparable, V any](root *AVLNode[K, V], key K, value V, parent *AVLNode[K, V], comp utils.Comparator[K], tracer gotree.Tracer) *AVLNode[K, V] {
   if root==nil {
     aux:=NewAVLNode(key, value, parent)
     aux.Parent=parent;
     root=aux;
     return root
   }
-  if comp(key, root.Key) == -1 {
-    root.Children[0]=avlInsert(root.Children[0], key, value, root, comp);
-  } else if comp(key, root.Key) == 1 {
-    root.Children[1]=avlInsert(root.Children[1], key, value, root, comp);
+  if comp(key, root.Key) < 0 {
+    root.Children[0]=avlInsert(root.Children[0], key, value, root, comp, tracer);
+  } else if comp(key, root.Key) > 0 {
+    root.Children[1]=avlInsert(root.Children[1], key, value, root, comp, tracer);
   } else if comp(key, root.Key) == 0 {
     root.Value = value
   }
 
-  root.bf=avlBalanceFactor(root)
+  avlUpdate(root)
   balance:=root.bf
   
   // si esta desbalanceado, se evalua
   
   if balance < -1 { // Left Case
-    if comp(key, root.Children[0].Key) == -1 { // Left Case
-      return avlRightRotate(root);
-    } else if comp(key, root.Children[0].Key) == 1 { // Right Case
-      return avlLeftRightRotate(root)
+    if comp(key, root.Children[0].Key) < 0 { // Left Case
+      return avlRightRotate(root, tracer);
+    } else if comp(key, root.Children[0].Key) > 0 { // Right Case
+      return avlLeftRightRotate(root, tracer)
     }
   } else if balance > 1 { // Right Case
-    if comp(key, root.Children[1].Key) == 1 { // Right Case
-      return avlLeftRotate(root);
-    } else if comp(key, root.Children[1].Key) == -1 { // Left Case
-      return avlRightLeftRotate(root)
+    if comp(key, root.Children[1].Key) > 0 { // Right Case
+      return avlLeftRotate(root, tracer);
+    } else if comp(key, root.Children[1].Key) < 0 { // Left Case
+      return avlRightLeftRotate(root, tracer)
     }
   }
   return root;
 }
 
-func avlRemove(root *AVLNode, key interface{}, comp goutils.TypeComparator) *AVLNode {
+func avlRemove[K comparable, V any](root *AVLNode[K, V], key K, comp utils.Comparator[K], tracer gotree.Tracer) *AVLNode[K, V] {
   if root == nil {
     return root;
   }
   
-  if comp(key, root.Key) == -1 {
-    root.Children[0]=avlRemove(root.Children[0], key, comp);
-  } else if comp(key, root.Key) == 1 {
-    root.Children[1]=avlRemove(root.Children[1], key, comp);
+  if comp(key, root.Key) < 0 {
+    root.Children[0]=avlRemove(root.Children[0], key, comp, tracer);
+  } else if comp(key, root.Key) > 0 {
+    root.Children[1]=avlRemove(root.Children[1], key, comp, tracer);
   } else if comp(key, root.Key) == 0 {
     // sin hijos
     if (root.Children[0] == nil) && (root.Children[1] == nil) {
@@ -125,7 +158,8 @@ func avlRemove(root *AVLNode, key interface{}, comp goutils.TypeComparator) *AVL
     }else { // 2 hijos agarra el minimo del arbol derecho
       temp:= avlFindNode(root.Children[1], 0)
       root.Key=temp.Key;
-      root.Children[1]=avlRemove(root.Children[1], temp.Key, comp);
+      root.Value=temp.Value;
+      root.Children[1]=avlRemove(root.Children[1], temp.Key, comp, tracer);
     }
   }
   
@@ -134,36 +168,36 @@ func avlRemove(root *AVLNode, key interface{}, comp goutils.TypeComparator) *AVL
     return root;
   }
   
-  root.bf=avlBalanceFactor(root)
+  avlUpdate(root)
   balance:=root.bf
   
   // si esta desbalanceado, se evalua
 
   if balance < -1 { // Left Case
-    if avlBalanceFactor(root.Children[0]) < 0 { // Left Case
-      return avlRightRotate(root);
-    } else if avlBalanceFactor(root.Children[0]) >= 0 { // Right Case
-      return avlLeftRightRotate(root)
+    if avlBalanceFactor(root.Children[0]) <= 0 { // Left Case
+      return avlRightRotate(root, tracer);
+    } else { // Right Case
+      return avlLeftRightRotate(root, tracer)
     }
   } else if balance > 1 { // Right Case
-    if avlBalanceFactor(root.Children[1]) > 0 { // Right Case
-      return avlLeftRotate(root);
-    } else if avlBalanceFactor(root.Children[1]) <= 0 { // Left Case
-      return avlRightLeftRotate(root)
+    if avlBalanceFactor(root.Children[1]) >= 0 { // Right Case
+      return avlLeftRotate(root, tracer);
+    } else { // Left Case
+      return avlRightLeftRotate(root, tracer)
     }
   }
   
   return root;
 }
 
-func avlSearch(root *AVLNode, key interface{}, comp goutils.TypeComparator) (*AVLNode){
+func avlSearch[K comparable, V any](root *AVLNode[K, V], key K, comp utils.Comparator[K]) (*AVLNode[K, V]){
   if root==nil {
     return nil;
   } else {
     curr_node:=root;
-    if comp(curr_node.Key, key) == 1 {
+    if comp(curr_node.Key, key) > 0 {
       return avlSearch(curr_node.Children[0], key, comp);
-    } else if comp(curr_node.Key, key) == -1{
+    } else if comp(curr_node.Key, key) < 0{
       return avlSearch(curr_node.Children[1], key, comp);
     } else {
       return curr_node;
@@ -171,31 +205,96 @@ func avlSearch(root *AVLNode, key interface{}, comp goutils.TypeComparator) (*AV
   }
 }
 
-func avlHeight(root *AVLNode) int {
-  if root!=nil {
-    var a,b int;
-    curr_node:=root;
-    a=avlHeight(curr_node.Children[0]);
-    b=avlHeight(curr_node.Children[1]);
-    if a>b {
-      return (a+1);
+// avlFloor returns the largest node smaller than key (or equal to it if inclusive)
+func avlFloor[K comparable, V any](root *AVLNode[K, V], key K, inclusive bool, comp utils.Comparator[K]) (*AVLNode[K, V]) {
+  var floor *AVLNode[K, V]
+  for root != nil {
+    compare := comp(key, root.Key)
+    if compare == 0 && inclusive {
+      return root
+    } else if compare <= 0 {
+      root = root.Children[0]
+    } else {
+      floor = root
+      root = root.Children[1]
+    }
+  }
+  return floor
+}
+
+// avlCeiling returns the smallest node larger than key (or equal to it if inclusive)
+func avlCeiling[K comparable, V any](root *AVLNode[K, V], key K, inclusive bool, comp utils.Comparator[K]) (*AVLNode[K, V]) {
+  var ceiling *AVLNode[K, V]
+  for root != nil {
+    compare := comp(key, root.Key)
+    if compare == 0 && inclusive {
+      return root
+    } else if compare < 0 {
+      ceiling = root
+      root = root.Children[0]
     } else {
-      return (b+1);
+      root = root.Children[1]
     }
+  }
+  return ceiling
+}
+
+func avlEntry[K comparable, V any](node *AVLNode[K, V]) (key K, value V, found bool) {
+  if node == nil {
+    return key, value, false
+  }
+  return node.Key, node.Value, true
+}
+
+// avlHeight reads the height cached in root, -1 for an empty subtree
+func avlHeight[K comparable, V any](root *AVLNode[K, V]) int {
+  if root!=nil {
+    return root.height;
   } else {
     return (-1);
   }
 }
 
-func avlSize(root *AVLNode) int{
-  if root==nil {
-    return 0;
-  } else {
-    return (avlSize(root.Children[0]) + avlSize(root.Children[1]) + 1);
+// avlSize reads the size cached in root, 0 for an empty subtree
+func avlSize[K comparable, V any](root *AVLNode[K, V]) int {
+  if root!=nil {
+    return root.size;
   }
+  return 0;
 }
 
-func avlLeafCount(root *AVLNode) int {
+// avlSelect walks down to the k-th smallest node using the cached sizes
+func avlSelect[K comparable, V any](root *AVLNode[K, V], k int) (*AVLNode[K, V]) {
+  for root != nil {
+    left := avlSize(root.Children[0])
+    if k < left {
+      root = root.Children[0]
+    } else if k > left {
+      k -= left + 1
+      root = root.Children[1]
+    } else {
+      return root
+    }
+  }
+  return nil
+}
+
+// avlRank counts the keys smaller than key (or equal to it if inclusive)
+func avlRank[K comparable, V any](root *AVLNode[K, V], key K, inclusive bool, comp utils.Comparator[K]) int {
+  rank := 0
+  for root != nil {
+    compare := comp(key, root.Key)
+    if compare < 0 || (compare == 0 && !inclusive) {
+      root = root.Children[0]
+    } else {
+      rank += avlSize(root.Children[0]) + 1
+      root = root.Children[1]
+    }
+  }
+  return rank
+}
+
+func avlLeafCount[K comparable, V any](root *AVLNode[K, V]) int {
   if root == nil {
     return 0;
   }
@@ -206,7 +305,7 @@ func avlLeafCount(root *AVLNode) int {
   }
 }
 
-func avlFindNode(root *AVLNode, child int) *AVLNode {
+func avlFindNode[K comparable, V any](root *AVLNode[K, V], child int) *AVLNode[K, V] {
   if root == nil {
     return nil;
   }
@@ -217,81 +316,105 @@ func avlFindNode(root *AVLNode, child int) *AVLNode {
   return curr_node;
 }
 
-func avlSumNodes(root *AVLNode, op goutils.TypeOperator) interface{}{
-  var sum interface{} = 0
+func avlSumNodes[K comparable, V any](root *AVLNode[K, V], op utils.Operator[K]) K {
+  var sum K
   if root!=nil {
     sumLeft  := avlSumNodes(root.Children[0], op)
     sumRight := avlSumNodes(root.Children[1], op)
-    sum=op(op(sumLeft, root.Key ,"+"), sumRight, "+");
+    sum=op(op(sumLeft, root.Key), sumRight);
   }
   return sum
 }
 
-func avlHeightOfNode(root *AVLNode, key interface{}, comp goutils.TypeComparator) int {
+func avlHeightOfNode[K comparable, V any](root *AVLNode[K, V], key K, comp utils.Comparator[K]) (int, bool) {
   height:=0;
   curr_node:=root;
   for curr_node!=nil {
     if comp(key, curr_node.Key) == 0 {
-      return height;
+      return height, true;
     } else {
       height++;
-      if comp(key, curr_node.Key) == -1 {
+      if comp(key, curr_node.Key) < 0 {
         curr_node = curr_node.Children[0];
-      } else if comp(key, curr_node.Key) == 1 {
+      } else if comp(key, curr_node.Key) > 0 {
         curr_node = curr_node.Children[1];
       }
     }
   }
-  return -1;
+  return -1, false;
 }
 
-func avlPrintPreorder(root *AVLNode) {
-  if root != nil {
-    fmt.Printf("%v ", root.Key);
-    avlPrintPreorder(root.Children[0]);
-    avlPrintPreorder(root.Children[1]);
+// avlWalk visits root depth-first in order, false if the visitor stopped the walk
+func avlWalk[K comparable, V any](root *AVLNode[K, V], order gotree.TraversalOrder, visitor func(K, V) bool) bool {
+  if root == nil {
+    return true;
+  }
+  if order == gotree.PreOrder && !visitor(root.Key, root.Value) {
+    return false;
   }
+  if !avlWalk(root.Children[0], order, visitor) {
+    return false;
+  }
+  if order == gotree.InOrder && !visitor(root.Key, root.Value) {
+    return false;
+  }
+  if !avlWalk(root.Children[1], order, visitor) {
+    return false;
+  }
+  return order != gotree.PostOrder || visitor(root.Key, root.Value);
 }
 
-func avlPrintInorder(root *AVLNode) {
-  if root != nil {
-    avlPrintInorder(root.Children[0]);
-    fmt.Printf("%v ", root.Key);
-    avlPrintInorder(root.Children[1]);
+// avlWalkLevels visits root breadth-first, until the visitor returns false
+func avlWalkLevels[K comparable, V any](root *AVLNode[K, V], visitor func(K, V) bool) {
+  if root == nil {
+    return;
+  }
+  queue := []*AVLNode[K, V]{root}
+  for len(queue) > 0 {
+    node := queue[0]
+    queue = queue[1:]
+    if !visitor(node.Key, node.Value) {
+      return;
+    }
+    for _, child := range node.Children {
+      if child != nil {
+        queue = append(queue, child)
+      }
+    }
   }
 }
 
-func avlPrintPostorder(root *AVLNode) {
-  if root != nil {
-    avlPrintPostorder(root.Children[0]);
-    avlPrintPostorder(root.Children[1]);
-    fmt.Printf("%v ", root.Key);
-  }
+func avlPrintOrder[K comparable, V any](w io.Writer, t *AVLTree[K, V], order gotree.TraversalOrder) {
+  t.Walk(order, func(key K, _ V) bool {
+    fmt.Fprintf(w, "%v ", key);
+    return true;
+  })
+  fmt.Fprintln(w);
 }
 
-func getParentNode(root *AVLNode, key interface{}, comp goutils.TypeComparator) *AVLNode{
+func getParentNode[K comparable, V any](root *AVLNode[K, V], key K, comp utils.Comparator[K]) (*AVLNode[K, V], bool) {
   curr_node:=avlSearch(root, key, comp);
-  if curr_node!=nil {
-    return curr_node.Parent;
-  } else{
-    return nil;
+  if curr_node==nil {
+    return nil, false;
   }
+  return curr_node.Parent, true;
 }
 
-func getBrotherNode(root *AVLNode, key interface{}, comp goutils.TypeComparator) *AVLNode{
+func getBrotherNode[K comparable, V any](root *AVLNode[K, V], key K, comp utils.Comparator[K]) (*AVLNode[K, V], bool) {
   curr_node:=avlSearch(root, key, comp);
-  if curr_node!=nil && curr_node.Parent!=nil && curr_node.Parent.Children[1]==curr_node {
-    return curr_node.Parent.Children[0]
-  } else if curr_node!=nil && curr_node.Parent!=nil && curr_node.Parent.Children[0]==curr_node {
-    return curr_node.Parent.Children[1]
-  } else if curr_node!=nil && curr_node.Parent==nil {
-    return nil;
-  } else{
-    return nil;
+  if curr_node==nil {
+    return nil, false;
+  }
+  if curr_node.Parent==nil {
+    return nil, true;
+  }
+  if curr_node.Parent.Children[1]==curr_node {
+    return curr_node.Parent.Children[0], true;
   }
+  return curr_node.Parent.Children[1], true;
 }
 
-func avlFindNeighbourNode(root *AVLNode,child int) *AVLNode {
+func avlFindNeighbourNode[K comparable, V any](root *AVLNode[K, V],child int) *AVLNode[K, V] {
   if root==nil {
     return nil
   }
@@ -306,17 +429,18 @@ func avlFindNeighbourNode(root *AVLNode,child int) *AVLNode {
   return curr_node;
 }
 
-func avlMirror(root *AVLNode) {
+func avlMirror[K comparable, V any](root *AVLNode[K, V]) {
   if root !=nil {
     avlMirror(root.Children[0]);
     avlMirror(root.Children[1]);
     temp := root.Children[0];
     root.Children[0] = root.Children[1];
     root.Children[1]=temp;
+    root.bf = -root.bf;
   }
 }
 
-func avlIsSameAs(a,b *AVLNode) bool {
+func avlIsSameAs[K comparable, V any](a,b *AVLNode[K, V]) bool {
   if a==nil && b==nil {
     return true;
   } else if a!=nil && b!=nil && a.Key == b.Key {
@@ -326,7 +450,7 @@ func avlIsSameAs(a,b *AVLNode) bool {
   }
 }
 
-func avlTreePrint(node *AVLNode, prefix string, isTail bool, str *string) {
+func avlTreePrint[K comparable, V any](node *AVLNode[K, V], prefix string, isTail bool, str *string) {
   if node.Children[1] != nil {
     newPrefix := prefix
     if node.Parent == nil {
@@ -366,22 +490,22 @@ func avlTreePrint(node *AVLNode, prefix string, isTail bool, str *string) {
   }
 }
 
-func avlTreePrintLevels(root *AVLNode) {
+func avlTreePrintLevels[K comparable, V any](w io.Writer, root *AVLNode[K, V]) {
   height:=avlHeight(root)+1;
   for i := 0;i < height;i++ {
-    fmt.Printf("\nNIVEL %v  :", i);
-    avlPrintNodeAtLevel(root, i, 0);
-    fmt.Println();
+    fmt.Fprintf(w, "\nNIVEL %v  :", i);
+    avlPrintNodeAtLevel(w, root, i, 0);
+    fmt.Fprintln(w);
   }
 }
 
-func avlPrintNodeAtLevel(root *AVLNode, height, level int) {
+func avlPrintNodeAtLevel[K comparable, V any](w io.Writer, root *AVLNode[K, V], height, level int) {
   if root!=nil {
     if(height==level) {
-      fmt.Printf("\t%v", root.Key)
+      fmt.Fprintf(w, "\t%v", root.Key)
     } else {
-      avlPrintNodeAtLevel(root.Children[0], height, level+1);
-      avlPrintNodeAtLevel(root.Children[1], height, level+1);
+      avlPrintNodeAtLevel(w, root.Children[0], height, level+1);
+      avlPrintNodeAtLevel(w, root.Children[1], height, level+1);
     }
   }
 }
@@ -2,7 +2,7 @@
 // Use of this source code is governed by a BSD-style
 // license that can be found in the LICENSE file.
 
-// Package binaryheap implements a binary heap backed by array list.
+// Package binaryheap implements a binary heap backed by a slice.
 //
 // Comparator defines this heap as either min or max heap.
 //
@@ -12,100 +12,152 @@
 package binaryheap
 
 import (
+	"cmp"
 	"fmt"
-	"github.com/emirpasic/gods/lists/arraylist"
-	"github.com/emirpasic/gods/trees"
-	"github.com/emirpasic/gods/utils"
+	"github.com/fmorenovr/gods/trees"
+	"github.com/fmorenovr/gods/utils"
 	"strings"
 )
 
 func assertTreeImplementation() {
-	var _ trees.Tree = (*Heap)(nil)
+	var _ gotree.GoTree[int] = (*Heap[int])(nil)
 }
 
-// Heap holds elements in an array-list
-type Heap struct {
-	list       *arraylist.List
-	Comparator utils.Comparator
+// Heap holds elements in a slice
+type Heap[T any] struct {
+	list       []T
+	Comparator utils.Comparator[T]
+	tracer     gotree.Tracer
+	codec      utils.Codec[T] // nil for the default codec
 }
 
-// NewWith instantiates a new empty heap tree with the custom comparator.
-func NewWith(comparator utils.Comparator) *Heap {
-	return &Heap{list: arraylist.New(), Comparator: comparator}
-}
-
-// NewWithIntComparator instantiates a new empty heap with the IntComparator, i.e. elements are of type int.
-func NewWithIntComparator() *Heap {
-	return &Heap{list: arraylist.New(), Comparator: utils.IntComparator}
+// New instantiates a new empty min-heap ordered by the natural order of T.
+func New[T cmp.Ordered]() *Heap[T] {
+	return &Heap[T]{Comparator: utils.OrderedComparator[T]()}
 }
 
-// NewWithStringComparator instantiates a new empty heap with the StringComparator, i.e. elements are of type string.
-func NewWithStringComparator() *Heap {
-	return &Heap{list: arraylist.New(), Comparator: utils.StringComparator}
+// NewWith instantiates a new empty heap tree with the custom comparator.
+func NewWith[T any](comparator utils.Comparator[T]) *Heap[T] {
+	return &Heap[T]{Comparator: comparator}
 }
 
 // Push adds a value onto the heap and bubbles it up accordingly.
-func (heap *Heap) Push(values ...interface{}) {
+func (heap *Heap[T]) Push(values ...T) {
+	if heap.tracer != nil {
+		heap.tracer.Trace(gotree.Operation("binaryheap", "Push", values...))
+	}
 	if len(values) == 1 {
-		heap.list.Add(values[0])
+		heap.list = append(heap.list, values[0])
 		heap.bubbleUp()
 	} else {
-		// Reference: https://en.wikipedia.org/wiki/Binary_heap#Building_a_heap
-		for _, value := range values {
-			heap.list.Add(value)
+		heap.list = append(heap.list, values...)
+		heap.heapify()
+	}
+	if utils.Debug {
+		heap.debugCheck("Push", values...)
+	}
+}
+
+// heapify restores the heap property of the whole list in O(n).
+func (heap *Heap[T]) heapify() {
+	// Reference: https://en.wikipedia.org/wiki/Binary_heap#Building_a_heap
+	size := len(heap.list)/2 + 1
+	for i := size; i >= 0; i-- {
+		heap.bubbleDownIndex(i)
+	}
+}
+
+// SetTracer sends every Push and Pop to tracer, followed by the swaps of bubbleUp and
+// bubbleDownIndex they make. A nil tracer stops tracing.
+func (heap *Heap[T]) SetTracer(tracer gotree.Tracer) {
+	heap.tracer = tracer
+}
+
+// Replay applies the Push and Pop operations of a trace to the heap, in order.
+func (heap *Heap[T]) Replay(events []gotree.Event) error {
+	for _, event := range events {
+		if event.Kind != gotree.OperationEvent {
+			continue
+		}
+		values, _, err := gotree.DecodeEvent[T, any](event)
+		if err != nil {
+			return err
 		}
-		size := heap.list.Size()/2 + 1
-		for i := size; i >= 0; i-- {
-			heap.bubbleDownIndex(i)
+		switch event.Name {
+		case "Push":
+			heap.Push(values...)
+		case "Pop":
+			heap.Pop()
+		default:
+			return fmt.Errorf("binaryheap: cannot replay operation %v", event)
 		}
 	}
+	return nil
 }
 
-// Pop removes top element on heap and returns it, or nil if heap is empty.
+// Pop removes top element on heap and returns it, or the zero value if heap is empty.
 // Second return parameter is true, unless the heap was empty and there was nothing to pop.
-func (heap *Heap) Pop() (value interface{}, ok bool) {
-	value, ok = heap.list.Get(0)
-	if !ok {
-		return
+func (heap *Heap[T]) Pop() (value T, ok bool) {
+	if heap.tracer != nil {
+		heap.tracer.Trace(gotree.Operation[T]("binaryheap", "Pop"))
+	}
+	if len(heap.list) == 0 {
+		return value, false
 	}
-	lastIndex := heap.list.Size() - 1
-	heap.list.Swap(0, lastIndex)
-	heap.list.Remove(lastIndex)
+	value = heap.list[0]
+	lastIndex := len(heap.list) - 1
+	heap.swap(0, lastIndex)
+	var zero T
+	heap.list[lastIndex] = zero
+	heap.list = heap.list[:lastIndex]
 	heap.bubbleDown()
-	return
+	if utils.Debug {
+		heap.debugCheck("Pop", value)
+	}
+	return value, true
 }
 
-// Peek returns top element on the heap without removing it, or nil if heap is empty.
+// Peek returns top element on the heap without removing it, or the zero value if heap is empty.
 // Second return parameter is true, unless the heap was empty and there was nothing to peek.
-func (heap *Heap) Peek() (value interface{}, ok bool) {
-	return heap.list.Get(0)
+func (heap *Heap[T]) Peek() (value T, ok bool) {
+	if len(heap.list) == 0 {
+		return value, false
+	}
+	return heap.list[0], true
 }
 
 // Empty returns true if heap does not contain any elements.
-func (heap *Heap) Empty() bool {
-	return heap.list.Empty()
+func (heap *Heap[T]) Empty() bool {
+	return len(heap.list) == 0
+}
+
+// IsEmpty returns true if heap does not contain any elements, same as Empty
+func (heap *Heap[T]) IsEmpty() bool {
+	return heap.Empty()
 }
 
 // Size returns number of elements within the heap.
-func (heap *Heap) Size() int {
-	return heap.list.Size()
+func (heap *Heap[T]) Size() int {
+	return len(heap.list)
 }
 
 // Clear removes all elements from the heap.
-func (heap *Heap) Clear() {
-	heap.list.Clear()
+func (heap *Heap[T]) Clear() {
+	heap.list = nil
 }
 
 // Values returns all elements in the heap.
-func (heap *Heap) Values() []interface{} {
-	return heap.list.Values()
+func (heap *Heap[T]) Values() []T {
+	values := make([]T, len(heap.list))
+	copy(values, heap.list)
+	return values
 }
 
 // String returns a string representation of container
-func (heap *Heap) String() string {
+func (heap *Heap[T]) String() string {
 	str := "BinaryHeap\n"
 	values := []string{}
-	for _, value := range heap.list.Values() {
+	for _, value := range heap.list {
 		values = append(values, fmt.Sprintf("%v", value))
 	}
 	str += strings.Join(values, ", ")
@@ -114,26 +166,23 @@ func (heap *Heap) String() string {
 
 // Performs the "bubble down" operation. This is to place the element that is at the root
 // of the heap in its correct place so that the heap maintains the min/max-heap order property.
-func (heap *Heap) bubbleDown() {
+func (heap *Heap[T]) bubbleDown() {
 	heap.bubbleDownIndex(0)
 }
 
 // Performs the "bubble down" operation. This is to place the element that is at the index
 // of the heap in its correct place so that the heap maintains the min/max-heap order property.
-func (heap *Heap) bubbleDownIndex(index int) {
-	size := heap.list.Size()
+func (heap *Heap[T]) bubbleDownIndex(index int) {
+	size := len(heap.list)
 	for leftIndex := index<<1 + 1; leftIndex < size; leftIndex = index<<1 + 1 {
 		rightIndex := index<<1 + 2
 		smallerIndex := leftIndex
-		leftValue, _ := heap.list.Get(leftIndex)
-		rightValue, _ := heap.list.Get(rightIndex)
-		if rightIndex < size && heap.Comparator(leftValue, rightValue) > 0 {
+		if rightIndex < size && heap.Comparator(heap.list[leftIndex], heap.list[rightIndex]) > 0 {
 			smallerIndex = rightIndex
 		}
-		indexValue, _ := heap.list.Get(index)
-		smallerValue, _ := heap.list.Get(smallerIndex)
-		if heap.Comparator(indexValue, smallerValue) > 0 {
-			heap.list.Swap(index, smallerIndex)
+		if heap.Comparator(heap.list[index], heap.list[smallerIndex]) > 0 {
+			heap.trace("bubbleDown", index, smallerIndex)
+			heap.swap(index, smallerIndex)
 		} else {
 			break
 		}
@@ -144,20 +193,31 @@ func (heap *Heap) bubbleDownIndex(index int) {
 // Performs the "bubble up" operation. This is to place a newly inserted
 // element (i.e. last element in the list) in its correct place so that
 // the heap maintains the min/max-heap order property.
-func (heap *Heap) bubbleUp() {
-	index := heap.list.Size() - 1
+func (heap *Heap[T]) bubbleUp() {
+	index := len(heap.list) - 1
 	for parentIndex := (index - 1) >> 1; index > 0; parentIndex = (index - 1) >> 1 {
-		indexValue, _ := heap.list.Get(index)
-		parentValue, _ := heap.list.Get(parentIndex)
-		if heap.Comparator(parentValue, indexValue) <= 0 {
+		if heap.Comparator(heap.list[parentIndex], heap.list[index]) <= 0 {
 			break
 		}
-		heap.list.Swap(index, parentIndex)
+		heap.trace("bubbleUp", index, parentIndex)
+		heap.swap(index, parentIndex)
 		index = parentIndex
 	}
 }
 
+// Sends the step name swapping the elements at the two indexes to the tracer, if any
+func (heap *Heap[T]) trace(name string, i, j int) {
+	if heap.tracer != nil {
+		heap.tracer.Trace(gotree.Step("binaryheap", name, heap.list[i], heap.list[j]))
+	}
+}
+
+// Swaps the elements at the two indexes of the list
+func (heap *Heap[T]) swap(i, j int) {
+	heap.list[i], heap.list[j] = heap.list[j], heap.list[i]
+}
+
 // Check that the index is within bounds of the list
-func (heap *Heap) withinRange(index int) bool {
-	return index >= 0 && index < heap.list.Size()
+func (heap *Heap[T]) withinRange(index int) bool {
+	return index >= 0 && index < len(heap.list)
 }
@@ -5,12 +5,19 @@
 package binaryheap
 
 import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/fmorenovr/gods/trees"
 	"math/rand"
+	"slices"
+	"strings"
 	"testing"
 )
 
 func TestBinaryHeapPush(t *testing.T) {
-	heap := NewWithIntComparator()
+	heap := New[int]()
 
 	if actualValue := heap.Empty(); actualValue != true {
 		t.Errorf("Got %v expected %v", actualValue, true)
@@ -20,7 +27,7 @@ func TestBinaryHeapPush(t *testing.T) {
 	heap.Push(2) // [2,3]
 	heap.Push(1) // [1,3,2](2 swapped with 1, hence last)
 
-	if actualValue := heap.Values(); actualValue[0].(int) != 1 || actualValue[1].(int) != 3 || actualValue[2].(int) != 2 {
+	if actualValue := heap.Values(); actualValue[0] != 1 || actualValue[1] != 3 || actualValue[2] != 2 {
 		t.Errorf("Got %v expected %v", actualValue, "[1,2,3]")
 	}
 	if actualValue := heap.Empty(); actualValue != false {
@@ -35,11 +42,11 @@ func TestBinaryHeapPush(t *testing.T) {
 }
 
 func TestBinaryHeapPushBulk(t *testing.T) {
-	heap := NewWithIntComparator()
+	heap := New[int]()
 
 	heap.Push(15, 20, 3, 1, 2)
 
-	if actualValue := heap.Values(); actualValue[0].(int) != 1 || actualValue[1].(int) != 2 || actualValue[2].(int) != 3 {
+	if actualValue := heap.Values(); actualValue[0] != 1 || actualValue[1] != 2 || actualValue[2] != 3 {
 		t.Errorf("Got %v expected %v", actualValue, "[1,2,3]")
 	}
 	if actualValue, ok := heap.Pop(); actualValue != 1 || !ok {
@@ -48,7 +55,7 @@ func TestBinaryHeapPushBulk(t *testing.T) {
 }
 
 func TestBinaryHeapPop(t *testing.T) {
-	heap := NewWithIntComparator()
+	heap := New[int]()
 
 	if actualValue := heap.Empty(); actualValue != true {
 		t.Errorf("Got %v expected %v", actualValue, true)
@@ -68,8 +75,8 @@ func TestBinaryHeapPop(t *testing.T) {
 	if actualValue, ok := heap.Pop(); actualValue != 3 || !ok {
 		t.Errorf("Got %v expected %v", actualValue, 3)
 	}
-	if actualValue, ok := heap.Pop(); actualValue != nil || ok {
-		t.Errorf("Got %v expected %v", actualValue, nil)
+	if actualValue, ok := heap.Pop(); actualValue != 0 || ok {
+		t.Errorf("Got %v expected %v", actualValue, 0)
 	}
 	if actualValue := heap.Empty(); actualValue != true {
 		t.Errorf("Got %v expected %v", actualValue, true)
@@ -80,7 +87,7 @@ func TestBinaryHeapPop(t *testing.T) {
 }
 
 func TestBinaryHeapRandom(t *testing.T) {
-	heap := NewWithIntComparator()
+	heap := New[int]()
 
 	rand.Seed(3)
 	for i := 0; i < 10000; i++ {
@@ -91,15 +98,191 @@ func TestBinaryHeapRandom(t *testing.T) {
 	prev, _ := heap.Pop()
 	for !heap.Empty() {
 		curr, _ := heap.Pop()
-		if prev.(int) > curr.(int) {
+		if prev > curr {
 			t.Errorf("Heap property invalidated. prev: %v current: %v", prev, curr)
 		}
 		prev = curr
 	}
 }
 
+func TestBinaryHeapDrain(t *testing.T) {
+	heap := New[int]()
+	heap.Push(15, 20, 3, 1, 2)
+
+	var values []int
+	for value := range heap.Drain() {
+		if value == 15 {
+			break
+		}
+		values = append(values, value)
+	}
+	if actualValue, expectedValue := values, []int{1, 2, 3}; !slices.Equal(actualValue, expectedValue) {
+		t.Errorf("Got %v expected %v", actualValue, expectedValue)
+	}
+	if actualValue, expectedValue := slices.Collect(heap.Drain()), []int{20}; !slices.Equal(actualValue, expectedValue) {
+		t.Errorf("Got %v expected %v", actualValue, expectedValue)
+	}
+	if actualValue := heap.Empty(); actualValue != true {
+		t.Errorf("Got %v expected %v", actualValue, true)
+	}
+}
+
+func TestBinaryHeapValidate(t *testing.T) {
+	heap := New[int]()
+	if err := heap.Validate(); err != nil {
+		t.Errorf("Got %v expected %v", err, nil)
+	}
+	heap.Push(15, 20, 3, 1, 2)
+	if err := heap.Validate(); err != nil {
+		t.Errorf("Got %v expected %v", err, nil)
+	}
+	heap.list[0], heap.list[4] = heap.list[4], heap.list[0]
+	if err := heap.Validate(); err == nil || !strings.Contains(err.Error(), "parent 15") {
+		t.Errorf("Got %v expected an error naming 15", err)
+	}
+}
+
+func TestBinaryHeapExport(t *testing.T) {
+	heap := New[int]()
+	heap.Push(3, 1, 2, 5)
+	var dot strings.Builder
+	if err := heap.WriteDOT(&dot, gotree.ExportOptions[int]{Highlight: []int{1}}); err != nil {
+		t.Errorf("Got %v expected %v", err, nil)
+	}
+	for _, expectedValue := range []string{`n0 [label="1 [0]", color="#ff8c00", penwidth=3];`, `n3 [label="5 [3]"];`, "n0 -> n2;", "n1 -> n3;"} {
+		if actualValue := dot.String(); !strings.Contains(actualValue, expectedValue) {
+			t.Errorf("Got %v expected it to contain %v", actualValue, expectedValue)
+		}
+	}
+	var mermaid strings.Builder
+	if err := heap.WriteMermaid(&mermaid, gotree.ExportOptions[int]{Name: "heap"}); err != nil {
+		t.Errorf("Got %v expected %v", err, nil)
+	}
+	for _, expectedValue := range []string{"title: heap", `n2(("2 [2]"))`, "n1 --> n3"} {
+		if actualValue := mermaid.String(); !strings.Contains(actualValue, expectedValue) {
+			t.Errorf("Got %v expected it to contain %v", actualValue, expectedValue)
+		}
+	}
+}
+
+func TestBinaryHeapTracer(t *testing.T) {
+	heap := New[int]()
+	recorder := &gotree.Recorder{}
+	heap.SetTracer(recorder)
+	heap.Push(3)
+	heap.Push(2)
+	heap.Push(1)
+	heap.Push(4)
+	heap.Pop()
+	var actualValue []string
+	for _, event := range recorder.Events {
+		actualValue = append(actualValue, fmt.Sprint(event.Name, event.Keys))
+	}
+	expectedValue := []string{"Push[3]", "Push[2]", "bubbleUp[2 3]", "Push[1]", "bubbleUp[1 2]", "Push[4]", "Pop[]", "bubbleDown[4 2]"}
+	if !slices.Equal(actualValue, expectedValue) {
+		t.Errorf("Got %v expected %v", actualValue, expectedValue)
+	}
+	replayed := New[int]()
+	if err := replayed.Replay(recorder.Events); err != nil {
+		t.Errorf("Got %v expected %v", err, nil)
+	}
+	if actualValue, expectedValue := replayed.Values(), heap.Values(); !slices.Equal(actualValue, expectedValue) {
+		t.Errorf("Got %v expected %v", actualValue, expectedValue)
+	}
+}
+
+func TestBinaryHeapMarshalJSON(t *testing.T) {
+	heap := New[int]()
+	heap.Push(3, 1, 2)
+	data, err := json.Marshal(heap)
+	if actualValue, expectedValue := string(data), "[1,3,2]"; err != nil || actualValue != expectedValue {
+		t.Errorf("Got %v, %v expected %v", actualValue, err, expectedValue)
+	}
+	decoded := new(Heap[int])
+	if err := json.Unmarshal([]byte("[5,4,3,2,1]"), decoded); err != nil {
+		t.Errorf("Got %v expected %v", err, nil)
+	}
+	if err := decoded.Validate(); err != nil {
+		t.Errorf("Got %v expected %v", err, nil)
+	}
+	if actualValue, expectedValue := slices.Collect(decoded.Drain()), []int{1, 2, 3, 4, 5}; !slices.Equal(actualValue, expectedValue) {
+		t.Errorf("Got %v expected %v", actualValue, expectedValue)
+	}
+	if err := json.Unmarshal([]byte(`[1,"x"]`), heap); err == nil || !strings.Contains(err.Error(), "entry 1") || !errors.Is(err, gotree.ErrCorruptData) || heap.Size() != 3 {
+		t.Errorf("Got %v expected a corrupt data error naming entry 1 and the heap untouched", err)
+	}
+	var zero Heap[int]
+	if err := zero.UnmarshalJSON([]byte(`[1,"x"]`)); err == nil || zero.Comparator != nil {
+		t.Errorf("Got %v expected an error and the heap untouched", err)
+	}
+}
+
+func TestBinaryHeapMarshalBinary(t *testing.T) {
+	heap := New[string]()
+	heap.Push("c", "a", "d", "b")
+	data, err := heap.MarshalBinary()
+	if err != nil {
+		t.Errorf("Got %v expected %v", err, nil)
+	}
+	decoded := new(Heap[string])
+	if err := decoded.UnmarshalBinary(data); err != nil {
+		t.Errorf("Got %v expected %v", err, nil)
+	}
+	if actualValue, expectedValue := decoded.Values(), heap.Values(); !slices.Equal(actualValue, expectedValue) {
+		t.Errorf("Got %v expected %v", actualValue, expectedValue)
+	}
+	maxHeap := NewWith(func(a, b string) int { return strings.Compare(b, a) })
+	if err := maxHeap.UnmarshalBinary(data); err != nil {
+		t.Errorf("Got %v expected %v", err, nil)
+	}
+	if actualValue, expectedValue := slices.Collect(maxHeap.Drain()), []string{"d", "c", "b", "a"}; !slices.Equal(actualValue, expectedValue) {
+		t.Errorf("Got %v expected %v", actualValue, expectedValue)
+	}
+	data[6] ^= 0xff
+	if err := decoded.UnmarshalBinary(data); err == nil || !strings.Contains(err.Error(), "checksum") || decoded.Size() != 4 {
+		t.Errorf("Got %v expected a checksum error and the heap untouched", err)
+	}
+	var zero Heap[string]
+	if err := zero.UnmarshalBinary(data); err == nil || zero.Comparator != nil {
+		t.Errorf("Got %v expected an error and the heap untouched", err)
+	}
+}
+
+func TestBinaryHeapEncodeDecode(t *testing.T) {
+	heap := New[int]()
+	heap.Push(5, 3, 8, 1)
+	var buffer bytes.Buffer
+	if err := heap.Encode(&buffer); err != nil {
+		t.Errorf("Got %v expected %v", err, nil)
+	}
+	if data, _ := json.Marshal(heap); buffer.String() != string(data) {
+		t.Errorf("Got %v expected %s", buffer.String(), data)
+	}
+	decoded := new(Heap[int])
+	if err := decoded.Decode(strings.NewReader("[9,7,5,3]")); err != nil {
+		t.Errorf("Got %v expected %v", err, nil)
+	}
+	if err := decoded.Validate(); err != nil {
+		t.Errorf("Got %v expected %v", err, nil)
+	}
+	if err := decoded.Decode(&buffer); err != nil {
+		t.Errorf("Got %v expected %v", err, nil)
+	}
+	if actualValue, expectedValue := slices.Collect(decoded.Drain()), []int{1, 3, 5, 8}; !slices.Equal(actualValue, expectedValue) {
+		t.Errorf("Got %v expected %v", actualValue, expectedValue)
+	}
+	decoded.Push(2)
+	if err := decoded.Decode(strings.NewReader(`[1,"x"]`)); err == nil || decoded.Size() != 1 {
+		t.Errorf("Got %v expected an error and the heap untouched", err)
+	}
+	var zero Heap[int]
+	if err := zero.Decode(strings.NewReader(`[1,"x"]`)); err == nil || zero.Comparator != nil {
+		t.Errorf("Got %v expected an error and the heap untouched", err)
+	}
+}
+
 func TestBinaryHeapIteratorOnEmpty(t *testing.T) {
-	heap := NewWithIntComparator()
+	heap := New[int]()
 	it := heap.Iterator()
 	for it.Next() {
 		t.Errorf("Shouldn't iterate on empty heap")
@@ -107,7 +290,7 @@ func TestBinaryHeapIteratorOnEmpty(t *testing.T) {
 }
 
 func TestBinaryHeapIteratorNext(t *testing.T) {
-	heap := NewWithIntComparator()
+	heap := New[int]()
 	heap.Push(3) // [3]
 	heap.Push(2) // [2,3]
 	heap.Push(1) // [1,3,2](2 swapped with 1, hence last)
@@ -144,7 +327,7 @@ func TestBinaryHeapIteratorNext(t *testing.T) {
 }
 
 func TestBinaryHeapIteratorPrev(t *testing.T) {
-	heap := NewWithIntComparator()
+	heap := New[int]()
 	heap.Push(3) // [3]
 	heap.Push(2) // [2,3]
 	heap.Push(1) // [1,3,2](2 swapped with 1, hence last)
@@ -183,7 +366,7 @@ func TestBinaryHeapIteratorPrev(t *testing.T) {
 }
 
 func TestBinaryHeapIteratorBegin(t *testing.T) {
-	heap := NewWithIntComparator()
+	heap := New[int]()
 	it := heap.Iterator()
 	it.Begin()
 	heap.Push(2)
@@ -199,7 +382,7 @@ func TestBinaryHeapIteratorBegin(t *testing.T) {
 }
 
 func TestListIteratorEnd(t *testing.T) {
-	heap := NewWithIntComparator()
+	heap := New[int]()
 	it := heap.Iterator()
 
 	if index := it.Index(); index != -1 {
@@ -226,7 +409,7 @@ func TestListIteratorEnd(t *testing.T) {
 }
 
 func TestStackIteratorFirst(t *testing.T) {
-	heap := NewWithIntComparator()
+	heap := New[int]()
 	it := heap.Iterator()
 	if actualValue, expectedValue := it.First(), false; actualValue != expectedValue {
 		t.Errorf("Got %v expected %v", actualValue, expectedValue)
@@ -243,7 +426,7 @@ func TestStackIteratorFirst(t *testing.T) {
 }
 
 func TestBinaryHeapIteratorLast(t *testing.T) {
-	tree := NewWithIntComparator()
+	tree := New[int]()
 	it := tree.Iterator()
 	if actualValue, expectedValue := it.Last(), false; actualValue != expectedValue {
 		t.Errorf("Got %v expected %v", actualValue, expectedValue)
@@ -260,7 +443,7 @@ func TestBinaryHeapIteratorLast(t *testing.T) {
 }
 
 func TestBinaryHeapSerialization(t *testing.T) {
-	heap := NewWithStringComparator()
+	heap := New[string]()
 
 	heap.Push("c") // ["c"]
 	heap.Push("b") // ["b","c"]
@@ -268,7 +451,7 @@ func TestBinaryHeapSerialization(t *testing.T) {
 
 	var err error
 	assert := func() {
-		if actualValue := heap.Values(); actualValue[0].(string) != "a" || actualValue[1].(string) != "c" || actualValue[2].(string) != "b" {
+		if actualValue := heap.Values(); actualValue[0] != "a" || actualValue[1] != "c" || actualValue[2] != "b" {
 			t.Errorf("Got %v expected %v", actualValue, "[1,3,2]")
 		}
 		if actualValue := heap.Size(); actualValue != 3 {
@@ -291,7 +474,7 @@ func TestBinaryHeapSerialization(t *testing.T) {
 	assert()
 }
 
-func benchmarkPush(b *testing.B, heap *Heap, size int) {
+func benchmarkPush(b *testing.B, heap *Heap[int], size int) {
 	for i := 0; i < b.N; i++ {
 		for n := 0; n < size; n++ {
 			heap.Push(n)
@@ -299,7 +482,7 @@ func benchmarkPush(b *testing.B, heap *Heap, size int) {
 	}
 }
 
-func benchmarkPop(b *testing.B, heap *Heap, size int) {
+func benchmarkPop(b *testing.B, heap *Heap[int], size int) {
 	for i := 0; i < b.N; i++ {
 		for n := 0; n < size; n++ {
 			heap.Pop()
@@ -310,7 +493,7 @@ func benchmarkPop(b *testing.B, heap *Heap, size int) {
 func BenchmarkBinaryHeapPop100(b *testing.B) {
 	b.StopTimer()
 	size := 100
-	heap := NewWithIntComparator()
+	heap := New[int]()
 	for n := 0; n < size; n++ {
 		heap.Push(n)
 	}
@@ -321,7 +504,7 @@ func BenchmarkBinaryHeapPop100(b *testing.B) {
 func BenchmarkBinaryHeapPop1000(b *testing.B) {
 	b.StopTimer()
 	size := 1000
-	heap := NewWithIntComparator()
+	heap := New[int]()
 	for n := 0; n < size; n++ {
 		heap.Push(n)
 	}
@@ -332,7 +515,7 @@ func BenchmarkBinaryHeapPop1000(b *testing.B) {
 func BenchmarkBinaryHeapPop10000(b *testing.B) {
 	b.StopTimer()
 	size := 10000
-	heap := NewWithIntComparator()
+	heap := New[int]()
 	for n := 0; n < size; n++ {
 		heap.Push(n)
 	}
@@ -343,7 +526,7 @@ func BenchmarkBinaryHeapPop10000(b *testing.B) {
 func BenchmarkBinaryHeapPop100000(b *testing.B) {
 	b.StopTimer()
 	size := 100000
-	heap := NewWithIntComparator()
+	heap := New[int]()
 	for n := 0; n < size; n++ {
 		heap.Push(n)
 	}
@@ -354,7 +537,7 @@ func BenchmarkBinaryHeapPop100000(b *testing.B) {
 func BenchmarkBinaryHeapPush100(b *testing.B) {
 	b.StopTimer()
 	size := 100
-	heap := NewWithIntComparator()
+	heap := New[int]()
 	b.StartTimer()
 	benchmarkPush(b, heap, size)
 }
@@ -362,7 +545,7 @@ func BenchmarkBinaryHeapPush100(b *testing.B) {
 func BenchmarkBinaryHeapPush1000(b *testing.B) {
 	b.StopTimer()
 	size := 1000
-	heap := NewWithIntComparator()
+	heap := New[int]()
 	for n := 0; n < size; n++ {
 		heap.Push(n)
 	}
@@ -373,7 +556,7 @@ func BenchmarkBinaryHeapPush1000(b *testing.B) {
 func BenchmarkBinaryHeapPush10000(b *testing.B) {
 	b.StopTimer()
 	size := 10000
-	heap := NewWithIntComparator()
+	heap := New[int]()
 	for n := 0; n < size; n++ {
 		heap.Push(n)
 	}
@@ -384,7 +567,7 @@ func BenchmarkBinaryHeapPush10000(b *testing.B) {
 func BenchmarkBinaryHeapPush100000(b *testing.B) {
 	b.StopTimer()
 	size := 100000
-	heap := NewWithIntComparator()
+	heap := New[int]()
 	for n := 0; n < size; n++ {
 		heap.Push(n)
 	}
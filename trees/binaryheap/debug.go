@@ -0,0 +1,31 @@
+// Copyright (c) 2015, Emir Pasic. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package binaryheap
+
+import (
+	"fmt"
+
+	"github.com/fmorenovr/gods/utils"
+)
+
+// debugCheck runs after every mutation in builds with the godsdebug tag (see utils.Debug).
+// It spot-checks the comparator on (up to three of) values, the top and the last element of the heap,
+// then re-validates the heap, and panics with a report if anything is wrong.
+func (heap *Heap[T]) debugCheck(operation string, values ...T) {
+	sample := values
+	if len(sample) > 3 {
+		sample = sample[:3]
+	}
+	if len(heap.list) > 0 {
+		sample = append(sample[:len(sample):len(sample)], heap.list[0], heap.list[len(heap.list)-1])
+	}
+	err := utils.CheckComparator(heap.Comparator, sample...)
+	if err == nil {
+		err = heap.Validate()
+	}
+	if err != nil {
+		utils.DebugFailure("binaryheap.Heap", fmt.Sprintf("%v(%v)", operation, values), err, heap)
+	}
+}
@@ -0,0 +1,25 @@
+// Copyright (c) 2015, Emir Pasic. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+//go:build godsdebug
+
+package binaryheap
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestBinaryHeapDebugCorruption(t *testing.T) {
+	heap := New[int]()
+	heap.Push(15, 20, 3, 1, 2)
+	heap.list[4] = 0
+	defer func() {
+		err, _ := recover().(error)
+		if err == nil || !strings.Contains(err.Error(), "binaryheap.Heap.Push([100])") || !strings.Contains(err.Error(), "parent") {
+			t.Errorf("Got %v expected a heap property report from Push", err)
+		}
+	}()
+	heap.Push(100)
+}
@@ -0,0 +1,41 @@
+// Copyright (c) 2015, Emir Pasic. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package binaryheap
+
+import (
+	"fmt"
+	"github.com/fmorenovr/gods/trees"
+	"io"
+)
+
+// WriteDOT writes the implicit tree of the heap in Graphviz DOT,
+// element i being the parent of elements 2i+1 and 2i+2.
+func (heap *Heap[T]) WriteDOT(w io.Writer, opts gotree.ExportOptions[T]) error {
+	return heap.Graph(opts).WriteDOT(w)
+}
+
+// WriteMermaid writes the implicit tree of the heap as a Mermaid flowchart,
+// element i being the parent of elements 2i+1 and 2i+2.
+func (heap *Heap[T]) WriteMermaid(w io.Writer, opts gotree.ExportOptions[T]) error {
+	return heap.Graph(opts).WriteMermaid(w)
+}
+
+// Graph returns the drawing that WriteDOT and WriteMermaid render, one node per element
+// labeled with its value and index in the backing slice. Node ids follow the slice,
+// so the parent of node i is node (i-1)/2.
+func (heap *Heap[T]) Graph(opts gotree.ExportOptions[T]) *gotree.Graph {
+	g := gotree.NewGraph(opts.Name, false)
+	ids := make([]string, len(heap.list))
+	for i, value := range heap.list {
+		ids[i] = g.AddNode(gotree.GraphNode{
+			Labels:    []string{fmt.Sprintf("%v", value), fmt.Sprintf("[%d]", i)},
+			Highlight: opts.Highlighted(value, heap.Comparator),
+		})
+		if i > 0 {
+			g.AddEdge(ids[(i-1)/2], ids[i], 0)
+		}
+	}
+	return g
+}
@@ -4,28 +4,32 @@
 
 package binaryheap
 
-import "github.com/emirpasic/gods/containers"
+import (
+	"iter"
+
+	"github.com/fmorenovr/gods/utils"
+)
 
 func assertIteratorImplementation() {
-	var _ containers.ReverseIteratorWithIndex = (*Iterator)(nil)
+	var _ utils.ReverseIteratorWithIndex[int] = (*Iterator[int])(nil)
 }
 
 // Iterator returns a stateful iterator whose values can be fetched by an index.
-type Iterator struct {
-	heap  *Heap
+type Iterator[T any] struct {
+	heap  *Heap[T]
 	index int
 }
 
 // Iterator returns a stateful iterator whose values can be fetched by an index.
-func (heap *Heap) Iterator() Iterator {
-	return Iterator{heap: heap, index: -1}
+func (heap *Heap[T]) Iterator() Iterator[T] {
+	return Iterator[T]{heap: heap, index: -1}
 }
 
 // Next moves the iterator to the next element and returns true if there was a next element in the container.
 // If Next() returns true, then next element's index and value can be retrieved by Index() and Value().
 // If Next() was called for the first time, then it will point the iterator to the first element if it exists.
 // Modifies the state of the iterator.
-func (iterator *Iterator) Next() bool {
+func (iterator *Iterator[T]) Next() bool {
 	if iterator.index < iterator.heap.Size() {
 		iterator.index++
 	}
@@ -35,7 +39,7 @@ func (iterator *Iterator) Next() bool {
 // Prev moves the iterator to the previous element and returns true if there was a previous element in the container.
 // If Prev() returns true, then previous element's index and value can be retrieved by Index() and Value().
 // Modifies the state of the iterator.
-func (iterator *Iterator) Prev() bool {
+func (iterator *Iterator[T]) Prev() bool {
 	if iterator.index >= 0 {
 		iterator.index--
 	}
@@ -44,33 +48,35 @@ func (iterator *Iterator) Prev() bool {
 
 // Value returns the current element's value.
 // Does not modify the state of the iterator.
-func (iterator *Iterator) Value() interface{} {
-	value, _ := iterator.heap.list.Get(iterator.index)
-	return value
+func (iterator *Iterator[T]) Value() (value T) {
+	if !iterator.heap.withinRange(iterator.index) {
+		return value
+	}
+	return iterator.heap.list[iterator.index]
 }
 
 // Index returns the current element's index.
 // Does not modify the state of the iterator.
-func (iterator *Iterator) Index() int {
+func (iterator *Iterator[T]) Index() int {
 	return iterator.index
 }
 
 // Begin resets the iterator to its initial state (one-before-first)
 // Call Next() to fetch the first element if any.
-func (iterator *Iterator) Begin() {
+func (iterator *Iterator[T]) Begin() {
 	iterator.index = -1
 }
 
 // End moves the iterator past the last element (one-past-the-end).
 // Call Prev() to fetch the last element if any.
-func (iterator *Iterator) End() {
+func (iterator *Iterator[T]) End() {
 	iterator.index = iterator.heap.Size()
 }
 
 // First moves the iterator to the first element and returns true if there was a first element in the container.
 // If First() returns true, then first element's index and value can be retrieved by Index() and Value().
 // Modifies the state of the iterator.
-func (iterator *Iterator) First() bool {
+func (iterator *Iterator[T]) First() bool {
 	iterator.Begin()
 	return iterator.Next()
 }
@@ -78,7 +84,20 @@ func (iterator *Iterator) First() bool {
 // Last moves the iterator to the last element and returns true if there was a last element in the container.
 // If Last() returns true, then last element's index and value can be retrieved by Index() and Value().
 // Modifies the state of the iterator.
-func (iterator *Iterator) Last() bool {
+func (iterator *Iterator[T]) Last() bool {
 	iterator.End()
 	return iterator.Prev()
 }
+
+// Drain returns an iterator that pops the elements off the heap in priority order, for use with for-range.
+// Every yielded element is removed from the heap; stopping the loop early leaves the rest in place.
+func (heap *Heap[T]) Drain() iter.Seq[T] {
+	return func(yield func(T) bool) {
+		for {
+			value, ok := heap.Pop()
+			if !ok || !yield(value) {
+				return
+			}
+		}
+	}
+}
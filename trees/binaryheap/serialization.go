@@ -4,19 +4,198 @@
 
 package binaryheap
 
-import "github.com/emirpasic/gods/containers"
+import (
+	"bufio"
+	"encoding"
+	"encoding/json"
+	"fmt"
+	"github.com/fmorenovr/gods/trees"
+	"github.com/fmorenovr/gods/utils"
+	"io"
+)
 
 func assertSerializationImplementation() {
-	var _ containers.JSONSerializer = (*Heap)(nil)
-	var _ containers.JSONDeserializer = (*Heap)(nil)
+	var _ utils.JSONSerializer = (*Heap[int])(nil)
+	var _ utils.JSONDeserializer = (*Heap[int])(nil)
+	var _ json.Marshaler = (*Heap[int])(nil)
+	var _ json.Unmarshaler = (*Heap[int])(nil)
+	var _ encoding.BinaryMarshaler = (*Heap[int])(nil)
+	var _ encoding.BinaryUnmarshaler = (*Heap[int])(nil)
 }
 
 // ToJSON outputs the JSON representation of list's elements.
-func (heap *Heap) ToJSON() ([]byte, error) {
-	return heap.list.ToJSON()
+func (heap *Heap[T]) ToJSON() ([]byte, error) {
+	return json.Marshal(heap.list)
 }
 
 // FromJSON populates list's elements from the input JSON representation.
-func (heap *Heap) FromJSON(data []byte) error {
-	return heap.list.FromJSON(data)
+func (heap *Heap[T]) FromJSON(data []byte) error {
+	var elements []T
+	err := json.Unmarshal(data, &elements)
+	if err == nil {
+		heap.list = elements
+	}
+	return gotree.CorruptJSON(err)
+}
+
+// SetCodec sets how the JSON and binary forms encode elements, nil keeps the defaults
+// (encoding/json and utils.BinaryCodec). In JSON the codec must write JSON values.
+func (heap *Heap[T]) SetCodec(codec utils.Codec[T]) {
+	heap.codec = codec
+}
+
+// MarshalJSON writes the elements as a JSON array, in the order of the backing slice.
+func (heap *Heap[T]) MarshalJSON() ([]byte, error) {
+	if heap.codec == nil {
+		return json.Marshal(heap.list)
+	}
+	raws := make([]json.RawMessage, len(heap.list))
+	for i, value := range heap.list {
+		data, err := heap.codec.Encode(value)
+		if err != nil {
+			return nil, fmt.Errorf("binaryheap: element %v: %w", i, err)
+		}
+		raws[i] = data
+	}
+	return json.Marshal(raws)
+}
+
+// UnmarshalJSON replaces the elements of the heap with the array written by MarshalJSON and
+// restores the heap property, so any array is accepted. A zero-value heap, as json.Unmarshal
+// allocates it, is a min-heap ordered by utils.DefaultComparator.
+func (heap *Heap[T]) UnmarshalJSON(data []byte) error {
+	comparator := heap.Comparator
+	if comparator == nil {
+		if comparator = utils.DefaultComparator[T](); comparator == nil {
+			return fmt.Errorf("binaryheap: no natural order for elements of type %T, build the heap with NewWith", *new(T))
+		}
+	}
+	var raws []json.RawMessage
+	if err := json.Unmarshal(data, &raws); err != nil {
+		return gotree.CorruptJSON(err)
+	}
+	codec := heap.jsonCodec()
+	values := make([]T, len(raws))
+	for i, raw := range raws {
+		value, err := codec.Decode(raw)
+		if err != nil {
+			return gotree.CorruptEntry(i, -1, err)
+		}
+		values[i] = value
+	}
+	heap.Comparator = comparator
+	heap.Clear()
+	if len(values) > 0 {
+		heap.Push(values...)
+	}
+	return nil
+}
+
+// MarshalBinary writes the elements in the binary form of gotree.MarshalBinaryEntries, in the
+// order of the backing slice, which already is a heap.
+func (heap *Heap[T]) MarshalBinary() ([]byte, error) {
+	entries := func(yield func(T, struct{}) bool) {
+		for _, value := range heap.list {
+			if !yield(value, struct{}{}) {
+				return
+			}
+		}
+	}
+	return gotree.MarshalBinaryEntries(gotree.BinaryHeader{Kind: "binaryheap", Count: len(heap.list)}, entries, heap.codec, nil)
+}
+
+// UnmarshalBinary replaces the elements of the heap with the ones written by MarshalBinary and
+// restores the heap property in O(n), so that a heap written with another comparator loads too.
+// Corrupt data is an error and leaves the heap untouched. A zero-value heap is a min-heap
+// ordered by utils.DefaultComparator.
+func (heap *Heap[T]) UnmarshalBinary(data []byte) error {
+	comparator := heap.Comparator
+	if comparator == nil {
+		if comparator = utils.DefaultComparator[T](); comparator == nil {
+			return fmt.Errorf("binaryheap: no natural order for elements of type %T, build the heap with NewWith", *new(T))
+		}
+	}
+	_, values, _, err := gotree.UnmarshalBinaryEntries[T, struct{}](data, "binaryheap", nil, heap.codec, nil)
+	if err != nil {
+		return err
+	}
+	heap.Comparator = comparator
+	heap.Clear()
+	if len(values) > 0 {
+		heap.Push(values...)
+	}
+	return nil
+}
+
+// Encode streams the elements to w in the JSON form of MarshalJSON, one element at a time.
+func (heap *Heap[T]) Encode(w io.Writer) error {
+	codec := heap.jsonCodec()
+	buffer := bufio.NewWriter(w)
+	buffer.WriteByte('[')
+	for i, value := range heap.list {
+		data, err := codec.Encode(value)
+		if err != nil {
+			return fmt.Errorf("binaryheap: element %v: %w", i, err)
+		}
+		if i > 0 {
+			buffer.WriteByte(',')
+		}
+		buffer.Write(data)
+	}
+	buffer.WriteByte(']')
+	return buffer.Flush()
+}
+
+// Decode replaces the elements of the heap with the JSON array of MarshalJSON read from r one
+// element at a time, then restores the heap property in O(n), so any array is accepted. An error
+// leaves the heap untouched. A zero-value heap is a min-heap ordered by utils.DefaultComparator.
+func (heap *Heap[T]) Decode(r io.Reader) error {
+	comparator := heap.Comparator
+	if comparator == nil {
+		if comparator = utils.DefaultComparator[T](); comparator == nil {
+			return fmt.Errorf("binaryheap: no natural order for elements of type %T, build the heap with NewWith", *new(T))
+		}
+	}
+	codec := heap.jsonCodec()
+	decoder := json.NewDecoder(r)
+	token, err := decoder.Token()
+	if err != nil {
+		return gotree.CorruptJSON(err)
+	}
+	if token == nil {
+		heap.Comparator = comparator
+		heap.Clear()
+		return nil
+	}
+	if token != json.Delim('[') {
+		return gotree.CorruptEntry(-1, decoder.InputOffset(), fmt.Errorf("got %v expected an array", token))
+	}
+	var values []T
+	for i := 0; decoder.More(); i++ {
+		offset := decoder.InputOffset()
+		var raw json.RawMessage
+		if err := decoder.Decode(&raw); err != nil {
+			return gotree.CorruptEntry(i, offset, err)
+		}
+		value, err := codec.Decode(raw)
+		if err != nil {
+			return gotree.CorruptEntry(i, offset, err)
+		}
+		values = append(values, value)
+	}
+	if _, err := decoder.Token(); err != nil {
+		return gotree.CorruptJSON(err)
+	}
+	heap.Comparator = comparator
+	heap.list = values
+	heap.heapify()
+	return nil
+}
+
+// jsonCodec returns the codec of the JSON forms.
+func (heap *Heap[T]) jsonCodec() utils.Codec[T] {
+	if heap.codec == nil {
+		return utils.JSONCodec[T]{}
+	}
+	return heap.codec
 }
@@ -0,0 +1,22 @@
+// Copyright (c) 2015, Emir Pasic. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package binaryheap
+
+import "fmt"
+
+// Validate checks the heap property and returns nil if it holds:
+// no element compares smaller than its parent under the heap's comparator.
+//
+// Otherwise it returns an error that names the offending element and its index.
+func (heap *Heap[T]) Validate() error {
+	for index := 1; index < len(heap.list); index++ {
+		parent := (index - 1) / 2
+		if heap.Comparator(heap.list[parent], heap.list[index]) > 0 {
+			return fmt.Errorf("binaryheap: element %v at index %v is smaller than its parent %v at index %v",
+				heap.list[index], index, heap.list[parent], parent)
+		}
+	}
+	return nil
+}
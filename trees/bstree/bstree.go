@@ -1,162 +1,271 @@
 package bstree
 
 import (
+  "cmp";
   "fmt";
-  "github.com/jenazads/goutils";
+  "io";
+  "iter";
+  "slices";
+  "github.com/fmorenovr/gods/trees";
+  "github.com/fmorenovr/gods/utils";
 )
 
 // BSTree object
-type BSTree struct {
-  Root       *BSTNode                // Root node
-  comparator goutils.TypeComparator  // Key comparator
-  operator   goutils.TypeOperator    // Type Operator
+type BSTree[K comparable, V any] struct {
+  Root       *BSTNode[K, V]     // Root node
+  comparator utils.Comparator[K]  // Key comparator
+  operator   utils.Operator[K]    // Key operator, used by SumNodes
+  size       int                  // Number of nodes, distinct keys
+  count      int                  // Number of values, duplicates included
+  keyCodec   utils.Codec[K]       // Encodes keys in the JSON and binary forms, nil for the default
+  valueCodec utils.Codec[V]       // Encodes each value in the JSON and binary forms, nil for the default
 }
 
 // Node 
-type BSTNode struct {
-  Key      interface{}
-  Value    []interface{}
+type BSTNode[K comparable, V any] struct {
+  Key      K
+  Value    []V
   Count    int
-  Parent   *BSTNode      // Parent node
-  Children [2]*BSTNode   // Children nodes, 0-> left, 1-> right
+  Parent   *BSTNode[K, V]      // Parent node
+  Children [2]*BSTNode[K, V]   // Children nodes, 0-> left, 1-> right
 }
 
-// New BS Tree
-func NewBSTree(comp goutils.TypeComparator, op goutils.TypeOperator) (*BSTree) {
-  return &BSTree{comparator: comp, operator: op}
+// New BS Tree with the natural order of K, SumNodes adds keys with +
+func NewBSTree[K cmp.Ordered, V any]() (*BSTree[K, V]) {
+  return NewBSTreeWith[K, V](utils.OrderedComparator[K](), utils.AddOperator[K]())
+}
+
+// New BS Tree with a custom comparator and operator
+func NewBSTreeWith[K comparable, V any](comp utils.Comparator[K], op utils.Operator[K]) (*BSTree[K, V]) {
+  return &BSTree[K, V]{comparator: comp, operator: op}
 }
 
 // New BS Node
-func NewBSTNode(key interface{}, p *BSTNode) (*BSTNode) {
-  return &BSTNode{Key: key, Value: nil, Parent: p}
+func NewBSTNode[K comparable, V any](key K, p *BSTNode[K, V]) (*BSTNode[K, V]) {
+  return &BSTNode[K, V]{Key: key, Value: nil, Parent: p}
 }
 
 // IsEmpty, true if tree doesnt have nodes
-func (t *BSTree) IsEmpty() (bool) {
+func (t *BSTree[K, V]) IsEmpty() (bool) {
   return (t.Root == nil)
 }
 
 // Return true if the node is leaf
-func IsLeaf(node *BSTNode) (bool) {
+func IsLeaf[K comparable, V any](node *BSTNode[K, V]) (bool) {
   return (node.Children[0]==nil && node.Children[1]==nil);
 }
 
 // Removes all nodes
-func (t *BSTree) Clear() {
+func (t *BSTree[K, V]) Clear() {
   t.Root = nil
+  t.size = 0
+  t.count = 0
 }
 
-// Insert New Node by Key
-func (t *BSTree) Insert(key interface{}, value interface{}) {
+// Insert New Node by Key, a repeated key keeps every inserted value
+func (t *BSTree[K, V]) Insert(key K, value V) {
+  if utils.Debug {
+    defer t.debugCheck("Insert", key)
+  }
+  if bstSearch(t.Root, key, t.comparator) == nil {
+    t.size++
+  }
+  t.count++
   t.Root = bstInsert(t.Root, key, value, nil, t.comparator)
 }
 
-// Remove Node by key
-func (t *BSTree) Remove(key interface{}) {
-  t.Root = bstRemove(t.Root, key, t.comparator)
+// Put replaces all the values stored under key with a copy of values, no values removes the key
+func (t *BSTree[K, V]) Put(key K, values []V) {
+  if len(values) == 0 {
+    t.Remove(key)
+    return
+  }
+  if utils.Debug {
+    defer t.debugCheck("Put", key)
+  }
+  values = slices.Clone(values)
+  if node := bstSearch(t.Root, key, t.comparator); node != nil {
+    t.count -= node.Count
+  } else {
+    t.size++
+  }
+  t.count += len(values)
+  t.Root = bstPut(t.Root, key, values, nil, t.comparator)
+}
+
+// Remove Node by key, with all of its values
+func (t *BSTree[K, V]) Remove(key K) {
+  if utils.Debug {
+    defer t.debugCheck("Remove", key)
+  }
+  node := bstSearch(t.Root, key, t.comparator)
+  if node == nil {
+    return
+  }
+  t.size--
+  t.count -= node.Count
+  t.Root = bstRemove(t.Root, key, true, t.comparator)
+}
+
+// RemoveOne removes the last inserted value of key, the node goes away with its last value
+func (t *BSTree[K, V]) RemoveOne(key K) {
+  if utils.Debug {
+    defer t.debugCheck("RemoveOne", key)
+  }
+  node := bstSearch(t.Root, key, t.comparator)
+  if node == nil {
+    return
+  }
+  if node.Count <= 1 {
+    t.size--
+  }
+  if node.Count > 0 {
+    t.count--
+  }
+  t.Root = bstRemove(t.Root, key, false, t.comparator)
 }
 
 // Search Value, return the node
-func (t *BSTree) Search(key interface{}) (*BSTNode) {
+func (t *BSTree[K, V]) Search(key K) (*BSTNode[K, V]) {
   return bstSearch(t.Root, key, t.comparator)
 }
 
-// Get Value
-func (t *BSTree) Get(key interface{}) (interface{}) {
+// Get all the values stored under key, found is false if the key is not in the tree
+func (t *BSTree[K, V]) Get(key K) (values []V, found bool) {
   node := bstSearch(t.Root, key, t.comparator)
-  if node.Value == nil {
-    return nil
+  if node == nil {
+    return nil, false
   }
-  return node.Value
+  return node.Value, true
+}
+
+// Contains returns true if the key is in the tree
+func (t *BSTree[K, V]) Contains(key K) (bool) {
+  return bstSearch(t.Root, key, t.comparator) != nil
 }
 
 // return BS Tree Height
-func (t *BSTree) Height() (int) {
+func (t *BSTree[K, V]) Height() (int) {
   return bstHeight(t.Root)
 }
 
 // Return Size of tree
-func (t *BSTree) Size() (int) {
-  return bstSize(t.Root)
+func (t *BSTree[K, V]) Size() (int) {
+  return t.size
+}
+
+// Count returns the number of values in the tree, duplicates included
+func (t *BSTree[K, V]) Count() (int) {
+  return t.count
+}
+
+// Len returns the number of distinct keys in the tree, same as Size
+func (t *BSTree[K, V]) Len() (int) {
+  return t.Size()
 }
 
 // Return number of Leaf
-func (t *BSTree) LeafCount() (int) {
+func (t *BSTree[K, V]) LeafCount() (int) {
   return bstLeafCount(t.Root)
 }
 
 // Return minimum element
-func (t *BSTree) Left() (*BSTNode) {
+func (t *BSTree[K, V]) Left() (*BSTNode[K, V]) {
   return bstFindNode(t.Root, 0)
 }
 
 // Return maximum element
-func (t *BSTree) Right() (*BSTNode) {
+func (t *BSTree[K, V]) Right() (*BSTNode[K, V]) {
   return bstFindNode(t.Root, 1)
 }
 
+// Min returns the minimum key and its values
+func (t *BSTree[K, V]) Min() (key K, values []V, found bool) {
+  return bstEntry(t.Left())
+}
+
+// Max returns the maximum key and its values
+func (t *BSTree[K, V]) Max() (key K, values []V, found bool) {
+  return bstEntry(t.Right())
+}
+
 // Return sum of all nodes
-func (t *BSTree) SumNodes() (interface{}) {
+func (t *BSTree[K, V]) SumNodes() (K) {
   return bstSumNodes(t.Root, t.operator)
 }
 
-// Return the height of a specific node
-func (t *BSTree) HeightOfNode(key interface{}) (int) {
+// HeightOfNode returns the depth of the node of key, 0 for the root; found is false if the key
+// is not in the tree
+func (t *BSTree[K, V]) HeightOfNode(key K) (height int, found bool) {
   return bstHeightOfNode(t.Root, key, t.comparator)
 }
 
-// print preorder
-func (t *BSTree) PrintPreOrder() {
-  bstPrintPreorder(t.Root)
-  fmt.Println()
+// Walk visits every node in the given order until visitor returns false
+func (t *BSTree[K, V]) Walk(order gotree.TraversalOrder, visitor func(key K, value []V) bool) {
+  if order == gotree.LevelOrder {
+    bstWalkLevels(t.Root, visitor)
+  } else {
+    bstWalk(t.Root, order, visitor)
+  }
+}
+
+// Traverse returns the key/value pairs in the given order as a range-over-func sequence
+func (t *BSTree[K, V]) Traverse(order gotree.TraversalOrder) iter.Seq2[K, []V] {
+  return func(yield func(K, []V) bool) {
+    t.Walk(order, yield)
+  }
 }
 
-// print inorder
-func (t *BSTree) PrintInOrder() {
-  bstPrintInorder(t.Root)
-  fmt.Println()
+// print preorder into w
+func (t *BSTree[K, V]) PrintPreOrder(w io.Writer) {
+  bstPrintOrder(w, t, gotree.PreOrder)
 }
 
-// print postorder
-func (t *BSTree) PrintPostOrder() {
-  bstPrintPostorder(t.Root)
-  fmt.Println()
+// print inorder into w
+func (t *BSTree[K, V]) PrintInOrder(w io.Writer) {
+  bstPrintOrder(w, t, gotree.InOrder)
 }
 
-// Return the parent node of a specific value
-func (t *BSTree) Parent(key interface{}) (*BSTNode) {
+// print postorder into w
+func (t *BSTree[K, V]) PrintPostOrder(w io.Writer) {
+  bstPrintOrder(w, t, gotree.PostOrder)
+}
+
+// Parent returns the parent node of key, nil for the root; found is false if the key is not in the tree
+func (t *BSTree[K, V]) Parent(key K) (parent *BSTNode[K, V], found bool) {
   return getParentNode(t.Root, key, t.comparator)
 }
 
-// Return the brother of a specific value
-func (t *BSTree) Brother(key interface{}) (*BSTNode) {
+// Brother returns the other child of the parent of key, nil if it has none or key is the root;
+// found is false if the key is not in the tree
+func (t *BSTree[K, V]) Brother(key K) (brother *BSTNode[K, V], found bool) {
   return getBrotherNode(t.Root, key, t.comparator)
 }
 
 // Return previous or predecessor node
-func (node *BSTNode) Prev() (*BSTNode) {
+func (node *BSTNode[K, V]) Prev() (*BSTNode[K, V]) {
   return bstFindNeighbourNode(node, 0)
 }
 
 // Return next or sucessor node
-func (node *BSTNode) Next() (*BSTNode) {
+func (node *BSTNode[K, V]) Next() (*BSTNode[K, V]) {
   return bstFindNeighbourNode(node, 1)
 }
 
 // Return bst mirror
-func (t *BSTree) Mirror() {
+func (t *BSTree[K, V]) Mirror() {
   bstMirror(t.Root)
 }
 
 // Compare 2 BSTree
-func (t *BSTree) IsSameAs(otherTree *BSTree) (bool) {
+func (t *BSTree[K, V]) IsSameAs(otherTree *BSTree[K, V]) (bool) {
   return bstIsSameAs(t.Root, otherTree.Root)
 }
 
 // Keys returns all keys in-order
-func (t *BSTree) Keys() ([]interface{}) {
+func (t *BSTree[K, V]) Keys() ([]K) {
   size:=t.Size()
-  keys := make([]interface{}, size)
+  keys := make([]K, size)
   it := t.Iterator()
   for i := 0; it.Next(); i++ {
     keys[i] = it.Key()
@@ -165,11 +274,11 @@ func (t *BSTree) Keys() ([]interface{}) {
 }
 
 // Values returns all values in-order based on the key.
-func (t *BSTree) Values() ([]interface{}) {
-  var values []interface{}
+func (t *BSTree[K, V]) Values() ([]V) {
+  values := make([]V, 0, t.count)
   it := t.Iterator()
   for i := 0; it.Next(); i++ {
-    vals := (it.Value()).([]interface{})
+    vals := it.Value()
     size := len(vals)
     for j:=0; j<size; j++ {
       values = append(values, vals[j])
@@ -178,13 +287,13 @@ func (t *BSTree) Values() ([]interface{}) {
   return values
 }
 
-// Print the BSTree
-func (t *BSTree) Print() {
-  bstTreePrintLevels(t.Root)
+// Print the BSTree level by level into w
+func (t *BSTree[K, V]) Print(w io.Writer) {
+  bstTreePrintLevels(w, t.Root)
 }
 
 // Print Tree with fmt.Print*
-func (t *BSTree) String() (string) {
+func (t *BSTree[K, V]) String() (string) {
   str := ""
 	if !t.IsEmpty() {
 		bstTreePrint(t.Root, "", false, &str)
@@ -193,18 +302,42 @@ func (t *BSTree) String() (string) {
 }
 
 // Print Node with fmt.Print*
-func (node *BSTNode) String() (string) {
+func (node *BSTNode[K, V]) String() (string) {
   return fmt.Sprintf("%v:%v", node.Key, node.Count)
 }
 
-// Return the largest node that is smaller than or equal to the given node.
-func (t *BSTree) Floor(key interface{}) (*BSTNode) {
-  node := t.Search(key)
-  return node.Next()
+// Return the largest node that is smaller than or equal to the given key, found is false if there is none
+func (t *BSTree[K, V]) Floor(key K) (floor *BSTNode[K, V], found bool) {
+  floor = bstFloor(t.Root, key, true, t.comparator)
+  return floor, floor != nil
+}
+
+// Return the smallest node that is larger than or equal to the given key, found is false if there is none
+func (t *BSTree[K, V]) Ceiling(key K) (ceiling *BSTNode[K, V], found bool) {
+  ceiling = bstCeiling(t.Root, key, true, t.comparator)
+  return ceiling, ceiling != nil
+}
+
+// Return the largest node that is strictly smaller than the given key, found is false if there is none
+func (t *BSTree[K, V]) Lower(key K) (lower *BSTNode[K, V], found bool) {
+  lower = bstFloor(t.Root, key, false, t.comparator)
+  return lower, lower != nil
+}
+
+// Return the smallest node that is strictly larger than the given key, found is false if there is none
+func (t *BSTree[K, V]) Higher(key K) (higher *BSTNode[K, V], found bool) {
+  higher = bstCeiling(t.Root, key, false, t.comparator)
+  return higher, higher != nil
+}
+
+// FloorEntry returns the largest key smaller than or equal to the given key and its values
+func (t *BSTree[K, V]) FloorEntry(key K) (floor K, values []V, found bool) {
+  node, _ := t.Floor(key)
+  return bstEntry(node)
 }
 
-// Return the smallest node that is larger than or equal to the given node.
-func (t *BSTree) Ceiling(key interface{}) (*BSTNode) {
-  node := t.Search(key)
-  return node.Prev()
+// CeilingEntry returns the smallest key larger than or equal to the given key and its values
+func (t *BSTree[K, V]) CeilingEntry(key K) (ceiling K, values []V, found bool) {
+  node, _ := t.Ceiling(key)
+  return bstEntry(node)
 }
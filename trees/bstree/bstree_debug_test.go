@@ -0,0 +1,44 @@
+//go:build godsdebug
+
+package bstree_test
+
+import (
+  "strings"
+  "testing"
+  "github.com/fmorenovr/gods/trees/bstree"
+  "github.com/fmorenovr/gods/utils"
+)
+
+func TestBSTreeDebugBadComparator(t *testing.T) {
+  // not antisymmetric: the first argument always wins unless the keys are equal
+  tree := bstree.NewBSTreeWith[int, string](func(a, b int) int {
+    if a == b {
+      return 0
+    }
+    return 1
+  }, utils.AddOperator[int]())
+  defer func() {
+    err, _ := recover().(error)
+    if err == nil || !strings.Contains(err.Error(), "BSTree.Insert(") || !strings.Contains(err.Error(), "antisymmetric") {
+      t.Errorf("Got %v expected a comparator report from Insert", err)
+    }
+  }()
+  for _, key := range []int{1, 2, 3} {
+    tree.Insert(key, "")
+  }
+}
+
+func TestBSTreeDebugCorruption(t *testing.T) {
+  tree := bstree.NewBSTree[int, string]()
+  for _, key := range []int{4, 2, 6, 1, 3, 5, 7} {
+    tree.Insert(key, "")
+  }
+  tree.Root.Children[0].Key = 100
+  defer func() {
+    err, _ := recover().(error)
+    if err == nil || !strings.Contains(err.Error(), "BSTree.Remove(7)") || !strings.Contains(err.Error(), "100") {
+      t.Errorf("Got %v expected a report naming node 100", err)
+    }
+  }()
+  tree.Remove(7)
+}
@@ -0,0 +1,48 @@
+package bstree
+
+import (
+  "fmt";
+  "io";
+  "github.com/fmorenovr/gods/trees";
+  "github.com/fmorenovr/gods/utils";
+)
+
+// WriteDOT writes the tree in Graphviz DOT, every node labeled with its key and, for duplicates, its count
+func (t *BSTree[K, V]) WriteDOT(w io.Writer, opts gotree.ExportOptions[K]) error {
+  return t.Graph(opts).WriteDOT(w)
+}
+
+// WriteMermaid writes the tree as a Mermaid flowchart, every node labeled with its key and, for duplicates, its count
+func (t *BSTree[K, V]) WriteMermaid(w io.Writer, opts gotree.ExportOptions[K]) error {
+  return t.Graph(opts).WriteMermaid(w)
+}
+
+// Graph returns the drawing that WriteDOT and WriteMermaid render
+func (t *BSTree[K, V]) Graph(opts gotree.ExportOptions[K]) (*gotree.Graph) {
+  g := gotree.NewGraph(opts.Name, false)
+  bstGraph(g, t.Root, opts, t.comparator)
+  return g
+}
+
+// bstGraph adds the subtree of node to g and returns the id of node
+func bstGraph[K comparable, V any](g *gotree.Graph, node *BSTNode[K, V], opts gotree.ExportOptions[K], comp utils.Comparator[K]) (string) {
+  if node == nil {
+    return ""
+  }
+  labels := []string{fmt.Sprintf("%v", node.Key)}
+  if node.Count > 1 {
+    labels = append(labels, fmt.Sprintf("x%d", node.Count))
+  }
+  id := g.AddNode(gotree.GraphNode{Labels: labels, Highlight: opts.Highlighted(node.Key, comp)})
+  if IsLeaf(node) {
+    return id
+  }
+  for _, child := range node.Children {
+    if child == nil {
+      g.AddEdge(id, g.AddNode(gotree.GraphNode{Invisible: true}), 0)
+    } else {
+      g.AddEdge(id, bstGraph(g, child, opts, comp), 0)
+    }
+  }
+  return id
+}
@@ -1,13 +1,473 @@
 package bstree_test
 
 import (
+  "bytes"
+  "encoding/json"
   "fmt"
-  "github.com/jenazads/gods/trees/bstree"
-  "github.com/jenazads/goutils"
+  "os"
+  "reflect"
+  "slices"
+  "strconv"
+  "strings"
+  "testing"
+  "github.com/fmorenovr/gods/trees"
+  "github.com/fmorenovr/gods/trees/bstree"
+  "github.com/fmorenovr/gods/trees/treetest"
+  "github.com/fmorenovr/gods/utils"
 )
 
+func TestBSTreeOrderedMap(t *testing.T) {
+  treetest.Run(t, func() gotree.OrderedMap[int, []string] {
+    return bstree.NewBSTree[int, string]()
+  }, func(key int) []string {
+    return []string{strconv.Itoa(key), strconv.Itoa(-key)}
+  })
+}
+
+func TestBSTreeRangeIterator(t *testing.T) {
+  treetest.RunRange(t, func() gotree.RangeMap[int, []string] {
+    return bstree.NewBSTree[int, string]()
+  }, func(key int) []string {
+    return []string{strconv.Itoa(key), strconv.Itoa(-key)}
+  })
+}
+
+func TestBSTreeSequences(t *testing.T) {
+  tree := bstree.NewBSTree[int, string]()
+  for _, key := range []int{5, 1, 4, 2, 3} {
+    tree.Insert(key, strconv.Itoa(key))
+    tree.Insert(key, strconv.Itoa(-key))
+  }
+  if actualValue, expectedValue := slices.Collect(tree.KeysSeq()), tree.Keys(); !slices.Equal(actualValue, expectedValue) {
+    t.Errorf("Got %v expected %v", actualValue, expectedValue)
+  }
+  if actualValue, expectedValue := slices.Collect(tree.ValuesSeq()), tree.Values(); !slices.Equal(actualValue, expectedValue) {
+    t.Errorf("Got %v expected %v", actualValue, expectedValue)
+  }
+  var keys []int
+  for key := range tree.Backward() {
+    if key == 2 {
+      break
+    }
+    keys = append(keys, key)
+  }
+  if actualValue, expectedValue := keys, []int{5, 4, 3}; !slices.Equal(actualValue, expectedValue) {
+    t.Errorf("Got %v expected %v", actualValue, expectedValue)
+  }
+}
+
+func TestBSTreeWalk(t *testing.T) {
+  tree := bstree.NewBSTree[int, string]()
+  for _, key := range []int{4, 2, 6, 1, 3, 5, 7} {
+    tree.Insert(key, strconv.Itoa(key))
+  }
+  tests := []struct {
+    order    gotree.TraversalOrder
+    expected []int
+  }{
+    {gotree.PreOrder, []int{4, 2, 1, 3, 6, 5, 7}},
+    {gotree.InOrder, []int{1, 2, 3, 4, 5, 6, 7}},
+    {gotree.PostOrder, []int{1, 3, 2, 5, 7, 6, 4}},
+    {gotree.LevelOrder, []int{4, 2, 6, 1, 3, 5, 7}},
+  }
+  for _, test := range tests {
+    var keys []int
+    tree.Walk(test.order, func(key int, _ []string) bool {
+      keys = append(keys, key)
+      return true
+    })
+    if !slices.Equal(keys, test.expected) {
+      t.Errorf("Walk(%v) got %v expected %v", test.order, keys, test.expected)
+    }
+    keys = keys[:0]
+    for key := range tree.Traverse(test.order) {
+      if len(keys) == 3 {
+        break
+      }
+      keys = append(keys, key)
+    }
+    if !slices.Equal(keys, test.expected[:3]) {
+      t.Errorf("Traverse(%v) got %v expected %v", test.order, keys, test.expected[:3])
+    }
+  }
+  var buffer bytes.Buffer
+  tree.PrintPreOrder(&buffer)
+  tree.PrintInOrder(&buffer)
+  tree.PrintPostOrder(&buffer)
+  if actualValue, expectedValue := buffer.String(), "4 2 1 3 6 5 7 \n1 2 3 4 5 6 7 \n1 3 2 5 7 6 4 \n"; actualValue != expectedValue {
+    t.Errorf("Got %q expected %q", actualValue, expectedValue)
+  }
+}
+
+func TestBSTreeValidate(t *testing.T) {
+  tree := bstree.NewBSTree[int, string]()
+  if err := tree.Validate(); err != nil {
+    t.Errorf("Got %v expected %v", err, nil)
+  }
+  for _, key := range []int{4, 2, 6, 1, 3, 5, 7, 3} {
+    tree.Insert(key, strconv.Itoa(key))
+  }
+  if err := tree.Validate(); err != nil {
+    t.Errorf("Got %v expected %v", err, nil)
+  }
+  tree.Root.Children[1].Key = 1
+  if err := tree.Validate(); err == nil || !strings.Contains(err.Error(), "node 1") {
+    t.Errorf("Got %v expected an error naming node 1", err)
+  }
+  tree.Root.Children[1].Key = 6
+  tree.Root.Children[0].Children[1].Count = 1
+  if err := tree.Validate(); err == nil || !strings.Contains(err.Error(), "node 3") {
+    t.Errorf("Got %v expected an error naming node 3", err)
+  }
+  tree.Root.Children[0].Children[1].Count = 2
+  tree.Root.Children[0].Children[1].Parent = nil
+  if err := tree.Validate(); err == nil || !strings.Contains(err.Error(), "child 3") {
+    t.Errorf("Got %v expected an error naming node 3", err)
+  }
+}
+
+func TestBSTreeExport(t *testing.T) {
+  tree := bstree.NewBSTree[int, string]()
+  for _, key := range []int{2, 1, 2, 3} {
+    tree.Insert(key, strconv.Itoa(key))
+  }
+  var dot bytes.Buffer
+  if err := tree.WriteDOT(&dot, gotree.ExportOptions[int]{Highlight: []int{1}}); err != nil {
+    t.Errorf("Got %v expected %v", err, nil)
+  }
+  for _, expectedValue := range []string{`digraph "tree" {`, `n0 [label="2 x2"];`, `n1 [label="1", color="#ff8c00", penwidth=3];`, `n2 [label="3"];`, "n0 -> n1;", "n0 -> n2;"} {
+    if actualValue := dot.String(); !strings.Contains(actualValue, expectedValue) {
+      t.Errorf("Got %v expected it to contain %v", actualValue, expectedValue)
+    }
+  }
+  var mermaid bytes.Buffer
+  if err := tree.WriteMermaid(&mermaid, gotree.ExportOptions[int]{}); err != nil {
+    t.Errorf("Got %v expected %v", err, nil)
+  }
+  for _, expectedValue := range []string{`n0(("2 x2"))`, "n0 --> n2"} {
+    if actualValue := mermaid.String(); !strings.Contains(actualValue, expectedValue) {
+      t.Errorf("Got %v expected it to contain %v", actualValue, expectedValue)
+    }
+  }
+}
+
+func TestBSTreeMissingKeys(t *testing.T) {
+  tree := bstree.NewBSTree[int, string]()
+  assertMissing := func(key int) {
+    t.Helper()
+    if _, found := tree.Get(key); found {
+      t.Errorf("Got found for %v expected not found", key)
+    }
+    if node, found := tree.Parent(key); node != nil || found {
+      t.Errorf("Got %v, %v expected no parent for %v", node, found, key)
+    }
+    if node, found := tree.Brother(key); node != nil || found {
+      t.Errorf("Got %v, %v expected no brother for %v", node, found, key)
+    }
+    if height, found := tree.HeightOfNode(key); height != -1 || found {
+      t.Errorf("Got %v, %v expected no height for %v", height, found, key)
+    }
+    if node := tree.Search(key); node != nil {
+      t.Errorf("Got %v expected no node for %v", node, key)
+    }
+    tree.Remove(key)
+  }
+  assertMissing(1)
+  if _, _, found := tree.Min(); found {
+    t.Errorf("Got found expected an empty tree to have no minimum")
+  }
+  for _, lookup := range []func(int) (*bstree.BSTNode[int, string], bool){tree.Floor, tree.Ceiling, tree.Lower, tree.Higher} {
+    if node, found := lookup(1); node != nil || found {
+      t.Errorf("Got %v, %v expected nothing in an empty tree", node, found)
+    }
+  }
+
+  for _, key := range []int{2, 1, 3} {
+    tree.Insert(key, strconv.Itoa(key))
+  }
+  assertMissing(0)
+  assertMissing(4)
+  if values, found := tree.Get(2); !found || !slices.Equal(values, []string{"2"}) {
+    t.Errorf("Got %v, %v expected %v", values, found, []string{"2"})
+  }
+  if node, found := tree.Parent(2); node != nil || !found {
+    t.Errorf("Got %v, %v expected the root to have no parent", node, found)
+  }
+  if node, found := tree.Parent(3); node == nil || node.Key != 2 || !found {
+    t.Errorf("Got %v, %v expected %v", node, found, 2)
+  }
+  if node, found := tree.Brother(3); node == nil || node.Key != 1 || !found {
+    t.Errorf("Got %v, %v expected %v", node, found, 1)
+  }
+  if height, found := tree.HeightOfNode(1); height != 1 || !found {
+    t.Errorf("Got %v, %v expected %v", height, found, 1)
+  }
+  if node, found := tree.Floor(0); node != nil || found {
+    t.Errorf("Got %v, %v expected no floor below the minimum", node, found)
+  }
+  if node, found := tree.Ceiling(4); node != nil || found {
+    t.Errorf("Got %v, %v expected no ceiling above the maximum", node, found)
+  }
+  if actualValue, expectedValue := tree.Size(), 3; actualValue != expectedValue {
+    t.Errorf("Got %v expected %v after removing missing keys", actualValue, expectedValue)
+  }
+}
+
+func TestBSTreeMarshalJSON(t *testing.T) {
+  tree := bstree.NewBSTree[int, string]()
+  for _, key := range []int{2, 1, 2} {
+    tree.Insert(key, fmt.Sprint("v", tree.Count()))
+  }
+  data, err := json.Marshal(tree)
+  if actualValue, expectedValue := string(data), `[{"key":1,"value":["v1"]},{"key":2,"value":["v0","v2"]}]`; err != nil || actualValue != expectedValue {
+    t.Errorf("Got %v, %v expected %v", actualValue, err, expectedValue)
+  }
+  decoded := new(bstree.BSTree[int, string])
+  if err := json.Unmarshal(data, decoded); err != nil {
+    t.Errorf("Got %v expected %v", err, nil)
+  }
+  if actualValue, expectedValue := decoded.Count(), 3; actualValue != expectedValue {
+    t.Errorf("Got %v expected %v", actualValue, expectedValue)
+  }
+  if values, _ := decoded.Get(2); !slices.Equal(values, []string{"v0", "v2"}) {
+    t.Errorf("Got %v expected %v", values, []string{"v0", "v2"})
+  }
+  decoded.SetCodecs(nil, utils.CodecFuncs[string]{
+    EncodeFunc: func(value string) ([]byte, error) { return json.Marshal(strings.ToUpper(value)) },
+    DecodeFunc: func(data []byte) (value string, err error) {
+      err = json.Unmarshal(data, &value)
+      return strings.ToLower(value), err
+    },
+  })
+  data, err = json.Marshal(decoded)
+  if actualValue, expectedValue := string(data), `[{"key":1,"value":["V1"]},{"key":2,"value":["V0","V2"]}]`; err != nil || actualValue != expectedValue {
+    t.Errorf("Got %v, %v expected %v", actualValue, err, expectedValue)
+  }
+  if err := json.Unmarshal(data, decoded); err != nil {
+    t.Errorf("Got %v expected %v", err, nil)
+  }
+  if values, _ := decoded.Get(1); !slices.Equal(values, []string{"v1"}) {
+    t.Errorf("Got %v expected %v", values, []string{"v1"})
+  }
+  entries := make([]string, 1023)
+  for key := range entries {
+    entries[key] = fmt.Sprintf(`{"key":%d,"value":["%d"]}`, key, key)
+  }
+  if err := json.Unmarshal([]byte("["+strings.Join(entries, ",")+"]"), decoded); err != nil {
+    t.Errorf("Got %v expected %v", err, nil)
+  }
+  if actualValue, expectedValue := decoded.Height(), 9; actualValue != expectedValue {
+    t.Errorf("Got %v expected %v", actualValue, expectedValue)
+  }
+  if err := decoded.Validate(); err != nil || decoded.Size() != 1023 || decoded.Count() != 1023 {
+    t.Errorf("Got %v, %v keys and %v values expected %v, 1023 keys and 1023 values", err, decoded.Size(), decoded.Count(), nil)
+  }
+  if err := json.Unmarshal([]byte(`[{"key":3,"value":["c"]},{"key":1,"value":["a"]},{"key":3,"value":["d"]}]`), decoded); err != nil {
+    t.Errorf("Got %v expected %v", err, nil)
+  }
+  if values, _ := decoded.Get(3); decoded.Validate() != nil || decoded.Size() != 2 || !slices.Equal(values, []string{"c", "d"}) {
+    t.Errorf("Got %v with %v keys expected %v with 2 keys", values, decoded.Size(), []string{"c", "d"})
+  }
+  var zero bstree.BSTree[int, string]
+  if err := zero.UnmarshalJSON([]byte(`{"key":1}`)); err == nil || !reflect.DeepEqual(zero, bstree.BSTree[int, string]{}) {
+    t.Errorf("Got %v expected an error and the tree untouched", err)
+  }
+}
+
+func TestBSTreeMarshalBinary(t *testing.T) {
+  tree := bstree.NewBSTree[string, int]()
+  for i, key := range []string{"d", "b", "f", "a", "c", "e", "b"} {
+    tree.Insert(key, i)
+  }
+  data, err := tree.MarshalBinary()
+  if err != nil {
+    t.Errorf("Got %v expected %v", err, nil)
+  }
+  decoded := new(bstree.BSTree[string, int])
+  if err := decoded.UnmarshalBinary(data); err != nil {
+    t.Errorf("Got %v expected %v", err, nil)
+  }
+  if actualValue, expectedValue := decoded.Keys(), []string{"a", "b", "c", "d", "e", "f"}; !slices.Equal(actualValue, expectedValue) {
+    t.Errorf("Got %v expected %v", actualValue, expectedValue)
+  }
+  if actualValue, expectedValue := decoded.Count(), 7; actualValue != expectedValue {
+    t.Errorf("Got %v expected %v", actualValue, expectedValue)
+  }
+  if values, _ := decoded.Get("b"); !slices.Equal(values, []int{1, 6}) {
+    t.Errorf("Got %v expected %v", values, []int{1, 6})
+  }
+  if actualValue, expectedValue := decoded.Height(), 2; actualValue != expectedValue {
+    t.Errorf("Got %v expected %v, rebuilt balanced", actualValue, expectedValue)
+  }
+  if err := decoded.Validate(); err != nil {
+    t.Errorf("Got %v expected %v", err, nil)
+  }
+  data[len(data)-1]++
+  if err := decoded.UnmarshalBinary(data); err == nil || !strings.Contains(err.Error(), "checksum") || decoded.Count() != 7 {
+    t.Errorf("Got %v expected a checksum error and the tree untouched", err)
+  }
+  var zero bstree.BSTree[string, int]
+  if err := zero.UnmarshalBinary(data); err == nil || !reflect.DeepEqual(zero, bstree.BSTree[string, int]{}) {
+    t.Errorf("Got %v expected an error and the tree untouched", err)
+  }
+}
+
+func TestBSTreeMarshalShape(t *testing.T) {
+  tree := bstree.NewBSTree[int, string]()
+  for _, key := range []int{1, 2, 3, 3} {
+    tree.Insert(key, strconv.Itoa(key))
+  }
+  data, err := tree.MarshalShape()
+  expected := `[{"key":1,"value":["1"]},null,{"key":2,"value":["2"]},null,{"key":3,"value":["3","3"]}]`
+  if actualValue := string(data); err != nil || actualValue != expected {
+    t.Errorf("Got %v, %v expected %v", actualValue, err, expected)
+  }
+  decoded := new(bstree.BSTree[int, string])
+  if err := decoded.UnmarshalShape(data); err != nil {
+    t.Errorf("Got %v expected %v", err, nil)
+  }
+  if actualValue, expectedValue := decoded.Height(), 2; actualValue != expectedValue {
+    t.Errorf("Got %v expected %v, the degenerate shape kept", actualValue, expectedValue)
+  }
+  if actualValue, expectedValue := decoded.Count(), 4; actualValue != expectedValue {
+    t.Errorf("Got %v expected %v", actualValue, expectedValue)
+  }
+  if err := decoded.UnmarshalShape([]byte(`[{"key":1},{"key":2}]`)); err == nil || decoded.Size() != 3 {
+    t.Errorf("Got %v expected an error and the tree untouched", err)
+  }
+}
+
+func TestBSTreeEncodeDecode(t *testing.T) {
+  tree := bstree.NewBSTree[int, string]()
+  for _, key := range []int{1, 2, 3, 4, 5, 6, 7, 7} {
+    tree.Insert(key, strconv.Itoa(key))
+  }
+  var buffer bytes.Buffer
+  if err := tree.Encode(&buffer); err != nil {
+    t.Errorf("Got %v expected %v", err, nil)
+  }
+  decoded := new(bstree.BSTree[int, string])
+  if err := decoded.Decode(&buffer); err != nil {
+    t.Errorf("Got %v expected %v", err, nil)
+  }
+  if actualValue, expectedValue := decoded.Height(), 2; actualValue != expectedValue {
+    t.Errorf("Got %v expected %v, sorted input built balanced", actualValue, expectedValue)
+  }
+  if actualValue, expectedValue := decoded.Count(), 8; actualValue != expectedValue {
+    t.Errorf("Got %v expected %v", actualValue, expectedValue)
+  }
+  if err := decoded.Decode(strings.NewReader(`[{"key":2,"value":["a"]},{"key":1,"value":["b"]},{"key":2,"value":["c"]}]`)); err != nil {
+    t.Errorf("Got %v expected %v", err, nil)
+  }
+  if values, _ := decoded.Get(2); !slices.Equal(values, []string{"a", "c"}) {
+    t.Errorf("Got %v expected %v", values, []string{"a", "c"})
+  }
+  if err := decoded.Validate(); err != nil {
+    t.Errorf("Got %v expected %v", err, nil)
+  }
+}
+
+func TestBSTreeSizeAndCount(t *testing.T) {
+  tree := bstree.NewBSTree[int, string]()
+  assertSize := func(expectedSize, expectedCount int) {
+    t.Helper()
+    if actualValue := tree.Size(); actualValue != expectedSize {
+      t.Errorf("Got %v expected %v", actualValue, expectedSize)
+    }
+    if actualValue := tree.Count(); actualValue != expectedCount {
+      t.Errorf("Got %v expected %v", actualValue, expectedCount)
+    }
+    if actualValue := len(tree.Values()); actualValue != expectedCount {
+      t.Errorf("Got %v expected %v", actualValue, expectedCount)
+    }
+  }
+  assertSize(0, 0)
+  for key := 1; key <= 10; key++ {
+    tree.Insert(key, strconv.Itoa(key))
+  }
+  assertSize(10, 10)
+  tree.Insert(5, "five")
+  tree.Insert(5, "cinco")
+  assertSize(10, 12)
+  tree.RemoveOne(5)
+  assertSize(10, 11)
+  tree.Put(5, []string{"5", "five", "cinco", "V"})
+  assertSize(10, 13)
+  values := []string{"11", "eleven"}
+  tree.Put(11, values)
+  values[0] = "once"
+  assertSize(11, 15)
+  if stored, _ := tree.Get(11); stored[0] != "11" {
+    t.Errorf("Got %v expected %v", stored[0], "11")
+  }
+  tree.Put(12, nil)
+  tree.Put(11, []string{})
+  assertSize(10, 13)
+  tree.Put(11, []string{"11", "eleven"})
+  assertSize(11, 15)
+  tree.Remove(5)
+  tree.Remove(42)
+  tree.RemoveOne(42)
+  assertSize(10, 11)
+  tree.RemoveOne(1)
+  assertSize(9, 10)
+  data, err := tree.ToJSON()
+  if err != nil {
+    t.Fatalf("Got error %v", err)
+  }
+  tree.Clear()
+  assertSize(0, 0)
+  if err := tree.FromJSON(data); err != nil {
+    t.Fatalf("Got error %v", err)
+  }
+  assertSize(9, 10)
+}
+
+func TestBSTreeFloorCeilingLowerHigher(t *testing.T) {
+  tree := bstree.NewBSTree[int, string]()
+  if node, found := tree.Floor(1); node != nil || found {
+    t.Errorf("Got %v expected %v", node, nil)
+  }
+  if node, found := tree.Higher(1); node != nil || found {
+    t.Errorf("Got %v expected %v", node, nil)
+  }
+  for key := 2; key <= 20; key += 2 {
+    tree.Insert(key, strconv.Itoa(key))
+  }
+  key := func(node *bstree.BSTNode[int, string], found bool) int {
+    if !found {
+      return -1
+    }
+    return node.Key
+  }
+  // key, floor, ceiling, lower, higher (-1 is not found)
+  tests := [][]int{
+    {1, -1, 2, -1, 2},
+    {2, 2, 2, -1, 4},
+    {3, 2, 4, 2, 4},
+    {10, 10, 10, 8, 12},
+    {11, 10, 12, 10, 12},
+    {20, 20, 20, 18, -1},
+    {21, 20, -1, 20, -1},
+  }
+  for _, test := range tests {
+    if actual := key(tree.Floor(test[0])); actual != test[1] {
+      t.Errorf("Floor(%v) got %v expected %v", test[0], actual, test[1])
+    }
+    if actual := key(tree.Ceiling(test[0])); actual != test[2] {
+      t.Errorf("Ceiling(%v) got %v expected %v", test[0], actual, test[2])
+    }
+    if actual := key(tree.Lower(test[0])); actual != test[3] {
+      t.Errorf("Lower(%v) got %v expected %v", test[0], actual, test[3])
+    }
+    if actual := key(tree.Higher(test[0])); actual != test[4] {
+      t.Errorf("Higher(%v) got %v expected %v", test[0], actual, test[4])
+    }
+  }
+}
+
 func Example_bsTree() {
-  tree := bstree.NewBSTree(goutils.IntComparator, goutils.IntOperator)
+  tree := bstree.NewBSTree[int, string]()
 
 
   tree.Insert(5, "e")
@@ -19,13 +479,13 @@ func Example_bsTree() {
   tree.Insert(7, "g")
   tree.Insert(8, "h")
 
-  tree.PrintPreOrder()
-  tree.PrintInOrder()
-  tree.PrintPostOrder()
+  tree.PrintPreOrder(os.Stdout)
+  tree.PrintInOrder(os.Stdout)
+  tree.PrintPostOrder(os.Stdout)
 
   fmt.Println(tree.Ceiling(4))
   fmt.Println(tree.Floor(5))
-  tree.Print()
+  tree.Print(os.Stdout)
 
   fmt.Println(tree.Values())
   fmt.Println(tree.Keys())
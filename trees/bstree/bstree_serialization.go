@@ -1,34 +1,315 @@
 package bstree
 
 import (
+  "encoding";
+  "encoding/binary";
   "encoding/json";
-  "github.com/jenazads/goutils";
+  "fmt";
+  "io";
+  "github.com/fmorenovr/gods/trees";
+  "github.com/fmorenovr/gods/utils";
 )
 
 func assertSerializationImplementation() {
-  var _ goutils.JSONSerializer = (*BSTree)(nil)
-  var _ goutils.JSONDeserializer = (*BSTree)(nil)
+  var _ utils.JSONSerializer = (*BSTree[string, int])(nil)
+  var _ utils.JSONDeserializer = (*BSTree[string, int])(nil)
+  var _ json.Marshaler = (*BSTree[string, int])(nil)
+  var _ json.Unmarshaler = (*BSTree[string, int])(nil)
+  var _ encoding.BinaryMarshaler = (*BSTree[string, int])(nil)
+  var _ encoding.BinaryUnmarshaler = (*BSTree[string, int])(nil)
 }
 
 // ToJSON return JSON format of elements
-func (t *BSTree) ToJSON() ([]byte, error) {
-  elements := make(map[string]interface{})
+func (t *BSTree[K, V]) ToJSON() ([]byte, error) {
+  elements := make(map[K][]V)
   it := t.Iterator()
   for it.Next() {
-    elements[goutils.ToString(it.Key())] = it.Value()
+    elements[it.Key()] = it.Value()
   }
   return json.Marshal(&elements)
 }
 
 // FromJSON Convert to JSON format the elements
-func (t *BSTree) FromJSON(data []byte) error {
-  elements := make(map[string]interface{})
+func (t *BSTree[K, V]) FromJSON(data []byte) error {
+  elements := make(map[K][]V)
   err := json.Unmarshal(data, &elements)
   if err == nil {
     t.Clear()
-    for key, value := range elements {
-      t.Insert(key, value)
+    for key, values := range elements {
+      for _, value := range values {
+        t.Insert(key, value)
+      }
     }
   }
-  return err
+  return gotree.CorruptJSON(err)
+}
+
+// SetCodecs sets how the JSON and binary forms encode keys and each value, nil keeps the defaults
+// (encoding/json and utils.BinaryCodec). In JSON the codecs must write JSON values.
+func (t *BSTree[K, V]) SetCodecs(keys utils.Codec[K], values utils.Codec[V]) {
+  t.keyCodec, t.valueCodec = keys, values
+}
+
+// MarshalJSON writes the tree as [{"key":..,"value":[..]}, ...] in key order, every key with the
+// array of its values; keys keep their JSON type
+func (t *BSTree[K, V]) MarshalJSON() ([]byte, error) {
+  return gotree.MarshalEntries(t.All(), t.keyCodec, t.valuesCodec())
+}
+
+// UnmarshalJSON replaces the contents of the tree with the entries written by MarshalJSON.
+// Entries in key order, as MarshalJSON writes them, are built into a balanced tree in O(n);
+// others are inserted one by one. An error leaves the tree untouched. A zero-value tree, as
+// json.Unmarshal allocates it, orders keys with utils.DefaultComparator.
+func (t *BSTree[K, V]) UnmarshalJSON(data []byte) error {
+  comparator := t.comparator
+  if comparator == nil {
+    if comparator = utils.DefaultComparator[K](); comparator == nil {
+      return fmt.Errorf("bstree: no natural order for keys of type %T, build the tree with NewBSTreeWith", *new(K))
+    }
+  }
+  keys, values, err := gotree.UnmarshalEntries(data, t.keyCodec, t.valuesCodec())
+  if err != nil {
+    return err
+  }
+  loaded := &BSTree[K, V]{comparator: comparator, operator: t.operator}
+  if bstAscending(keys, comparator) {
+    nodes := make([]*BSTNode[K, V], len(keys))
+    for i, key := range keys {
+      nodes[i] = newNode(key, values[i])
+      loaded.count += len(values[i])
+    }
+    loaded.Root, loaded.size = bstBuild(nodes, nil), len(nodes)
+  } else {
+    for i, key := range keys {
+      for _, value := range values[i] {
+        loaded.Insert(key, value)
+      }
+    }
+  }
+  t.Root, t.size, t.count, t.comparator = loaded.Root, loaded.size, loaded.count, comparator
+  return nil
+}
+
+// MarshalBinary writes the tree in the binary form of gotree.MarshalBinaryEntries, in key order,
+// every key with the list of its values
+func (t *BSTree[K, V]) MarshalBinary() ([]byte, error) {
+  return gotree.MarshalBinaryEntries(gotree.BinaryHeader{Kind: "bstree", Count: t.size}, t.All(), t.keyCodec, binaryValuesCodec[V]{t.valueCodec})
+}
+
+// UnmarshalBinary replaces the contents of the tree with the entries written by MarshalBinary,
+// building a balanced tree from the sorted entries in O(n). Corrupt data is an error and leaves
+// the tree untouched. A zero-value tree orders keys with utils.DefaultComparator.
+func (t *BSTree[K, V]) UnmarshalBinary(data []byte) error {
+  comparator := t.comparator
+  if comparator == nil {
+    if comparator = utils.DefaultComparator[K](); comparator == nil {
+      return fmt.Errorf("bstree: no natural order for keys of type %T, build the tree with NewBSTreeWith", *new(K))
+    }
+  }
+  _, keys, values, err := gotree.UnmarshalBinaryEntries(data, "bstree", comparator, t.keyCodec, binaryValuesCodec[V]{t.valueCodec})
+  if err != nil {
+    return err
+  }
+  nodes := make([]*BSTNode[K, V], len(keys))
+  count := 0
+  for i, key := range keys {
+    nodes[i] = newNode(key, values[i])
+    count += len(values[i])
+  }
+  t.Root, t.size, t.count, t.comparator = bstBuild(nodes, nil), len(nodes), count, comparator
+  return nil
+}
+
+// Encode streams the tree to w in the JSON form of MarshalJSON, one key and its values at a time
+func (t *BSTree[K, V]) Encode(w io.Writer) error {
+  return gotree.EncodeEntries(w, t.All(), t.keyCodec, t.valuesCodec())
+}
+
+// Decode replaces the contents of the tree with the JSON form of MarshalJSON read from r one entry
+// at a time. Entries in key order, as Encode writes them, become nodes linked into a balanced tree
+// at the end; from the first entry out of order on, the values are inserted one by one. An error
+// leaves the tree untouched. A zero-value tree orders keys with utils.DefaultComparator.
+func (t *BSTree[K, V]) Decode(r io.Reader) error {
+  comparator := t.comparator
+  if comparator == nil {
+    if comparator = utils.DefaultComparator[K](); comparator == nil {
+      return fmt.Errorf("bstree: no natural order for keys of type %T, build the tree with NewBSTreeWith", *new(K))
+    }
+  }
+  loaded := &BSTree[K, V]{comparator: comparator, operator: t.operator}
+  var nodes []*BSTNode[K, V]
+  err := gotree.DecodeEntries(r, t.keyCodec, t.valuesCodec(), func(key K, values []V) error {
+    if loaded.Root == nil && (len(nodes) == 0 || comparator(nodes[len(nodes)-1].Key, key) < 0) {
+      nodes = append(nodes, newNode(key, values))
+      loaded.count += len(values)
+      return nil
+    }
+    if loaded.Root == nil {
+      loaded.Root, loaded.size = bstBuild(nodes, nil), len(nodes)
+    }
+    for _, value := range values {
+      loaded.Insert(key, value)
+    }
+    return nil
+  })
+  if err != nil {
+    return err
+  }
+  if loaded.Root == nil {
+    loaded.Root, loaded.size = bstBuild(nodes, nil), len(nodes)
+  }
+  t.Root, t.size, t.count, t.comparator = loaded.Root, loaded.size, loaded.count, comparator
+  return nil
+}
+
+// newNode returns a detached node holding values
+func newNode[K comparable, V any](key K, values []V) *BSTNode[K, V] {
+  node := NewBSTNode[K, V](key, nil)
+  node.Value, node.Count = values, len(values)
+  return node
+}
+
+// MarshalShape writes the exact layout of the tree as the level-order JSON array of
+// gotree.MarshalShape, every node with the array of its values: [{"key":2,"value":[..]},null,{..}]
+func (t *BSTree[K, V]) MarshalShape() ([]byte, error) {
+  return gotree.MarshalShape(t.Root, shape[K, V](), t.keyCodec, t.valuesCodec())
+}
+
+// UnmarshalShape replaces the tree with the layout written by MarshalShape, a degenerate tree
+// stays degenerate. The rebuilt tree must be a valid BST, otherwise it is an error and the tree is
+// left untouched. A zero-value tree orders keys with utils.DefaultComparator.
+func (t *BSTree[K, V]) UnmarshalShape(data []byte) error {
+  comparator := t.comparator
+  if comparator == nil {
+    if comparator = utils.DefaultComparator[K](); comparator == nil {
+      return fmt.Errorf("bstree: no natural order for keys of type %T, build the tree with NewBSTreeWith", *new(K))
+    }
+  }
+  root, size, err := gotree.UnmarshalShape(data, shape[K, V](), t.keyCodec, t.valuesCodec())
+  if err != nil {
+    return err
+  }
+  loaded := &BSTree[K, V]{Root: root, comparator: comparator, size: size, count: bstCount(root)}
+  if err := loaded.Validate(); err != nil {
+    return gotree.CorruptEntry(-1, -1, err)
+  }
+  t.Root, t.size, t.count, t.comparator = loaded.Root, loaded.size, loaded.count, comparator
+  return nil
+}
+
+// shape returns how gotree walks and rebuilds the nodes of a tree
+func shape[K comparable, V any]() gotree.Shape[*BSTNode[K, V], K, []V] {
+  return gotree.Shape[*BSTNode[K, V], K, []V]{
+    Children: func(node *BSTNode[K, V]) (*BSTNode[K, V], *BSTNode[K, V]) {
+      return node.Children[0], node.Children[1]
+    },
+    Node: func(node *BSTNode[K, V]) gotree.ShapeNode[K, []V] {
+      return gotree.ShapeNode[K, []V]{Key: node.Key, Value: node.Value}
+    },
+    New: func(s gotree.ShapeNode[K, []V]) (*BSTNode[K, V], error) {
+      return newNode(s.Key, s.Value), nil
+    },
+    Link: func(parent, left, right *BSTNode[K, V]) {
+      parent.Children = [2]*BSTNode[K, V]{left, right}
+      for _, child := range parent.Children {
+        if child != nil {
+          child.Parent = parent
+        }
+      }
+    },
+  }
+}
+
+// valuesCodec returns the codec of the values of a key, nil when values use encoding/json
+func (t *BSTree[K, V]) valuesCodec() utils.Codec[[]V] {
+  if t.valueCodec == nil {
+    return nil
+  }
+  return valuesCodec[V]{t.valueCodec}
+}
+
+// valuesCodec encodes a list of values as a JSON array, each value with codec
+type valuesCodec[V any] struct {
+  codec utils.Codec[V]
+}
+
+func (c valuesCodec[V]) Encode(values []V) ([]byte, error) {
+  raws := make([]json.RawMessage, len(values))
+  for i, value := range values {
+    data, err := c.codec.Encode(value)
+    if err != nil {
+      return nil, err
+    }
+    raws[i] = data
+  }
+  return json.Marshal(raws)
+}
+
+func (c valuesCodec[V]) Decode(data []byte) ([]V, error) {
+  var raws []json.RawMessage
+  if err := json.Unmarshal(data, &raws); err != nil {
+    return nil, err
+  }
+  values := make([]V, len(raws))
+  for i, raw := range raws {
+    value, err := c.codec.Decode(raw)
+    if err != nil {
+      return nil, err
+    }
+    values[i] = value
+  }
+  return values, nil
+}
+
+// binaryValuesCodec encodes a list of values as a uvarint count followed by every value,
+// a uvarint length and its bytes, each value with codec (utils.BinaryCodec when nil)
+type binaryValuesCodec[V any] struct {
+  codec utils.Codec[V]
+}
+
+func (c binaryValuesCodec[V]) Encode(values []V) ([]byte, error) {
+  codec := c.valueCodec()
+  data := binary.AppendUvarint(nil, uint64(len(values)))
+  for _, value := range values {
+    valueData, err := codec.Encode(value)
+    if err != nil {
+      return nil, err
+    }
+    data = binary.AppendUvarint(data, uint64(len(valueData)))
+    data = append(data, valueData...)
+  }
+  return data, nil
+}
+
+func (c binaryValuesCodec[V]) Decode(data []byte) ([]V, error) {
+  codec := c.valueCodec()
+  count, read := binary.Uvarint(data)
+  if read <= 0 || count > uint64(len(data)) {
+    return nil, fmt.Errorf("bstree: invalid count of values")
+  }
+  data = data[read:]
+  values := make([]V, count)
+  for i := range values {
+    n, read := binary.Uvarint(data)
+    if read <= 0 || n > uint64(len(data)-read) {
+      return nil, fmt.Errorf("bstree: value %v is truncated", i)
+    }
+    value, err := codec.Decode(data[read : read+int(n)])
+    if err != nil {
+      return nil, err
+    }
+    values[i] = value
+    data = data[read+int(n):]
+  }
+  if len(data) > 0 {
+    return nil, fmt.Errorf("bstree: %v bytes after the last value", len(data))
+  }
+  return values, nil
+}
+
+func (c binaryValuesCodec[V]) valueCodec() utils.Codec[V] {
+  if c.codec == nil {
+    return utils.BinaryCodec[V]{}
+  }
+  return c.codec
 }
@@ -0,0 +1,56 @@
+package bstree
+
+import (
+  "fmt";
+)
+
+// Validate checks the BST invariants: key order, parent pointers, that every node
+// Count matches its values, and the number of keys and values of the tree. It returns
+// nil for a sound tree or an error naming the first offending node.
+func (t *BSTree[K, V]) Validate() error {
+  if t.Root != nil && t.Root.Parent != nil {
+    return fmt.Errorf("bstree: root %v has parent %v", t.Root.Key, t.Root.Parent.Key)
+  }
+  size, count, err := t.validate(t.Root, nil, nil)
+  if err != nil {
+    return err
+  }
+  if size != t.size {
+    return fmt.Errorf("bstree: tree size is %v but it holds %v nodes", t.size, size)
+  }
+  if count != t.count {
+    return fmt.Errorf("bstree: tree count is %v but it holds %v values", t.count, count)
+  }
+  return nil
+}
+
+// validate checks the subtree of node, whose keys must lie strictly between low and high
+// (nil is unbounded), and returns its number of nodes and values
+func (t *BSTree[K, V]) validate(node, low, high *BSTNode[K, V]) (size, count int, err error) {
+  if node == nil {
+    return 0, 0, nil
+  }
+  if low != nil && t.comparator(node.Key, low.Key) <= 0 {
+    return 0, 0, fmt.Errorf("bstree: node %v is not greater than its ancestor %v", node.Key, low.Key)
+  }
+  if high != nil && t.comparator(node.Key, high.Key) >= 0 {
+    return 0, 0, fmt.Errorf("bstree: node %v is not smaller than its ancestor %v", node.Key, high.Key)
+  }
+  if node.Count != len(node.Value) {
+    return 0, 0, fmt.Errorf("bstree: node %v has Count %v but holds %v values", node.Key, node.Count, len(node.Value))
+  }
+  for _, child := range node.Children {
+    if child != nil && child.Parent != node {
+      return 0, 0, fmt.Errorf("bstree: node %v has child %v whose parent is not %v", node.Key, child.Key, node.Key)
+    }
+  }
+  leftSize, leftCount, err := t.validate(node.Children[0], low, node)
+  if err != nil {
+    return 0, 0, err
+  }
+  rightSize, rightCount, err := t.validate(node.Children[1], node, high)
+  if err != nil {
+    return 0, 0, err
+  }
+  return leftSize + rightSize + 1, leftCount + rightCount + node.Count, nil
+}
@@ -2,15 +2,17 @@ package bstree
 
 import (
   "fmt";
-  "github.com/jenazads/goutils";
-  "github.com/jenazads/gods/trees";
+  "io";
+  "github.com/fmorenovr/gods/utils";
+  "github.com/fmorenovr/gods/trees";
 )
 
 func assertTreeImplementation() {
-  var _ gotree.GoTree = new(BSTree)
+  var _ gotree.GoTree[int] = new(BSTree[string, int])
+  var _ gotree.RangeMap[string, []int] = new(BSTree[string, int])
 }
 
-func bstInsert(root *BSTNode, key interface{}, value interface{}, parent *BSTNode, comp goutils.TypeComparator) *BSTNode {
+func bstInsert[K comparable, V any](root *BSTNode[K, V], key K, value V, parent *BSTNode[K, V], comp utils.Comparator[K]) *BSTNode[K, V] {
   if root==nil {
     aux:=NewBSTNode(key, parent)
     aux.Value = append(aux.Value, value)
@@ -19,9 +21,9 @@ func bstInsert(root *BSTNode, key interface{}, value interface{}, parent *BSTNod
     root=aux;
     return root
   }
-  if comp(key, root.Key) == -1 {
+  if comp(key, root.Key) < 0 {
     root.Children[0]=bstInsert(root.Children[0], key, value, root, comp);
-  } else if comp(key, root.Key) == 1 {
+  } else if comp(key, root.Key) > 0 {
     root.Children[1]=bstInsert(root.Children[1], key, value, root, comp);
   } else if comp(key, root.Key) == 0 {
     root.Value = append(root.Value, value)
@@ -30,26 +32,68 @@ func bstInsert(root *BSTNode, key interface{}, value interface{}, parent *BSTNod
   return root;
 }
 
-func bstTransplant(u, v *BSTNode) (*BSTNode) {
+// bstBuild links the sorted nodes into a balanced tree, the middle node at the root, and returns it
+func bstBuild[K comparable, V any](nodes []*BSTNode[K, V], parent *BSTNode[K, V]) *BSTNode[K, V] {
+  if len(nodes) == 0 {
+    return nil
+  }
+  middle := len(nodes) / 2
+  node := nodes[middle]
+  node.Parent = parent
+  node.Children[0] = bstBuild(nodes[:middle], node)
+  node.Children[1] = bstBuild(nodes[middle+1:], node)
+  return node
+}
+
+// bstAscending reports whether keys are in strictly ascending order, as bstBuild needs them
+func bstAscending[K comparable](keys []K, comp utils.Comparator[K]) bool {
+  for i := 1; i < len(keys); i++ {
+    if comp(keys[i-1], keys[i]) >= 0 {
+      return false
+    }
+  }
+  return true
+}
+
+func bstPut[K comparable, V any](root *BSTNode[K, V], key K, values []V, parent *BSTNode[K, V], comp utils.Comparator[K]) *BSTNode[K, V] {
+  if root==nil {
+    root=NewBSTNode(key, parent)
+    root.Value = values
+    root.Count = len(values)
+    return root
+  }
+  if comp(key, root.Key) < 0 {
+    root.Children[0]=bstPut(root.Children[0], key, values, root, comp);
+  } else if comp(key, root.Key) > 0 {
+    root.Children[1]=bstPut(root.Children[1], key, values, root, comp);
+  } else if comp(key, root.Key) == 0 {
+    root.Value = values
+    root.Count = len(values)
+  }
+  return root;
+}
+
+func bstTransplant[K comparable, V any](u, v *BSTNode[K, V]) (*BSTNode[K, V]) {
   v.Parent = u.Parent
   u=v
   return u;
 }
 
-func bstRemove(root *BSTNode, key interface{}, comp goutils.TypeComparator) *BSTNode {
+// bstRemove removes one value of key, or the whole node if all is true
+func bstRemove[K comparable, V any](root *BSTNode[K, V], key K, all bool, comp utils.Comparator[K]) *BSTNode[K, V] {
   if root == nil {
     return root;
   }
   
-  if comp(key, root.Key) == -1 {
-    root.Children[0]=bstRemove(root.Children[0], key, comp);
-  } else if comp(key, root.Key) == 1 {
-    root.Children[1]=bstRemove(root.Children[1], key, comp);
+  if comp(key, root.Key) < 0 {
+    root.Children[0]=bstRemove(root.Children[0], key, all, comp);
+  } else if comp(key, root.Key) > 0 {
+    root.Children[1]=bstRemove(root.Children[1], key, all, comp);
   } else if comp(key, root.Key) == 0 {
-    if root.Count > 1 {
+    if root.Count > 1 && !all {
       root.Value = root.Value[:len(root.Value)-1]
       root.Count = root.Count - 1
-    } else if root.Count == 1 {
+    } else {
       // sin hijos
       if (root.Children[0] == nil) && (root.Children[1] == nil) {
         root = nil
@@ -60,7 +104,9 @@ func bstRemove(root *BSTNode, key interface{}, comp goutils.TypeComparator) *BST
       }else { // 2 hijos agarra el minimo del arbol derecho
         temp:= bstFindNode(root.Children[1], 0)
         root.Key=temp.Key;
-        root.Children[1]=bstRemove(root.Children[1], temp.Key, comp);
+        root.Value=temp.Value;
+        root.Count=temp.Count;
+        root.Children[1]=bstRemove(root.Children[1], temp.Key, true, comp);
       }
     }
   }
@@ -68,14 +114,14 @@ func bstRemove(root *BSTNode, key interface{}, comp goutils.TypeComparator) *BST
   return root;
 }
 
-func bstSearch(root *BSTNode, key interface{}, comp goutils.TypeComparator) (*BSTNode){
+func bstSearch[K comparable, V any](root *BSTNode[K, V], key K, comp utils.Comparator[K]) (*BSTNode[K, V]){
   if root==nil {
     return nil;
   } else {
     curr_node:=root;
-    if comp(curr_node.Key, key) == 1 {
+    if comp(curr_node.Key, key) > 0 {
       return bstSearch(curr_node.Children[0], key, comp);
-    } else if comp(curr_node.Key, key) == -1{
+    } else if comp(curr_node.Key, key) < 0{
       return bstSearch(curr_node.Children[1], key, comp);
     } else{
       return curr_node;
@@ -83,7 +129,48 @@ func bstSearch(root *BSTNode, key interface{}, comp goutils.TypeComparator) (*BS
   }
 }
 
-func bstHeight(root *BSTNode) int {
+// bstFloor returns the largest node smaller than key (or equal to it if inclusive)
+func bstFloor[K comparable, V any](root *BSTNode[K, V], key K, inclusive bool, comp utils.Comparator[K]) (*BSTNode[K, V]) {
+  var floor *BSTNode[K, V]
+  for root != nil {
+    compare := comp(key, root.Key)
+    if compare == 0 && inclusive {
+      return root
+    } else if compare <= 0 {
+      root = root.Children[0]
+    } else {
+      floor = root
+      root = root.Children[1]
+    }
+  }
+  return floor
+}
+
+// bstCeiling returns the smallest node larger than key (or equal to it if inclusive)
+func bstCeiling[K comparable, V any](root *BSTNode[K, V], key K, inclusive bool, comp utils.Comparator[K]) (*BSTNode[K, V]) {
+  var ceiling *BSTNode[K, V]
+  for root != nil {
+    compare := comp(key, root.Key)
+    if compare == 0 && inclusive {
+      return root
+    } else if compare < 0 {
+      ceiling = root
+      root = root.Children[0]
+    } else {
+      root = root.Children[1]
+    }
+  }
+  return ceiling
+}
+
+func bstEntry[K comparable, V any](node *BSTNode[K, V]) (key K, values []V, found bool) {
+  if node == nil {
+    return key, nil, false
+  }
+  return node.Key, node.Value, true
+}
+
+func bstHeight[K comparable, V any](root *BSTNode[K, V]) int {
   if root!=nil {
     var a,b int;
     curr_node:=root;
@@ -99,15 +186,15 @@ func bstHeight(root *BSTNode) int {
   }
 }
 
-func bstSize(root *BSTNode) int{
-  if root==nil {
-    return 0;
-  } else {
-    return (bstSize(root.Children[0]) + bstSize(root.Children[1]) + 1);
+// bstCount returns the number of values under node
+func bstCount[K comparable, V any](node *BSTNode[K, V]) int {
+  if node == nil {
+    return 0
   }
+  return node.Count + bstCount(node.Children[0]) + bstCount(node.Children[1])
 }
 
-func bstLeafCount(root *BSTNode) int {
+func bstLeafCount[K comparable, V any](root *BSTNode[K, V]) int {
   if root == nil {
     return 0;
   }
@@ -118,7 +205,7 @@ func bstLeafCount(root *BSTNode) int {
   }
 }
 
-func bstFindNode(root *BSTNode, child int) *BSTNode {
+func bstFindNode[K comparable, V any](root *BSTNode[K, V], child int) *BSTNode[K, V] {
   if root == nil {
     return nil;
   }
@@ -129,81 +216,105 @@ func bstFindNode(root *BSTNode, child int) *BSTNode {
   return curr_node;
 }
 
-func bstSumNodes(root *BSTNode, op goutils.TypeOperator) interface{}{
-  var sum interface{} = 0
+func bstSumNodes[K comparable, V any](root *BSTNode[K, V], op utils.Operator[K]) K {
+  var sum K
   if root!=nil {
     sumLeft  := bstSumNodes(root.Children[0], op)
     sumRight := bstSumNodes(root.Children[1], op)
-    sum=op(op(sumLeft, root.Key ,"+"), sumRight, "+");
+    sum=op(op(sumLeft, root.Key), sumRight);
   }
   return sum
 }
 
-func bstHeightOfNode(root *BSTNode, key interface{}, comp goutils.TypeComparator) int {
+func bstHeightOfNode[K comparable, V any](root *BSTNode[K, V], key K, comp utils.Comparator[K]) (int, bool) {
   height:=0;
   curr_node:=root;
   for curr_node!=nil {
     if comp(key, curr_node.Key) == 0 {
-      return height;
+      return height, true;
     } else {
       height++;
-      if comp(key, curr_node.Key) == -1 {
+      if comp(key, curr_node.Key) < 0 {
         curr_node = curr_node.Children[0];
-      } else if comp(key, curr_node.Key) == 1 {
+      } else if comp(key, curr_node.Key) > 0 {
         curr_node = curr_node.Children[1];
       }
     }
   }
-  return -1;
+  return -1, false;
 }
 
-func bstPrintPreorder(root *BSTNode) {
-  if root != nil {
-    fmt.Printf("%v ", root.Key);
-    bstPrintPreorder(root.Children[0]);
-    bstPrintPreorder(root.Children[1]);
+// bstWalk visits root depth-first in order, false if the visitor stopped the walk
+func bstWalk[K comparable, V any](root *BSTNode[K, V], order gotree.TraversalOrder, visitor func(K, []V) bool) bool {
+  if root == nil {
+    return true;
   }
+  if order == gotree.PreOrder && !visitor(root.Key, root.Value) {
+    return false;
+  }
+  if !bstWalk(root.Children[0], order, visitor) {
+    return false;
+  }
+  if order == gotree.InOrder && !visitor(root.Key, root.Value) {
+    return false;
+  }
+  if !bstWalk(root.Children[1], order, visitor) {
+    return false;
+  }
+  return order != gotree.PostOrder || visitor(root.Key, root.Value);
 }
 
-func bstPrintInorder(root *BSTNode) {
-  if root != nil {
-    bstPrintInorder(root.Children[0]);
-    fmt.Printf("%v ", root.Key);
-    bstPrintInorder(root.Children[1]);
+// bstWalkLevels visits root breadth-first, until the visitor returns false
+func bstWalkLevels[K comparable, V any](root *BSTNode[K, V], visitor func(K, []V) bool) {
+  if root == nil {
+    return;
+  }
+  queue := []*BSTNode[K, V]{root}
+  for len(queue) > 0 {
+    node := queue[0]
+    queue = queue[1:]
+    if !visitor(node.Key, node.Value) {
+      return;
+    }
+    for _, child := range node.Children {
+      if child != nil {
+        queue = append(queue, child)
+      }
+    }
   }
 }
 
-func bstPrintPostorder(root *BSTNode) {
-  if root != nil {
-    bstPrintPostorder(root.Children[0]);
-    bstPrintPostorder(root.Children[1]);
-    fmt.Printf("%v ", root.Key);
-  }
+func bstPrintOrder[K comparable, V any](w io.Writer, t *BSTree[K, V], order gotree.TraversalOrder) {
+  t.Walk(order, func(key K, _ []V) bool {
+    fmt.Fprintf(w, "%v ", key);
+    return true;
+  })
+  fmt.Fprintln(w);
 }
 
-func getParentNode(root *BSTNode, key interface{}, comp goutils.TypeComparator) *BSTNode{
+func getParentNode[K comparable, V any](root *BSTNode[K, V], key K, comp utils.Comparator[K]) (*BSTNode[K, V], bool) {
   curr_node:=bstSearch(root, key, comp);
-  if curr_node!=nil {
-    return curr_node.Parent;
-  } else{
-    return nil;
+  if curr_node==nil {
+    return nil, false;
   }
+  return curr_node.Parent, true;
 }
 
-func getBrotherNode(root *BSTNode, key interface{}, comp goutils.TypeComparator) *BSTNode{
+func getBrotherNode[K comparable, V any](root *BSTNode[K, V], key K, comp utils.Comparator[K]) (*BSTNode[K, V], bool) {
   curr_node:=bstSearch(root, key, comp);
-  if curr_node!=nil && curr_node.Parent!=nil && curr_node.Parent.Children[1]==curr_node {
-    return curr_node.Parent.Children[0]
-  } else if curr_node!=nil && curr_node.Parent!=nil && curr_node.Parent.Children[0]==curr_node {
-    return curr_node.Parent.Children[1]
-  } else if curr_node!=nil && curr_node.Parent==nil {
-    return nil;
-  } else{
-    return nil;
+  if curr_node==nil {
+    return nil, false;
+  }
+  if curr_node.Parent==nil {
+    return nil, true;
+  }
+  if curr_node.Parent.Children[1]==curr_node {
+    return curr_node.Parent.Children[0], true;
   }
+  return curr_node.Parent.Children[1], true;
 }
 
-func bstFindNeighbourNode(root *BSTNode,child int) *BSTNode {
+func bstFindNeighbourNode[K comparable, V any](root *BSTNode[K, V],child int) *BSTNode[K, V] {
   if root==nil {
     return nil
   }
@@ -218,7 +329,7 @@ func bstFindNeighbourNode(root *BSTNode,child int) *BSTNode {
   return curr_node;
 }
 
-func bstMirror(root *BSTNode) {
+func bstMirror[K comparable, V any](root *BSTNode[K, V]) {
   if root !=nil {
     bstMirror(root.Children[0]);
     bstMirror(root.Children[1]);
@@ -228,7 +339,7 @@ func bstMirror(root *BSTNode) {
   }
 }
 
-func bstIsSameAs(a,b *BSTNode) bool {
+func bstIsSameAs[K comparable, V any](a,b *BSTNode[K, V]) bool {
   if a==nil && b==nil {
     return true;
   } else if a!=nil && b!=nil && a.Key == b.Key {
@@ -238,7 +349,7 @@ func bstIsSameAs(a,b *BSTNode) bool {
   }
 }
 
-func bstTreePrint(node *BSTNode, prefix string, isTail bool, str *string) {
+func bstTreePrint[K comparable, V any](node *BSTNode[K, V], prefix string, isTail bool, str *string) {
   if node.Children[1] != nil {
     newPrefix := prefix
     if node.Parent == nil {
@@ -278,22 +389,22 @@ func bstTreePrint(node *BSTNode, prefix string, isTail bool, str *string) {
   }
 }
 
-func bstTreePrintLevels(root *BSTNode) {
+func bstTreePrintLevels[K comparable, V any](w io.Writer, root *BSTNode[K, V]) {
   height:=bstHeight(root)+1;
   for i := 0;i < height;i++ {
-    fmt.Printf("\nNIVEL %v  :", i);
-    bstPrintNodeAtLevel(root, i, 0);
-    fmt.Println();
+    fmt.Fprintf(w, "\nNIVEL %v  :", i);
+    bstPrintNodeAtLevel(w, root, i, 0);
+    fmt.Fprintln(w);
   }
 }
 
-func bstPrintNodeAtLevel(root *BSTNode, height, level int) {
+func bstPrintNodeAtLevel[K comparable, V any](w io.Writer, root *BSTNode[K, V], height, level int) {
   if root!=nil {
     if(height==level) {
-      fmt.Printf("\t%v", root.Key)
+      fmt.Fprintf(w, "\t%v", root.Key)
     } else {
-      bstPrintNodeAtLevel(root.Children[0], height, level+1);
-      bstPrintNodeAtLevel(root.Children[1], height, level+1);
+      bstPrintNodeAtLevel(w, root.Children[0], height, level+1);
+      bstPrintNodeAtLevel(w, root.Children[1], height, level+1);
     }
   }
 }
@@ -2,66 +2,100 @@ package btree
 
 import (
   "bytes"
+  "cmp"
   "fmt"
-  "github.com/jenazads/goutils"
+  "iter"
+  "github.com/fmorenovr/gods/trees"
+  "github.com/fmorenovr/gods/utils"
   "strings"
 )
 
+// DefaultOrder is the order a zero-value tree takes when JSON is decoded into it
+const DefaultOrder = 32
+
 // B-Tree object
-type BTree struct {
-  Root       *BNode                  // Root node
-  comparator goutils.TypeComparator  // Key comparator
-  size       int                     // Total number of keys in the tree
-  m          int                     // order (maximum number of children)
+type BTree[K comparable, V any] struct {
+  Root       *BNode[K, V]      // Root node
+  comparator utils.Comparator[K]  // Key comparator
+  size       int               // Total number of keys in the tree
+  m          int               // order (maximum number of children)
+  tracer     gotree.Tracer     // Receives operations, splits and rebalancing, nil when not tracing
+  keyCodec   utils.Codec[K]    // Encodes keys in the JSON and binary forms, nil for the default
+  valueCodec utils.Codec[V]    // Encodes values in the JSON and binary forms, nil for the default
 }
 
 // Node
-type BNode struct {
-  Parent   *BNode
-  Entries  []*Entry  // Contained keys in node
-  Children []*BNode   // Children nodes
+type BNode[K comparable, V any] struct {
+  Parent   *BNode[K, V]
+  Entries  []*Entry[K, V]  // Contained keys in node
+  Children []*BNode[K, V]  // Children nodes
+}
+
+// New B Tree with the natural order of K
+func NewBTree[K cmp.Ordered, V any](order int) *BTree[K, V] {
+  return NewBTreeWith[K, V](order, utils.OrderedComparator[K]())
 }
 
-// New B Tree
-func NewBTree(order int, comp goutils.TypeComparator) *BTree {
+// New B Tree with a custom comparator, panics on an order below 3
+func NewBTreeWith[K comparable, V any](order int, comp utils.Comparator[K]) *BTree[K, V] {
+  t, err := New[K, V](order, comp)
+  if err != nil {
+    panic(err)
+  }
+  return t
+}
+
+// New B Tree with a custom comparator, an order below 3 is an error wrapping gotree.ErrInvalidOrder
+func New[K comparable, V any](order int, comp utils.Comparator[K]) (*BTree[K, V], error) {
   if order < 3 {
-    panic("Invalid order, should be at least 3")
+    return nil, invalidOrder(order)
   }
-  return &BTree{m: order, comparator: comp}
+  return &BTree[K, V]{m: order, comparator: comp}, nil
+}
+
+// invalidOrder is the error of a B-tree order below 3
+func invalidOrder(order int) error {
+  return fmt.Errorf("btree: %w %v, should be at least 3", gotree.ErrInvalidOrder, order)
 }
 
 // New BTree Node
-func NewBNode(p *BNode, entry []*Entry, child []*BNode) (*BNode) {
-  return &BNode{Parent: p, Entries: entry, Children: child}
+func NewBNode[K comparable, V any](p *BNode[K, V], entry []*Entry[K, V], child []*BNode[K, V]) (*BNode[K, V]) {
+  return &BNode[K, V]{Parent: p, Entries: entry, Children: child}
 }
 
 // New Entry
-func NewEntry(key, value interface{}) *Entry{
-  return &Entry{Key: key, Value: value}
+func NewEntry[K comparable, V any](key K, value V) *Entry[K, V]{
+  return &Entry[K, V]{Key: key, Value: value}
 }
 
 // IsEmpty, true if tree doesnt have nodes
-func (t *BTree) IsEmpty() bool {
+func (t *BTree[K, V]) IsEmpty() bool {
   return t.size == 0
 }
 
 // Return true if the node is leaf
-func IsLeaf(node *BNode) bool {
+func IsLeaf[K comparable, V any](node *BNode[K, V]) bool {
   return len(node.Children) == 0
 }
 
 // Removes all nodes
-func (t *BTree) Clear() {
+func (t *BTree[K, V]) Clear() {
   t.Root = nil
   t.size = 0
 }
 
 // Put inserts key-value pair node into the tree
-func (t *BTree) Put(key interface{}, value interface{}) {
+func (t *BTree[K, V]) Put(key K, value V) {
+  if utils.Debug {
+    defer t.debugCheck("Put", key)
+  }
+  if t.tracer != nil {
+    t.tracer.Trace(gotree.Operation("btree", "Put", key).WithValue(value))
+  }
   entry := NewEntry(key, value)
 
   if t.Root == nil {
-    t.Root = NewBNode(nil, []*Entry{entry}, []*BNode{})
+    t.Root = NewBNode(nil, []*Entry[K, V]{entry}, []*BNode[K, V]{})
     t.size++
     return
   }
@@ -72,7 +106,13 @@ func (t *BTree) Put(key interface{}, value interface{}) {
 }
 
 // Remove Node by key
-func (t *BTree) Remove(key interface{}) {
+func (t *BTree[K, V]) Remove(key K) {
+  if utils.Debug {
+    defer t.debugCheck("Remove", key)
+  }
+  if t.tracer != nil {
+    t.tracer.Trace(gotree.Operation("btree", "Remove", key))
+  }
   node, index, found := t.searchRecursively(t.Root, key)
   if found {
     t.delete(node, index)
@@ -80,38 +120,55 @@ func (t *BTree) Remove(key interface{}) {
   }
 }
 
-// Get Value
-func (t *BTree) Get(key interface{}) (interface{}) {
+// SetTracer sends every Put and Remove to tracer, followed by the node splits, rotations
+// through the parent and merges they cause; nil stops tracing
+func (t *BTree[K, V]) SetTracer(tracer gotree.Tracer) {
+  t.tracer = tracer
+}
+
+// Get searches the key and returns its value, found is false if the key is not in the tree
+func (t *BTree[K, V]) Get(key K) (value V, found bool) {
   node, index, found := t.searchRecursively(t.Root, key)
   if found {
-    return node.Entries[index].Value
+    return node.Entries[index].Value, true
   }
-  return nil
+  return value, false
+}
+
+// Contains returns true if the key is in the tree
+func (t *BTree[K, V]) Contains(key K) bool {
+  _, _, found := t.searchRecursively(t.Root, key)
+  return found
 }
 
 // Returns the height
-func (t *BTree) Height() int {
+func (t *BTree[K, V]) Height() int {
   return t.Root.height()
 }
 
 // Return Size of tree
-func (t *BTree) Size() int {
+func (t *BTree[K, V]) Size() int {
+  return t.size
+}
+
+// Len returns the number of keys in the tree, same as Size
+func (t *BTree[K, V]) Len() int {
   return t.size
 }
 
 // Return minimum element
-func (t *BTree) Left() *BNode {
+func (t *BTree[K, V]) Left() *BNode[K, V] {
   return t.left(t.Root)
 }
 
 // Return maximum element
-func (t *BTree) Right() *BNode {
+func (t *BTree[K, V]) Right() *BNode[K, V] {
   return t.right(t.Root)
 }
 
 // Keys returns all keys in-order
-func (t *BTree) Keys() []interface{} {
-  keys := make([]interface{}, t.size)
+func (t *BTree[K, V]) Keys() []K {
+  keys := make([]K, t.size)
   it := t.Iterator()
   for i := 0; it.Next(); i++ {
     keys[i] = it.Key()
@@ -120,8 +177,8 @@ func (t *BTree) Keys() []interface{} {
 }
 
 // Values returns all values in-order based on the key.
-func (t *BTree) Values() []interface{} {
-  values := make([]interface{}, t.size)
+func (t *BTree[K, V]) Values() []V {
+  values := make([]V, t.size)
   it := t.Iterator()
   for i := 0; it.Next(); i++ {
     values[i] = it.Value()
@@ -129,40 +186,128 @@ func (t *BTree) Values() []interface{} {
   return values
 }
 
-// LeftKey returns the left-most (min) key or nil if tree is empty.
-func (t *BTree) LeftKey() interface{} {
+// LeftKey returns the left-most (min) key or the zero value if tree is empty.
+func (t *BTree[K, V]) LeftKey() (key K) {
   if left := t.Left(); left != nil {
     return left.Entries[0].Key
   }
-  return nil
+  return key
 }
 
-// LeftValue returns the left-most value or nil if tree is empty.
-func (t *BTree) LeftValue() interface{} {
+// LeftValue returns the left-most value or the zero value if tree is empty.
+func (t *BTree[K, V]) LeftValue() (value V) {
   if left := t.Left(); left != nil {
     return left.Entries[0].Value
   }
-  return nil
+  return value
 }
 
-// RightKey returns the right-most (max) key or nil if tree is empty.
-func (t *BTree) RightKey() interface{} {
+// RightKey returns the right-most (max) key or the zero value if tree is empty.
+func (t *BTree[K, V]) RightKey() (key K) {
   if right := t.Right(); right != nil {
     return right.Entries[len(right.Entries)-1].Key
   }
-  return nil
+  return key
 }
 
-// RightValue returns the right-most value or nil if tree is empty.
-func (t *BTree) RightValue() interface{} {
+// RightValue returns the right-most value or the zero value if tree is empty.
+func (t *BTree[K, V]) RightValue() (value V) {
   if right := t.Right(); right != nil {
     return right.Entries[len(right.Entries)-1].Value
   }
-  return nil
+  return value
+}
+
+// Min returns the minimum key and its value
+func (t *BTree[K, V]) Min() (key K, value V, found bool) {
+  if left := t.Left(); left != nil {
+    return left.Entries[0].Key, left.Entries[0].Value, true
+  }
+  return key, value, false
+}
+
+// Max returns the maximum key and its value
+func (t *BTree[K, V]) Max() (key K, value V, found bool) {
+  if right := t.Right(); right != nil {
+    entry := right.Entries[len(right.Entries)-1]
+    return entry.Key, entry.Value, true
+  }
+  return key, value, false
+}
+
+// Floor returns the entry with the largest key smaller than or equal to the given key, found is false if there is none
+func (t *BTree[K, V]) Floor(key K) (floor *Entry[K, V], found bool) {
+  _, floor = t.floor(key, true)
+  return floor, floor != nil
+}
+
+// Ceiling returns the entry with the smallest key larger than or equal to the given key, found is false if there is none
+func (t *BTree[K, V]) Ceiling(key K) (ceiling *Entry[K, V], found bool) {
+  _, ceiling = t.ceiling(key, true)
+  return ceiling, ceiling != nil
+}
+
+// Lower returns the entry with the largest key strictly smaller than the given key, found is false if there is none
+func (t *BTree[K, V]) Lower(key K) (lower *Entry[K, V], found bool) {
+  _, lower = t.floor(key, false)
+  return lower, lower != nil
+}
+
+// Higher returns the entry with the smallest key strictly larger than the given key, found is false if there is none
+func (t *BTree[K, V]) Higher(key K) (higher *Entry[K, V], found bool) {
+  _, higher = t.ceiling(key, false)
+  return higher, higher != nil
+}
+
+// FloorEntry returns the largest key smaller than or equal to the given key and its value
+func (t *BTree[K, V]) FloorEntry(key K) (floor K, value V, found bool) {
+  if _, entry := t.floor(key, true); entry != nil {
+    return entry.Key, entry.Value, true
+  }
+  return floor, value, false
+}
+
+// CeilingEntry returns the smallest key larger than or equal to the given key and its value
+func (t *BTree[K, V]) CeilingEntry(key K) (ceiling K, value V, found bool) {
+  if _, entry := t.ceiling(key, true); entry != nil {
+    return entry.Key, entry.Value, true
+  }
+  return ceiling, value, false
+}
+
+// Walk visits every entry in the given order until visitor returns false.
+// PreOrder visits the entries of a node before its children, PostOrder after them,
+// LevelOrder visits the nodes level by level from the root.
+func (t *BTree[K, V]) Walk(order gotree.TraversalOrder, visitor func(key K, value V) bool) {
+  if t.Root == nil {
+    return
+  }
+  if order != gotree.LevelOrder {
+    t.walk(t.Root, order, visitor)
+    return
+  }
+  queue := []*BNode[K, V]{t.Root}
+  for len(queue) > 0 {
+    node := queue[0]
+    queue = queue[1:]
+    for _, entry := range node.Entries {
+      if !visitor(entry.Key, entry.Value) {
+        return
+      }
+    }
+    queue = append(queue, node.Children...)
+  }
+}
+
+// Traverse returns the key/value pairs in the given order as a range-over-func sequence
+func (t *BTree[K, V]) Traverse(order gotree.TraversalOrder) iter.Seq2[K, V] {
+  return func(yield func(K, V) bool) {
+    t.Walk(order, yield)
+  }
 }
 
 // String returns a string representation of container (for debugging purposes)
-func (t *BTree) String() string {
+func (t *BTree[K, V]) String() string {
   var buffer bytes.Buffer
   if _, err := buffer.WriteString("BTree\n"); err != nil {
   }
@@ -172,11 +317,15 @@ func (t *BTree) String() string {
   return buffer.String()
 }
 
-func (entry *Entry) String() string {
+func (entry *Entry[K, V]) String() string {
   return fmt.Sprintf("%v", entry.Key)
 }
 
-func (t *BTree) output(buffer *bytes.Buffer, node *BNode, level int, isTail bool) {
+func (node *BNode[K, V]) String() string {
+  return fmt.Sprint(node.Entries)
+}
+
+func (t *BTree[K, V]) output(buffer *bytes.Buffer, node *BNode[K, V], level int, isTail bool) {
   for e := 0; e < len(node.Entries)+1; e++ {
     if e < len(node.Children) {
       t.output(buffer, node.Children[e], level+1, true)
@@ -190,7 +339,34 @@ func (t *BTree) output(buffer *bytes.Buffer, node *BNode, level int, isTail bool
   }
 }
 
-func (node *BNode) height() int {
+// walk visits node depth-first in order, false if the visitor stopped the walk
+func (t *BTree[K, V]) walk(node *BNode[K, V], order gotree.TraversalOrder, visitor func(K, V) bool) bool {
+  visitEntries := func() bool {
+    for _, entry := range node.Entries {
+      if !visitor(entry.Key, entry.Value) {
+        return false
+      }
+    }
+    return true
+  }
+  if order == gotree.PreOrder && !visitEntries() {
+    return false
+  }
+  for i, child := range node.Children {
+    if !t.walk(child, order, visitor) {
+      return false
+    }
+    if order == gotree.InOrder && i < len(node.Entries) && !visitor(node.Entries[i].Key, node.Entries[i].Value) {
+      return false
+    }
+  }
+  if order == gotree.InOrder && IsLeaf(node) && !visitEntries() {
+    return false
+  }
+  return order != gotree.PostOrder || visitEntries()
+}
+
+func (node *BNode[K, V]) height() int {
   height := 0
   for ; node != nil; node = node.Children[0] {
     height++
@@ -201,36 +377,36 @@ func (node *BNode) height() int {
   return height
 }
 
-func (t *BTree) isFull(node *BNode) bool {
+func (t *BTree[K, V]) isFull(node *BNode[K, V]) bool {
   return len(node.Entries) == t.maxEntries()
 }
 
-func (t *BTree) shouldSplit(node *BNode) bool {
+func (t *BTree[K, V]) shouldSplit(node *BNode[K, V]) bool {
   return len(node.Entries) > t.maxEntries()
 }
 
-func (t *BTree) maxChildren() int {
+func (t *BTree[K, V]) maxChildren() int {
   return t.m
 }
 
-func (t *BTree) minChildren() int {
+func (t *BTree[K, V]) minChildren() int {
   return (t.m + 1) / 2 // ceil(m/2)
 }
 
-func (t *BTree) maxEntries() int {
+func (t *BTree[K, V]) maxEntries() int {
   return t.maxChildren() - 1
 }
 
-func (t *BTree) minEntries() int {
+func (t *BTree[K, V]) minEntries() int {
   return t.minChildren() - 1
 }
 
-func (t *BTree) middle() int {
+func (t *BTree[K, V]) middle() int {
   return (t.m - 1) / 2 // "-1" to favor right nodes to have more keys when splitting
 }
 
 // search searches only within the single node among its entries
-func (t *BTree) search(node *BNode, key interface{}) (index int, found bool) {
+func (t *BTree[K, V]) search(node *BNode[K, V], key K) (index int, found bool) {
   low, high := 0, len(node.Entries)-1
   var mid int
   for low <= high {
@@ -249,7 +425,7 @@ func (t *BTree) search(node *BNode, key interface{}) (index int, found bool) {
 }
 
 // searchRecursively searches recursively down the tree starting at the startNode
-func (t *BTree) searchRecursively(startNode *BNode, key interface{}) (node *BNode, index int, found bool) {
+func (t *BTree[K, V]) searchRecursively(startNode *BNode[K, V], key K) (node *BNode[K, V], index int, found bool) {
   if t.IsEmpty() {
     return nil, -1, false
   }
@@ -266,14 +442,57 @@ func (t *BTree) searchRecursively(startNode *BNode, key interface{}) (node *BNod
   }
 }
 
-func (t *BTree) insert(node *BNode, entry *Entry) (inserted bool) {
+// floor returns the entry with the largest key smaller than key (or equal to it if inclusive) and its node, nil if there is none
+func (t *BTree[K, V]) floor(key K, inclusive bool) (*BNode[K, V], *Entry[K, V]) {
+  var floorNode *BNode[K, V]
+  var floor *Entry[K, V]
+  for node := t.Root; node != nil; {
+    index, found := t.search(node, key)
+    if found && inclusive {
+      return node, node.Entries[index]
+    }
+    if index > 0 {
+      floorNode, floor = node, node.Entries[index-1]
+    }
+    if IsLeaf(node) {
+      break
+    }
+    node = node.Children[index]
+  }
+  return floorNode, floor
+}
+
+// ceiling returns the entry with the smallest key larger than key (or equal to it if inclusive) and its node, nil if there is none
+func (t *BTree[K, V]) ceiling(key K, inclusive bool) (*BNode[K, V], *Entry[K, V]) {
+  var ceilingNode *BNode[K, V]
+  var ceiling *Entry[K, V]
+  for node := t.Root; node != nil; {
+    index, found := t.search(node, key)
+    if found {
+      if inclusive {
+        return node, node.Entries[index]
+      }
+      index++
+    }
+    if index < len(node.Entries) {
+      ceilingNode, ceiling = node, node.Entries[index]
+    }
+    if IsLeaf(node) {
+      break
+    }
+    node = node.Children[index]
+  }
+  return ceilingNode, ceiling
+}
+
+func (t *BTree[K, V]) insert(node *BNode[K, V], entry *Entry[K, V]) (inserted bool) {
   if IsLeaf(node) {
     return t.insertIntoLeaf(node, entry)
   }
   return t.insertIntoInternal(node, entry)
 }
 
-func (t *BTree) insertIntoLeaf(node *BNode, entry *Entry) (inserted bool) {
+func (t *BTree[K, V]) insertIntoLeaf(node *BNode[K, V], entry *Entry[K, V]) (inserted bool) {
   insertPosition, found := t.search(node, entry.Key)
   if found {
     node.Entries[insertPosition] = entry
@@ -287,7 +506,7 @@ func (t *BTree) insertIntoLeaf(node *BNode, entry *Entry) (inserted bool) {
   return true
 }
 
-func (t *BTree) insertIntoInternal(node *BNode, entry *Entry) (inserted bool) {
+func (t *BTree[K, V]) insertIntoInternal(node *BNode[K, V], entry *Entry[K, V]) (inserted bool) {
   insertPosition, found := t.search(node, entry.Key)
   if found {
     node.Entries[insertPosition] = entry
@@ -296,7 +515,7 @@ func (t *BTree) insertIntoInternal(node *BNode, entry *Entry) (inserted bool) {
   return t.insert(node.Children[insertPosition], entry)
 }
 
-func (t *BTree) split(node *BNode) {
+func (t *BTree[K, V]) split(node *BNode[K, V]) {
   if !t.shouldSplit(node) {
     return
   }
@@ -309,17 +528,18 @@ func (t *BTree) split(node *BNode) {
   t.splitNonRoot(node)
 }
 
-func (t *BTree) splitNonRoot(node *BNode) {
+func (t *BTree[K, V]) splitNonRoot(node *BNode[K, V]) {
+  t.traceNode("split", node)
   middle := t.middle()
   parent := node.Parent
 
-  left := NewBNode(parent, append([]*Entry(nil), node.Entries[:middle]...), nil)
-  right := NewBNode(parent, append([]*Entry(nil), node.Entries[middle+1:]...), nil)
+  left := NewBNode(parent, append([]*Entry[K, V](nil), node.Entries[:middle]...), nil)
+  right := NewBNode(parent, append([]*Entry[K, V](nil), node.Entries[middle+1:]...), nil)
 
   // Move children from the node to be split into left and right nodes
   if !IsLeaf(node) {
-    left.Children = append([]*BNode(nil), node.Children[:middle+1]...)
-    right.Children = append([]*BNode(nil), node.Children[middle+1:]...)
+    left.Children = append([]*BNode[K, V](nil), node.Children[:middle+1]...)
+    right.Children = append([]*BNode[K, V](nil), node.Children[middle+1:]...)
     setParent(left.Children, left)
     setParent(right.Children, right)
   }
@@ -342,35 +562,81 @@ func (t *BTree) splitNonRoot(node *BNode) {
   t.split(parent)
 }
 
-func (t *BTree) splitRoot() {
+func (t *BTree[K, V]) splitRoot() {
+  t.traceNode("splitRoot", t.Root)
   middle := t.middle()
 
-  left := NewBNode(nil, append([]*Entry(nil), t.Root.Entries[:middle]...), nil)
-  right := NewBNode(nil, append([]*Entry(nil), t.Root.Entries[middle+1:]...), nil)
+  left := NewBNode(nil, append([]*Entry[K, V](nil), t.Root.Entries[:middle]...), nil)
+  right := NewBNode(nil, append([]*Entry[K, V](nil), t.Root.Entries[middle+1:]...), nil)
 
   // Move children from the node to be split into left and right nodes
   if !IsLeaf(t.Root) {
-    left.Children = append([]*BNode(nil), t.Root.Children[:middle+1]...)
-    right.Children = append([]*BNode(nil), t.Root.Children[middle+1:]...)
+    left.Children = append([]*BNode[K, V](nil), t.Root.Children[:middle+1]...)
+    right.Children = append([]*BNode[K, V](nil), t.Root.Children[middle+1:]...)
     setParent(left.Children, left)
     setParent(right.Children, right)
   }
 
   // Root is a node with one entry and two children (left and right)
-  newRoot := NewBNode(nil, []*Entry{t.Root.Entries[middle]}, []*BNode{left, right})
+  newRoot := NewBNode(nil, []*Entry[K, V]{t.Root.Entries[middle]}, []*BNode[K, V]{left, right})
 
   left.Parent = newRoot
   right.Parent = newRoot
   t.Root = newRoot
 }
 
-func setParent(nodes []*BNode, parent *BNode) {
+// trace sends the step name touching keys to the tracer, if any
+func (t *BTree[K, V]) trace(name string, keys ...K) {
+  if t.tracer != nil {
+    t.tracer.Trace(gotree.Step("btree", name, keys...))
+  }
+}
+
+// traceNode sends the step name with the keys of node to the tracer, if any
+func (t *BTree[K, V]) traceNode(name string, node *BNode[K, V]) {
+  if t.tracer == nil {
+    return
+  }
+  keys := make([]K, len(node.Entries))
+  for i, entry := range node.Entries {
+    keys[i] = entry.Key
+  }
+  t.trace(name, keys...)
+}
+
+// build replaces the contents of the tree with the sorted keys and their values
+func (t *BTree[K, V]) build(keys []K, values []V) {
+  t.Clear()
+  var leaf *BNode[K, V]
+  for i, key := range keys {
+    leaf = t.appendEntry(leaf, NewEntry(key, values[i]))
+  }
+}
+
+// appendEntry adds entry, whose key is greater than every key of the tree, to leaf, the rightmost
+// leaf (nil at first), and returns the new rightmost leaf. Splits go up as in Put, but the leaf is
+// not searched for.
+func (t *BTree[K, V]) appendEntry(leaf *BNode[K, V], entry *Entry[K, V]) *BNode[K, V] {
+  if t.Root == nil {
+    t.Root = NewBNode[K, V](nil, nil, nil)
+    leaf = t.Root
+  }
+  t.size++
+  leaf.Entries = append(leaf.Entries, entry)
+  if t.shouldSplit(leaf) {
+    t.split(leaf)
+    leaf = t.right(t.Root)
+  }
+  return leaf
+}
+
+func setParent[K comparable, V any](nodes []*BNode[K, V], parent *BNode[K, V]) {
   for _, node := range nodes {
     node.Parent = parent
   }
 }
 
-func (t *BTree) left(node *BNode) *BNode {
+func (t *BTree[K, V]) left(node *BNode[K, V]) *BNode[K, V] {
   if t.IsEmpty() {
     return nil
   }
@@ -383,7 +649,7 @@ func (t *BTree) left(node *BNode) *BNode {
   }
 }
 
-func (t *BTree) right(node *BNode) *BNode {
+func (t *BTree[K, V]) right(node *BNode[K, V]) *BNode[K, V] {
   if t.IsEmpty() {
     return nil
   }
@@ -398,7 +664,7 @@ func (t *BTree) right(node *BNode) *BNode {
 
 // leftSibling returns the node's left sibling and child index (in parent) if it exists, otherwise (nil,-1)
 // key is any of keys in node (could even be deleted).
-func (t *BTree) leftSibling(node *BNode, key interface{}) (*BNode, int) {
+func (t *BTree[K, V]) leftSibling(node *BNode[K, V], key K) (*BNode[K, V], int) {
   if node.Parent != nil {
     index, _ := t.search(node.Parent, key)
     index--
@@ -411,7 +677,7 @@ func (t *BTree) leftSibling(node *BNode, key interface{}) (*BNode, int) {
 
 // rightSibling returns the node's right sibling and child index (in parent) if it exists, otherwise (nil,-1)
 // key is any of keys in node (could even be deleted).
-func (t *BTree) rightSibling(node *BNode, key interface{}) (*BNode, int) {
+func (t *BTree[K, V]) rightSibling(node *BNode[K, V], key K) (*BNode[K, V], int) {
   if node.Parent != nil {
     index, _ := t.search(node.Parent, key)
     index++
@@ -424,7 +690,7 @@ func (t *BTree) rightSibling(node *BNode, key interface{}) (*BNode, int) {
 
 // delete deletes an entry in node at entries' index
 // ref.: https://en.wikipedia.org/wiki/B-tree#Deletion
-func (t *BTree) delete(node *BNode, index int) {
+func (t *BTree[K, V]) delete(node *BNode[K, V], index int) {
   // deleting from a leaf node
   if IsLeaf(node) {
     deletedKey := node.Entries[index].Key
@@ -447,7 +713,7 @@ func (t *BTree) delete(node *BNode, index int) {
 
 // rebalance rebalances the tree after deletion if necessary and returns true, otherwise false.
 // Note that we first delete the entry and then call rebalance, thus the passed deleted key as reference.
-func (t *BTree) rebalance(node *BNode, deletedKey interface{}) {
+func (t *BTree[K, V]) rebalance(node *BNode[K, V], deletedKey K) {
   // check if rebalancing is needed
   if node == nil || len(node.Entries) >= t.minEntries() {
     return
@@ -457,13 +723,14 @@ func (t *BTree) rebalance(node *BNode, deletedKey interface{}) {
   leftSibling, leftSiblingIndex := t.leftSibling(node, deletedKey)
   if leftSibling != nil && len(leftSibling.Entries) > t.minEntries() {
     // rotate right
-    node.Entries = append([]*Entry{node.Parent.Entries[leftSiblingIndex]}, node.Entries...) // prepend parent's separator entry to node's entries
+    t.trace("rotateRight", node.Parent.Entries[leftSiblingIndex].Key, leftSibling.Entries[len(leftSibling.Entries)-1].Key)
+    node.Entries = append([]*Entry[K, V]{node.Parent.Entries[leftSiblingIndex]}, node.Entries...) // prepend parent's separator entry to node's entries
     node.Parent.Entries[leftSiblingIndex] = leftSibling.Entries[len(leftSibling.Entries)-1]
     t.deleteEntry(leftSibling, len(leftSibling.Entries)-1)
     if !IsLeaf(leftSibling) {
       leftSiblingRightMostChild := leftSibling.Children[len(leftSibling.Children)-1]
       leftSiblingRightMostChild.Parent = node
-      node.Children = append([]*BNode{leftSiblingRightMostChild}, node.Children...)
+      node.Children = append([]*BNode[K, V]{leftSiblingRightMostChild}, node.Children...)
       t.deleteChild(leftSibling, len(leftSibling.Children)-1)
     }
     return
@@ -473,6 +740,7 @@ func (t *BTree) rebalance(node *BNode, deletedKey interface{}) {
   rightSibling, rightSiblingIndex := t.rightSibling(node, deletedKey)
   if rightSibling != nil && len(rightSibling.Entries) > t.minEntries() {
     // rotate left
+    t.trace("rotateLeft", node.Parent.Entries[rightSiblingIndex-1].Key, rightSibling.Entries[0].Key)
     node.Entries = append(node.Entries, node.Parent.Entries[rightSiblingIndex-1]) // append parent's separator entry to node's entries
     node.Parent.Entries[rightSiblingIndex-1] = rightSibling.Entries[0]
     t.deleteEntry(rightSibling, 0)
@@ -494,15 +762,17 @@ func (t *BTree) rebalance(node *BNode, deletedKey interface{}) {
     t.deleteEntry(node.Parent, rightSiblingIndex-1)
     t.appendChildren(node.Parent.Children[rightSiblingIndex], node)
     t.deleteChild(node.Parent, rightSiblingIndex)
+    t.traceNode("merge", node)
   } else if leftSibling != nil {
     // merge with left sibling
-    entries := append([]*Entry(nil), leftSibling.Entries...)
+    entries := append([]*Entry[K, V](nil), leftSibling.Entries...)
     entries = append(entries, node.Parent.Entries[leftSiblingIndex])
     node.Entries = append(entries, node.Entries...)
     deletedKey = node.Parent.Entries[leftSiblingIndex].Key
     t.deleteEntry(node.Parent, leftSiblingIndex)
     t.prependChildren(node.Parent.Children[leftSiblingIndex], node)
     t.deleteChild(node.Parent, leftSiblingIndex)
+    t.traceNode("merge", node)
   }
 
   // make the merged node the root if its parent was the root and the root is empty
@@ -516,24 +786,24 @@ func (t *BTree) rebalance(node *BNode, deletedKey interface{}) {
   t.rebalance(node.Parent, deletedKey)
 }
 
-func (t *BTree) prependChildren(fromNode *BNode, toNode *BNode) {
-  children := append([]*BNode(nil), fromNode.Children...)
+func (t *BTree[K, V]) prependChildren(fromNode *BNode[K, V], toNode *BNode[K, V]) {
+  children := append([]*BNode[K, V](nil), fromNode.Children...)
   toNode.Children = append(children, toNode.Children...)
   setParent(fromNode.Children, toNode)
 }
 
-func (t *BTree) appendChildren(fromNode *BNode, toNode *BNode) {
+func (t *BTree[K, V]) appendChildren(fromNode *BNode[K, V], toNode *BNode[K, V]) {
   toNode.Children = append(toNode.Children, fromNode.Children...)
   setParent(fromNode.Children, toNode)
 }
 
-func (t *BTree) deleteEntry(node *BNode, index int) {
+func (t *BTree[K, V]) deleteEntry(node *BNode[K, V], index int) {
   copy(node.Entries[index:], node.Entries[index+1:])
   node.Entries[len(node.Entries)-1] = nil
   node.Entries = node.Entries[:len(node.Entries)-1]
 }
 
-func (t *BTree) deleteChild(node *BNode, index int) {
+func (t *BTree[K, V]) deleteChild(node *BNode[K, V], index int) {
   if index >= len(node.Children) {
     return
   }
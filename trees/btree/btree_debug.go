@@ -0,0 +1,33 @@
+package btree
+
+import (
+  "fmt"
+  "github.com/fmorenovr/gods/utils"
+)
+
+// debugCheck runs after every mutation in godsdebug builds (see utils.Debug): it
+// spot-checks the comparator on key and the keys around its search path, then
+// re-validates the tree
+func (t *BTree[K, V]) debugCheck(operation string, key K) {
+  keys := []K{key}
+  for node := t.Root; node != nil; {
+    index, found := t.search(node, key)
+    if index > 0 {
+      keys = append(keys, node.Entries[index-1].Key)
+    }
+    if index < len(node.Entries) {
+      keys = append(keys, node.Entries[index].Key)
+    }
+    if found || IsLeaf(node) {
+      break
+    }
+    node = node.Children[index]
+  }
+  err := utils.CheckComparator(t.comparator, keys...)
+  if err == nil {
+    err = t.Validate()
+  }
+  if err != nil {
+    utils.DebugFailure("BTree", fmt.Sprintf("%v(%v)", operation, key), err, t)
+  }
+}
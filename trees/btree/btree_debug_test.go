@@ -0,0 +1,42 @@
+//go:build godsdebug
+
+package btree
+
+import (
+  "strings"
+  "testing"
+)
+
+func TestBTreeDebugBadComparator(t *testing.T) {
+  // not antisymmetric: the first argument always wins unless the keys are equal
+  tree := NewBTreeWith[int, string](3, func(a, b int) int {
+    if a == b {
+      return 0
+    }
+    return 1
+  })
+  defer func() {
+    err, _ := recover().(error)
+    if err == nil || !strings.Contains(err.Error(), "BTree.Put(") || !strings.Contains(err.Error(), "antisymmetric") {
+      t.Errorf("Got %v expected a comparator report from Put", err)
+    }
+  }()
+  for _, key := range []int{1, 2, 3} {
+    tree.Put(key, "")
+  }
+}
+
+func TestBTreeDebugCorruption(t *testing.T) {
+  tree := NewBTree[int, string](3)
+  for key := 1; key <= 7; key++ {
+    tree.Put(key, "")
+  }
+  tree.Root.Children[0].Entries[0].Key = 100
+  defer func() {
+    err, _ := recover().(error)
+    if err == nil || !strings.Contains(err.Error(), "BTree.Remove(7)") || !strings.Contains(err.Error(), "100") {
+      t.Errorf("Got %v expected a report naming key 100", err)
+    }
+  }()
+  tree.Remove(7)
+}
@@ -0,0 +1,43 @@
+package btree
+
+import (
+  "fmt"
+  "io"
+  "github.com/fmorenovr/gods/trees"
+)
+
+// WriteDOT writes the tree in Graphviz DOT, one record per node with a field per key
+// and edges leaving from the ports between keys
+func (t *BTree[K, V]) WriteDOT(w io.Writer, opts gotree.ExportOptions[K]) error {
+  return t.Graph(opts).WriteDOT(w)
+}
+
+// WriteMermaid writes the tree as a Mermaid flowchart, one box per node listing its keys
+func (t *BTree[K, V]) WriteMermaid(w io.Writer, opts gotree.ExportOptions[K]) error {
+  return t.Graph(opts).WriteMermaid(w)
+}
+
+// Graph returns the drawing that WriteDOT and WriteMermaid render
+func (t *BTree[K, V]) Graph(opts gotree.ExportOptions[K]) *gotree.Graph {
+  g := gotree.NewGraph(opts.Name, true)
+  if t.Root != nil {
+    t.addGraph(g, t.Root, opts)
+  }
+  return g
+}
+
+// addGraph adds the subtree of node to g and returns the id of node,
+// child i hangs from port i, between keys i-1 and i
+func (t *BTree[K, V]) addGraph(g *gotree.Graph, node *BNode[K, V], opts gotree.ExportOptions[K]) string {
+  labels := make([]string, len(node.Entries))
+  highlight := false
+  for i, entry := range node.Entries {
+    labels[i] = fmt.Sprintf("%v", entry.Key)
+    highlight = highlight || opts.Highlighted(entry.Key, t.comparator)
+  }
+  id := g.AddNode(gotree.GraphNode{Labels: labels, Highlight: highlight})
+  for i, child := range node.Children {
+    g.AddEdge(id, t.addGraph(g, child, opts), i)
+  }
+  return id
+}
@@ -1,38 +1,76 @@
 package btree
 
 import(
-  "github.com/jenazads/goutils";
+  "iter";
+  "github.com/fmorenovr/gods/trees";
+  "github.com/fmorenovr/gods/utils";
 )
 
 func assertIteratorImplementation() {
-  var _ goutils.ReverseIteratorKey = (*Iterator)(nil)
+  var _ utils.ReverseIteratorWithKey[string, int] = (*Iterator[string, int])(nil)
 }
 
 // Iterator holding the iterator's state
-type Iterator struct {
-  tree     *BTree
-  node     *BNode
-  entry    *Entry
+type Iterator[K comparable, V any] struct {
+  tree     *BTree[K, V]
+  node     *BNode[K, V]
+  entry    *Entry[K, V]
   position position
+  bounded  bool         // true for range iterators
+  lowNode  *BNode[K, V] // node holding low
+  low      *Entry[K, V] // first entry of the range, nil if the range is empty
+  highNode *BNode[K, V] // node holding high
+  high     *Entry[K, V] // last entry of the range, nil if the range is empty
 }
 
 type position byte
 
+// before means the iterator sits just before entry, see IteratorAt
 const (
-  begin, between, end position = 0, 1, 2
+  begin, between, end, before position = 0, 1, 2, 3
 )
 
 // Iterator returns a stateful iterator whose elements are key/value pairs.
-func (t *BTree) Iterator() Iterator {
-  return Iterator{tree: t, node: nil, position: begin}
+func (t *BTree[K, V]) Iterator() utils.ReverseIteratorWithKey[K, V] {
+  return &Iterator[K, V]{tree: t, node: nil, position: begin}
+}
+
+// RangeIterator returns a stateful iterator over the keys between lo and hi,
+// inclusivity tells whether lo and hi themselves are part of the range.
+// Begin/End and First/Last refer to the bounds of the range, not of the tree.
+func (t *BTree[K, V]) RangeIterator(lo, hi K, inclusivity gotree.Inclusivity) utils.ReverseIteratorWithKey[K, V] {
+  iterator := &Iterator[K, V]{tree: t, node: nil, position: begin, bounded: true}
+  iterator.lowNode, iterator.low = t.ceiling(lo, inclusivity.IncludesLow())
+  iterator.highNode, iterator.high = t.floor(hi, inclusivity.IncludesHigh())
+  if iterator.low == nil || iterator.high == nil || t.comparator(iterator.low.Key, iterator.high.Key) > 0 {
+    iterator.lowNode, iterator.low, iterator.highNode, iterator.high = nil, nil, nil, nil
+  }
+  return iterator
+}
+
+// IteratorAt returns a stateful iterator placed just before the smallest key
+// larger than or equal to key: Next moves to that key, Prev to the one before it.
+func (t *BTree[K, V]) IteratorAt(key K) utils.ReverseIteratorWithKey[K, V] {
+  node, entry := t.ceiling(key, true)
+  if entry == nil {
+    return &Iterator[K, V]{tree: t, node: nil, position: end}
+  }
+  return &Iterator[K, V]{tree: t, node: node, entry: entry, position: before}
 }
 
 // Moves to the next element
-func (iterator *Iterator) Next() bool {
+func (iterator *Iterator[K, V]) Next() bool {
   if iterator.position == end {
     goto end
   }
   if iterator.position == begin {
+    if iterator.bounded {
+      if iterator.low == nil {
+        goto end
+      }
+      iterator.node, iterator.entry = iterator.lowNode, iterator.low
+      goto between
+    }
     left := iterator.tree.Left()
     if left == nil {
       goto end
@@ -41,6 +79,12 @@ func (iterator *Iterator) Next() bool {
     iterator.entry = left.Entries[0]
     goto between
   }
+  if iterator.position == before {
+    goto between
+  }
+  if iterator.bounded && iterator.entry == iterator.high {
+    goto end
+  }
   {
     e, _ := iterator.tree.search(iterator.node, iterator.entry.Key)
     if e+1 < len(iterator.node.Children) {
@@ -75,11 +119,18 @@ between:
 }
 
 // Move to the Prev element
-func (iterator *Iterator) Prev() bool {
+func (iterator *Iterator[K, V]) Prev() bool {
   if iterator.position == begin {
     goto begin
   }
   if iterator.position == end {
+    if iterator.bounded {
+      if iterator.high == nil {
+        goto begin
+      }
+      iterator.node, iterator.entry = iterator.highNode, iterator.high
+      goto between
+    }
     right := iterator.tree.Right()
     if right == nil {
       goto begin
@@ -88,6 +139,9 @@ func (iterator *Iterator) Prev() bool {
     iterator.entry = right.Entries[len(right.Entries)-1]
     goto between
   }
+  if iterator.bounded && iterator.entry == iterator.low {
+    goto begin
+  }
   {
     e, _ := iterator.tree.search(iterator.node, iterator.entry.Key)
     if e < len(iterator.node.Children) {
@@ -122,37 +176,84 @@ between:
 }
 
 // Return current value
-func (iterator *Iterator) Value() interface{} {
+func (iterator *Iterator[K, V]) Value() V {
   return iterator.entry.Value
 }
 
 // Return current Key
-func (iterator *Iterator) Key() interface{} {
+func (iterator *Iterator[K, V]) Key() K {
   return iterator.entry.Key
 }
 
 // Set pointer to Begin state
-func (iterator *Iterator) Begin() {
+func (iterator *Iterator[K, V]) Begin() {
   iterator.node = nil
   iterator.position = begin
   iterator.entry = nil
 }
 
 // Set pointer to last state
-func (iterator *Iterator) End() {
+func (iterator *Iterator[K, V]) End() {
   iterator.node = nil
   iterator.position = end
   iterator.entry = nil
 }
 
 // Moves to the first element
-func (iterator *Iterator) First() bool {
+func (iterator *Iterator[K, V]) First() bool {
   iterator.Begin()
   return iterator.Next()
 }
 
 // Moves to the Last element
-func (iterator *Iterator) Last() bool {
+func (iterator *Iterator[K, V]) Last() bool {
   iterator.End()
   return iterator.Prev()
 }
+
+// All returns a sequence of the key/value pairs in key order, for use with range
+func (t *BTree[K, V]) All() iter.Seq2[K, V] {
+  return func(yield func(K, V) bool) {
+    it := t.Iterator()
+    for it.Next() {
+      if !yield(it.Key(), it.Value()) {
+        return
+      }
+    }
+  }
+}
+
+// Backward returns a sequence of the key/value pairs in reverse key order
+func (t *BTree[K, V]) Backward() iter.Seq2[K, V] {
+  return func(yield func(K, V) bool) {
+    it := t.Iterator()
+    it.End()
+    for it.Prev() {
+      if !yield(it.Key(), it.Value()) {
+        return
+      }
+    }
+  }
+}
+
+// KeysSeq returns a sequence of the keys in order, the lazy form of Keys
+func (t *BTree[K, V]) KeysSeq() iter.Seq[K] {
+  return func(yield func(K) bool) {
+    for key := range t.All() {
+      if !yield(key) {
+        return
+      }
+    }
+  }
+}
+
+// ValuesSeq returns a sequence of the values in key order, the lazy form of Values
+func (t *BTree[K, V]) ValuesSeq() iter.Seq[V] {
+  return func(yield func(V) bool) {
+    for _, value := range t.All() {
+      if !yield(value) {
+        return
+      }
+    }
+  }
+}
@@ -1,13 +1,21 @@
-package btree_test
+package btree
 
 import (
+  "bytes"
+  "encoding/json"
+  "errors"
   "fmt"
-  "github.com/jenazads/gods/trees/btree"
-  "github.com/jenazads/goutils"
+  "slices"
+  "strconv"
+  "strings"
+  "testing"
+  "github.com/fmorenovr/gods/trees"
+  "github.com/fmorenovr/gods/trees/treetest"
+  "github.com/fmorenovr/gods/utils"
 )
 
-func main() {
-	tree := btree.NewBTree(4, goutils.IntComparator)
+func Example_bTree() {
+	tree := NewBTree[int, string](4)
 	tree.Put(1, "a")
 	tree.Put(2, "b")
 	tree.Put(3, "c")
@@ -20,8 +28,212 @@ func main() {
   fmt.Println(tree)
 }
 
+func TestBTreeOrderedMap(t *testing.T) {
+  for _, order := range []int{3, 4, 5, 8} {
+    treetest.Run(t, func() gotree.OrderedMap[int, string] {
+      return NewBTree[int, string](order)
+    }, strconv.Itoa)
+  }
+}
+
+func TestBTreeRangeIterator(t *testing.T) {
+  for _, order := range []int{3, 4, 5} {
+    treetest.RunRange(t, func() gotree.RangeMap[int, string] {
+      return NewBTree[int, string](order)
+    }, strconv.Itoa)
+  }
+}
+
+func TestBTreeSequences(t *testing.T) {
+  tree := NewBTree[int, string](3)
+  for _, key := range []int{5, 1, 4, 2, 3} {
+    tree.Put(key, strconv.Itoa(key))
+  }
+  if actualValue, expectedValue := slices.Collect(tree.KeysSeq()), tree.Keys(); !slices.Equal(actualValue, expectedValue) {
+    t.Errorf("Got %v expected %v", actualValue, expectedValue)
+  }
+  if actualValue, expectedValue := slices.Collect(tree.ValuesSeq()), tree.Values(); !slices.Equal(actualValue, expectedValue) {
+    t.Errorf("Got %v expected %v", actualValue, expectedValue)
+  }
+  var keys []int
+  for key := range tree.Backward() {
+    if key == 2 {
+      break
+    }
+    keys = append(keys, key)
+  }
+  if actualValue, expectedValue := keys, []int{5, 4, 3}; !slices.Equal(actualValue, expectedValue) {
+    t.Errorf("Got %v expected %v", actualValue, expectedValue)
+  }
+}
+
+func TestBTreeWalk(t *testing.T) {
+  tree := NewBTree[int, string](3)
+  for _, key := range []int{1, 2, 3, 4, 5, 6, 7} {
+    tree.Put(key, strconv.Itoa(key))
+  }
+  tests := []struct {
+    order    gotree.TraversalOrder
+    expected []int
+  }{
+    {gotree.PreOrder, []int{4, 2, 1, 3, 6, 5, 7}},
+    {gotree.InOrder, []int{1, 2, 3, 4, 5, 6, 7}},
+    {gotree.PostOrder, []int{1, 3, 2, 5, 7, 6, 4}},
+    {gotree.LevelOrder, []int{4, 2, 6, 1, 3, 5, 7}},
+  }
+  for _, test := range tests {
+    var keys []int
+    tree.Walk(test.order, func(key int, _ string) bool {
+      keys = append(keys, key)
+      return true
+    })
+    if !slices.Equal(keys, test.expected) {
+      t.Errorf("Walk(%v) got %v expected %v", test.order, keys, test.expected)
+    }
+    keys = keys[:0]
+    for key := range tree.Traverse(test.order) {
+      if len(keys) == 3 {
+        break
+      }
+      keys = append(keys, key)
+    }
+    if !slices.Equal(keys, test.expected[:3]) {
+      t.Errorf("Traverse(%v) got %v expected %v", test.order, keys, test.expected[:3])
+    }
+  }
+}
+
+func TestBTreeValidate(t *testing.T) {
+  tree := NewBTree[int, string](3)
+  if err := tree.Validate(); err != nil {
+    t.Errorf("Got %v expected %v", err, nil)
+  }
+  for key := 1; key <= 7; key++ {
+    tree.Put(key, strconv.Itoa(key))
+  }
+  if err := tree.Validate(); err != nil {
+    t.Errorf("Got %v expected %v", err, nil)
+  }
+  tree.size++
+  if err := tree.Validate(); err == nil || !strings.Contains(err.Error(), "size") {
+    t.Errorf("Got %v expected a size error", err)
+  }
+  tree.size--
+  tree.Root.Children[0].Children[1].Entries[0].Key = 5
+  if err := tree.Validate(); err == nil || !strings.Contains(err.Error(), "[5]") {
+    t.Errorf("Got %v expected an error naming node [5]", err)
+  }
+  tree.Root.Children[0].Children[1].Entries[0].Key = 3
+  leaf := tree.Root.Children[1].Children[0]
+  leaf.Entries = append(leaf.Entries, &Entry[int, string]{Key: 6}, &Entry[int, string]{Key: 7})
+  if err := tree.Validate(); err == nil || !strings.Contains(err.Error(), "3 entries") {
+    t.Errorf("Got %v expected an error about 3 entries", err)
+  }
+}
+
+func TestBTreeExport(t *testing.T) {
+  tree := NewBTree[int, string](3)
+  for _, key := range []int{1, 2, 3, 4, 5} {
+    tree.Put(key, strconv.Itoa(key))
+  }
+  var dot strings.Builder
+  if err := tree.WriteDOT(&dot, gotree.ExportOptions[int]{Highlight: []int{4}}); err != nil {
+    t.Errorf("Got %v expected %v", err, nil)
+  }
+  for _, expectedValue := range []string{"node [shape=record];", `n0 [label="<p0> |2|<p1> |4|<p2> ", color="#ff8c00", penwidth=3];`, `n3 [label="<p0> |5|<p1> "];`, "n0:p0 -> n1;", "n0:p2 -> n3;"} {
+    if actualValue := dot.String(); !strings.Contains(actualValue, expectedValue) {
+      t.Errorf("Got %v expected it to contain %v", actualValue, expectedValue)
+    }
+  }
+  var mermaid strings.Builder
+  if err := tree.WriteMermaid(&mermaid, gotree.ExportOptions[int]{}); err != nil {
+    t.Errorf("Got %v expected %v", err, nil)
+  }
+  for _, expectedValue := range []string{`n0["2 | 4"]`, "n0 --> n3"} {
+    if actualValue := mermaid.String(); !strings.Contains(actualValue, expectedValue) {
+      t.Errorf("Got %v expected it to contain %v", actualValue, expectedValue)
+    }
+  }
+  empty := NewBTree[int, string](3)
+  dot.Reset()
+  if err := empty.WriteDOT(&dot, gotree.ExportOptions[int]{}); err != nil || strings.Contains(dot.String(), "n0") {
+    t.Errorf("Got %v, %v expected an empty graph", dot.String(), err)
+  }
+}
+
+func TestBTreeTracer(t *testing.T) {
+  tree := NewBTree[int, string](3)
+  recorder := &gotree.Recorder{}
+  tree.SetTracer(recorder)
+  for _, key := range []int{1, 2, 3, 4, 5} {
+    tree.Put(key, strconv.Itoa(key))
+  }
+  tree.Remove(1)
+  var actualValue []string
+  for _, event := range recorder.Events {
+    actualValue = append(actualValue, fmt.Sprint(event.Name, event.Keys))
+  }
+  expectedValue := []string{
+    "Put[1]", "Put[2]", "Put[3]", "splitRoot[1 2 3]", "Put[4]", "Put[5]", "split[3 4 5]",
+    "Remove[1]", "merge[2 3]",
+  }
+  if !slices.Equal(actualValue, expectedValue) {
+    t.Errorf("Got %v expected %v", actualValue, expectedValue)
+  }
+  replayed := NewBTree[int, string](3)
+  if err := gotree.Replay[int, string](recorder.Events, replayed); err != nil {
+    t.Errorf("Got %v expected %v", err, nil)
+  }
+  if actualValue, expectedValue := replayed.String(), tree.String(); actualValue != expectedValue {
+    t.Errorf("Got %v expected %v", actualValue, expectedValue)
+  }
+}
+
+func TestBTreeFloorCeilingLowerHigher(t *testing.T) {
+  tree := NewBTree[int, string](3)
+  if node, found := tree.Floor(1); node != nil || found {
+    t.Errorf("Got %v expected %v", node, nil)
+  }
+  if node, found := tree.Higher(1); node != nil || found {
+    t.Errorf("Got %v expected %v", node, nil)
+  }
+  for key := 2; key <= 20; key += 2 {
+    tree.Put(key, strconv.Itoa(key))
+  }
+  key := func(node *Entry[int, string], found bool) int {
+    if !found {
+      return -1
+    }
+    return node.Key
+  }
+  // key, floor, ceiling, lower, higher (-1 is not found)
+  tests := [][]int{
+    {1, -1, 2, -1, 2},
+    {2, 2, 2, -1, 4},
+    {3, 2, 4, 2, 4},
+    {10, 10, 10, 8, 12},
+    {11, 10, 12, 10, 12},
+    {20, 20, 20, 18, -1},
+    {21, 20, -1, 20, -1},
+  }
+  for _, test := range tests {
+    if actual := key(tree.Floor(test[0])); actual != test[1] {
+      t.Errorf("Floor(%v) got %v expected %v", test[0], actual, test[1])
+    }
+    if actual := key(tree.Ceiling(test[0])); actual != test[2] {
+      t.Errorf("Ceiling(%v) got %v expected %v", test[0], actual, test[2])
+    }
+    if actual := key(tree.Lower(test[0])); actual != test[3] {
+      t.Errorf("Lower(%v) got %v expected %v", test[0], actual, test[3])
+    }
+    if actual := key(tree.Higher(test[0])); actual != test[4] {
+      t.Errorf("Higher(%v) got %v expected %v", test[0], actual, test[4])
+    }
+  }
+}
+
 func TestBTreeGet1(t *testing.T) {
-	tree := NewWithIntComparator(3)
+	tree := NewBTree[int, any](3)
 	tree.Put(1, "a")
 	tree.Put(2, "b")
 	tree.Put(3, "c")
@@ -43,14 +255,14 @@ func TestBTreeGet1(t *testing.T) {
 	}
 
 	for _, test := range tests {
-		if value, found := tree.Get(test[0]); value != test[1] || found != test[2] {
+		if value, found := tree.Get(test[0].(int)); value != test[1] || found != test[2] {
 			t.Errorf("Got %v,%v expected %v,%v", value, found, test[1], test[2])
 		}
 	}
 }
 
 func TestBTreeGet2(t *testing.T) {
-	tree := NewWithIntComparator(3)
+	tree := NewBTree[int, any](3)
 	tree.Put(7, "g")
 	tree.Put(9, "i")
 	tree.Put(10, "j")
@@ -78,7 +290,7 @@ func TestBTreeGet2(t *testing.T) {
 	}
 
 	for _, test := range tests {
-		if value, found := tree.Get(test[0]); value != test[1] || found != test[2] {
+		if value, found := tree.Get(test[0].(int)); value != test[1] || found != test[2] {
 			t.Errorf("Got %v,%v expected %v,%v", value, found, test[1], test[2])
 		}
 	}
@@ -86,7 +298,7 @@ func TestBTreeGet2(t *testing.T) {
 
 func TestBTreePut1(t *testing.T) {
 	// https://upload.wikimedia.org/wikipedia/commons/3/33/B_tree_insertion_example.png
-	tree := NewWithIntComparator(3)
+	tree := NewBTree[int, any](3)
 	assertValidTree(t, tree, 0)
 
 	tree.Put(1, 0)
@@ -135,7 +347,7 @@ func TestBTreePut1(t *testing.T) {
 }
 
 func TestBTreePut2(t *testing.T) {
-	tree := NewWithIntComparator(4)
+	tree := NewBTree[int, any](4)
 	assertValidTree(t, tree, 0)
 
 	tree.Put(0, 0)
@@ -176,7 +388,7 @@ func TestBTreePut2(t *testing.T) {
 
 func TestBTreePut3(t *testing.T) {
 	// http://www.geeksforgeeks.org/b-tree-set-1-insert-2/
-	tree := NewWithIntComparator(6)
+	tree := NewBTree[int, any](6)
 	assertValidTree(t, tree, 0)
 
 	tree.Put(10, 0)
@@ -226,7 +438,7 @@ func TestBTreePut3(t *testing.T) {
 }
 
 func TestBTreePut4(t *testing.T) {
-	tree := NewWithIntComparator(3)
+	tree := NewBTree[int, any](3)
 	assertValidTree(t, tree, 0)
 
 	tree.Put(6, nil)
@@ -321,14 +533,14 @@ func TestBTreePut4(t *testing.T) {
 
 func TestBTreeRemove1(t *testing.T) {
 	// empty
-	tree := NewWithIntComparator(3)
+	tree := NewBTree[int, any](3)
 	tree.Remove(1)
 	assertValidTree(t, tree, 0)
 }
 
 func TestBTreeRemove2(t *testing.T) {
 	// leaf node (no underflow)
-	tree := NewWithIntComparator(3)
+	tree := NewBTree[int, any](3)
 	tree.Put(1, nil)
 	tree.Put(2, nil)
 
@@ -343,7 +555,7 @@ func TestBTreeRemove2(t *testing.T) {
 func TestBTreeRemove3(t *testing.T) {
 	// merge with right (underflow)
 	{
-		tree := NewWithIntComparator(3)
+		tree := NewBTree[int, any](3)
 		tree.Put(1, nil)
 		tree.Put(2, nil)
 		tree.Put(3, nil)
@@ -354,7 +566,7 @@ func TestBTreeRemove3(t *testing.T) {
 	}
 	// merge with left (underflow)
 	{
-		tree := NewWithIntComparator(3)
+		tree := NewBTree[int, any](3)
 		tree.Put(1, nil)
 		tree.Put(2, nil)
 		tree.Put(3, nil)
@@ -367,7 +579,7 @@ func TestBTreeRemove3(t *testing.T) {
 
 func TestBTreeRemove4(t *testing.T) {
 	// rotate left (underflow)
-	tree := NewWithIntComparator(3)
+	tree := NewBTree[int, any](3)
 	tree.Put(1, nil)
 	tree.Put(2, nil)
 	tree.Put(3, nil)
@@ -387,7 +599,7 @@ func TestBTreeRemove4(t *testing.T) {
 
 func TestBTreeRemove5(t *testing.T) {
 	// rotate right (underflow)
-	tree := NewWithIntComparator(3)
+	tree := NewBTree[int, any](3)
 	tree.Put(1, nil)
 	tree.Put(2, nil)
 	tree.Put(3, nil)
@@ -408,7 +620,7 @@ func TestBTreeRemove5(t *testing.T) {
 func TestBTreeRemove6(t *testing.T) {
 	// root height reduction after a series of underflows on right side
 	// use simulator: https://www.cs.usfca.edu/~galles/visualization/BTree.html
-	tree := NewWithIntComparator(3)
+	tree := NewBTree[int, any](3)
 	tree.Put(1, nil)
 	tree.Put(2, nil)
 	tree.Put(3, nil)
@@ -437,7 +649,7 @@ func TestBTreeRemove6(t *testing.T) {
 func TestBTreeRemove7(t *testing.T) {
 	// root height reduction after a series of underflows on left side
 	// use simulator: https://www.cs.usfca.edu/~galles/visualization/BTree.html
-	tree := NewWithIntComparator(3)
+	tree := NewBTree[int, any](3)
 	tree.Put(1, nil)
 	tree.Put(2, nil)
 	tree.Put(3, nil)
@@ -496,7 +708,7 @@ func TestBTreeRemove7(t *testing.T) {
 
 func TestBTreeRemove8(t *testing.T) {
 	// use simulator: https://www.cs.usfca.edu/~galles/visualization/BTree.html
-	tree := NewWithIntComparator(3)
+	tree := NewBTree[int, any](3)
 	tree.Put(1, nil)
 	tree.Put(2, nil)
 	tree.Put(3, nil)
@@ -533,7 +745,7 @@ func TestBTreeRemove9(t *testing.T) {
 	orders := []int{3, 4, 5, 6, 7, 8, 9, 10, 20, 100, 500, 1000, 5000, 10000}
 	for _, order := range orders {
 
-		tree := NewWithIntComparator(order)
+		tree := NewBTree[int, any](order)
 
 		{
 			for i := 1; i <= max; i++ {
@@ -574,7 +786,7 @@ func TestBTreeRemove9(t *testing.T) {
 }
 
 func TestBTreeHeight(t *testing.T) {
-	tree := NewWithIntComparator(3)
+	tree := NewBTree[int, any](3)
 	if actualValue, expectedValue := tree.Height(), 0; actualValue != expectedValue {
 		t.Errorf("Got %v expected %v", actualValue, expectedValue)
 	}
@@ -627,7 +839,7 @@ func TestBTreeHeight(t *testing.T) {
 }
 
 func TestBTreeLeftAndRight(t *testing.T) {
-	tree := NewWithIntComparator(3)
+	tree := NewBTree[int, any](3)
 
 	if actualValue := tree.Left(); actualValue != nil {
 		t.Errorf("Got %v expected %v", actualValue, nil)
@@ -661,7 +873,7 @@ func TestBTreeLeftAndRight(t *testing.T) {
 }
 
 func TestBTreeIteratorValuesAndKeys(t *testing.T) {
-	tree := NewWithIntComparator(4)
+	tree := NewBTree[int, any](4)
 	tree.Put(4, "d")
 	tree.Put(5, "e")
 	tree.Put(6, "f")
@@ -670,7 +882,7 @@ func TestBTreeIteratorValuesAndKeys(t *testing.T) {
 	tree.Put(7, "g")
 	tree.Put(2, "b")
 	tree.Put(1, "x") // override
-	if actualValue, expectedValue := fmt.Sprintf("%d%d%d%d%d%d%d", tree.Keys()...), "1234567"; actualValue != expectedValue {
+	if actualValue, expectedValue := fmt.Sprint(tree.Keys()), "[1 2 3 4 5 6 7]"; actualValue != expectedValue {
 		t.Errorf("Got %v expected %v", actualValue, expectedValue)
 	}
 	if actualValue, expectedValue := fmt.Sprintf("%s%s%s%s%s%s%s", tree.Values()...), "xbcdefg"; actualValue != expectedValue {
@@ -682,7 +894,7 @@ func TestBTreeIteratorValuesAndKeys(t *testing.T) {
 }
 
 func TestBTreeIteratorNextOnEmpty(t *testing.T) {
-	tree := NewWithIntComparator(3)
+	tree := NewBTree[int, any](3)
 	it := tree.Iterator()
 	for it.Next() {
 		t.Errorf("Shouldn't iterate on empty tree")
@@ -690,7 +902,7 @@ func TestBTreeIteratorNextOnEmpty(t *testing.T) {
 }
 
 func TestBTreeIteratorPrevOnEmpty(t *testing.T) {
-	tree := NewWithIntComparator(3)
+	tree := NewBTree[int, any](3)
 	it := tree.Iterator()
 	for it.Prev() {
 		t.Errorf("Shouldn't iterate on empty tree")
@@ -698,7 +910,7 @@ func TestBTreeIteratorPrevOnEmpty(t *testing.T) {
 }
 
 func TestBTreeIterator1Next(t *testing.T) {
-	tree := NewWithIntComparator(3)
+	tree := NewBTree[int, any](3)
 	tree.Put(5, "e")
 	tree.Put(6, "f")
 	tree.Put(7, "g")
@@ -729,7 +941,7 @@ func TestBTreeIterator1Next(t *testing.T) {
 }
 
 func TestBTreeIterator1Prev(t *testing.T) {
-	tree := NewWithIntComparator(3)
+	tree := NewBTree[int, any](3)
 	tree.Put(5, "e")
 	tree.Put(6, "f")
 	tree.Put(7, "g")
@@ -762,7 +974,7 @@ func TestBTreeIterator1Prev(t *testing.T) {
 }
 
 func TestBTreeIterator2Next(t *testing.T) {
-	tree := NewWithIntComparator(3)
+	tree := NewBTree[int, any](3)
 	tree.Put(3, "c")
 	tree.Put(1, "a")
 	tree.Put(2, "b")
@@ -788,7 +1000,7 @@ func TestBTreeIterator2Next(t *testing.T) {
 }
 
 func TestBTreeIterator2Prev(t *testing.T) {
-	tree := NewWithIntComparator(3)
+	tree := NewBTree[int, any](3)
 	tree.Put(3, "c")
 	tree.Put(1, "a")
 	tree.Put(2, "b")
@@ -816,7 +1028,7 @@ func TestBTreeIterator2Prev(t *testing.T) {
 }
 
 func TestBTreeIterator3Next(t *testing.T) {
-	tree := NewWithIntComparator(3)
+	tree := NewBTree[int, any](3)
 	tree.Put(1, "a")
 	it := tree.Iterator()
 	count := 0
@@ -840,7 +1052,7 @@ func TestBTreeIterator3Next(t *testing.T) {
 }
 
 func TestBTreeIterator3Prev(t *testing.T) {
-	tree := NewWithIntComparator(3)
+	tree := NewBTree[int, any](3)
 	tree.Put(1, "a")
 	it := tree.Iterator()
 	for it.Next() {
@@ -866,7 +1078,7 @@ func TestBTreeIterator3Prev(t *testing.T) {
 }
 
 func TestBTreeIterator4Next(t *testing.T) {
-	tree := NewWithIntComparator(3)
+	tree := NewBTree[int, any](3)
 	tree.Put(13, 5)
 	tree.Put(8, 3)
 	tree.Put(17, 7)
@@ -899,7 +1111,7 @@ func TestBTreeIterator4Next(t *testing.T) {
 }
 
 func TestBTreeIterator4Prev(t *testing.T) {
-	tree := NewWithIntComparator(3)
+	tree := NewBTree[int, any](3)
 	tree.Put(13, 5)
 	tree.Put(8, 3)
 	tree.Put(17, 7)
@@ -934,11 +1146,11 @@ func TestBTreeIterator4Prev(t *testing.T) {
 }
 
 func TestBTreeIteratorBegin(t *testing.T) {
-	tree := NewWithIntComparator(3)
+	tree := NewBTree[int, any](3)
 	tree.Put(3, "c")
 	tree.Put(1, "a")
 	tree.Put(2, "b")
-	it := tree.Iterator()
+	it := tree.Iterator().(*Iterator[int, any])
 
 	if it.node != nil {
 		t.Errorf("Got %v expected %v", it.node, nil)
@@ -966,8 +1178,8 @@ func TestBTreeIteratorBegin(t *testing.T) {
 }
 
 func TestBTreeIteratorEnd(t *testing.T) {
-	tree := NewWithIntComparator(3)
-	it := tree.Iterator()
+	tree := NewBTree[int, any](3)
+	it := tree.Iterator().(*Iterator[int, any])
 
 	if it.node != nil {
 		t.Errorf("Got %v expected %v", it.node, nil)
@@ -993,7 +1205,7 @@ func TestBTreeIteratorEnd(t *testing.T) {
 }
 
 func TestBTreeIteratorFirst(t *testing.T) {
-	tree := NewWithIntComparator(3)
+	tree := NewBTree[int, any](3)
 	tree.Put(3, "c")
 	tree.Put(1, "a")
 	tree.Put(2, "b")
@@ -1007,7 +1219,7 @@ func TestBTreeIteratorFirst(t *testing.T) {
 }
 
 func TestBTreeIteratorLast(t *testing.T) {
-	tree := NewWithIntComparator(3)
+	tree := NewBTree[int, any](3)
 	tree.Put(3, "c")
 	tree.Put(1, "a")
 	tree.Put(2, "b")
@@ -1022,13 +1234,13 @@ func TestBTreeIteratorLast(t *testing.T) {
 
 func TestBTree_search(t *testing.T) {
 	{
-		tree := NewWithIntComparator(3)
-		tree.Root = &Node{Entries: []*Entry{}, Children: make([]*Node, 0)}
+		tree := NewBTree[int, any](3)
+		tree.Root = &BNode[int, any]{Entries: []*Entry[int, any]{}, Children: make([]*BNode[int, any], 0)}
 		tests := [][]interface{}{
 			{0, 0, false},
 		}
 		for _, test := range tests {
-			index, found := tree.search(tree.Root, test[0])
+			index, found := tree.search(tree.Root, test[0].(int))
 			if actualValue, expectedValue := index, test[1]; actualValue != expectedValue {
 				t.Errorf("Got %v expected %v", actualValue, expectedValue)
 			}
@@ -1038,8 +1250,8 @@ func TestBTree_search(t *testing.T) {
 		}
 	}
 	{
-		tree := NewWithIntComparator(3)
-		tree.Root = &Node{Entries: []*Entry{{2, 0}, {4, 1}, {6, 2}}, Children: []*Node{}}
+		tree := NewBTree[int, any](3)
+		tree.Root = &BNode[int, any]{Entries: []*Entry[int, any]{{2, 0}, {4, 1}, {6, 2}}, Children: []*BNode[int, any]{}}
 		tests := [][]interface{}{
 			{0, 0, false},
 			{1, 0, false},
@@ -1051,7 +1263,7 @@ func TestBTree_search(t *testing.T) {
 			{7, 3, false},
 		}
 		for _, test := range tests {
-			index, found := tree.search(tree.Root, test[0])
+			index, found := tree.search(tree.Root, test[0].(int))
 			if actualValue, expectedValue := index, test[1]; actualValue != expectedValue {
 				t.Errorf("Got %v expected %v", actualValue, expectedValue)
 			}
@@ -1062,13 +1274,13 @@ func TestBTree_search(t *testing.T) {
 	}
 }
 
-func assertValidTree(t *testing.T, tree *Tree, expectedSize int) {
+func assertValidTree(t *testing.T, tree *BTree[int, any], expectedSize int) {
 	if actualValue, expectedValue := tree.size, expectedSize; actualValue != expectedValue {
 		t.Errorf("Got %v expected %v for tree size", actualValue, expectedValue)
 	}
 }
 
-func assertValidTreeNode(t *testing.T, node *Node, expectedEntries int, expectedChildren int, keys []int, hasParent bool) {
+func assertValidTreeNode(t *testing.T, node *BNode[int, any], expectedEntries int, expectedChildren int, keys []int, hasParent bool) {
 	if actualValue, expectedValue := node.Parent != nil, hasParent; actualValue != expectedValue {
 		t.Errorf("Got %v expected %v for hasParent", actualValue, expectedValue)
 	}
@@ -1085,8 +1297,183 @@ func assertValidTreeNode(t *testing.T, node *Node, expectedEntries int, expected
 	}
 }
 
+func TestBTreeMarshalJSON(t *testing.T) {
+  tree := NewBTree[float64, []int](3)
+  for i, key := range []float64{2.5, -1, 10} {
+    tree.Put(key, []int{i})
+  }
+  data, err := json.Marshal(tree)
+  if actualValue, expectedValue := string(data), `[{"key":-1,"value":[1]},{"key":2.5,"value":[0]},{"key":10,"value":[2]}]`; err != nil || actualValue != expectedValue {
+    t.Errorf("Got %v, %v expected %v", actualValue, err, expectedValue)
+  }
+  decoded := NewBTree[float64, []int](4)
+  if err := json.Unmarshal(data, decoded); err != nil {
+    t.Errorf("Got %v expected %v", err, nil)
+  }
+  if actualValue, expectedValue := decoded.Keys(), []float64{-1, 2.5, 10}; !slices.Equal(actualValue, expectedValue) {
+    t.Errorf("Got %v expected %v", actualValue, expectedValue)
+  }
+  var config struct{ Limits BTree[float64, []int] }
+  if err := json.Unmarshal([]byte(`{"Limits":`+string(data)+`}`), &config); err != nil {
+    t.Errorf("Got %v expected %v", err, nil)
+  }
+  if actualValue, expectedValue := config.Limits.Keys(), []float64{-1, 2.5, 10}; !slices.Equal(actualValue, expectedValue) {
+    t.Errorf("Got %v expected %v", actualValue, expectedValue)
+  }
+  if err := config.Limits.Validate(); err != nil || config.Limits.m != DefaultOrder {
+    t.Errorf("Got %v, order %v expected a valid tree of order %v", err, config.Limits.m, DefaultOrder)
+  }
+  zero := new(BTree[float64, []int])
+  if err := json.Unmarshal([]byte(`{"key":1}`), zero); err == nil || zero.m != 0 {
+    t.Errorf("Got %v, order %v expected an error and the tree untouched", err, zero.m)
+  }
+  if err := json.Unmarshal([]byte(`{"key":1}`), decoded); err == nil || decoded.Size() != 3 {
+    t.Errorf("Got %v, size %v expected an error and the tree untouched", err, decoded.Size())
+  }
+}
+
+func TestBTreeMarshalBinary(t *testing.T) {
+  for _, order := range []int{3, 4, 5, 10} {
+    tree := NewBTree[int, string](order)
+    for i := 100; i > 0; i-- {
+      tree.Put(i, strconv.Itoa(i))
+    }
+    data, err := tree.MarshalBinary()
+    if err != nil {
+      t.Errorf("Got %v expected %v", err, nil)
+    }
+    decoded := new(BTree[int, string])
+    if err := decoded.UnmarshalBinary(data); err != nil {
+      t.Errorf("Got %v expected %v", err, nil)
+    }
+    if actualValue, expectedValue := decoded.m, order; actualValue != expectedValue {
+      t.Errorf("Got %v expected %v", actualValue, expectedValue)
+    }
+    if actualValue, expectedValue := decoded.Keys(), tree.Keys(); !slices.Equal(actualValue, expectedValue) {
+      t.Errorf("Got %v expected %v", actualValue, expectedValue)
+    }
+    if err := decoded.Validate(); err != nil {
+      t.Errorf("order %v: got %v expected %v", order, err, nil)
+    }
+    decoded.Remove(50)
+    decoded.Put(0, "0")
+    if err := decoded.Validate(); err != nil {
+      t.Errorf("order %v: got %v expected %v", order, err, nil)
+    }
+  }
+  tree := NewBTree[int, string](3)
+  tree.Put(1, "1")
+  data, _ := tree.MarshalBinary()
+  decoded := NewBTree[int, string](5)
+  if err := decoded.UnmarshalBinary(data); err != nil || decoded.m != 5 {
+    t.Errorf("Got %v, order %v expected the order of the tree kept", err, decoded.m)
+  }
+  data[len(data)-5] ^= 1
+  if err := decoded.UnmarshalBinary(data); err == nil || !strings.Contains(err.Error(), "checksum") || decoded.Size() != 1 {
+    t.Errorf("Got %v expected a checksum error and the tree untouched", err)
+  }
+}
+
+func TestBTreeEncodeDecode(t *testing.T) {
+  tree := NewBTree[int, int](3)
+  for i := 0; i < 200; i++ {
+    tree.Put(i, i*i)
+  }
+  var buffer bytes.Buffer
+  if err := tree.Encode(&buffer); err != nil {
+    t.Errorf("Got %v expected %v", err, nil)
+  }
+  decoded := NewBTree[int, int](4)
+  if err := decoded.Decode(&buffer); err != nil {
+    t.Errorf("Got %v expected %v", err, nil)
+  }
+  if actualValue, expectedValue := decoded.Keys(), tree.Keys(); !slices.Equal(actualValue, expectedValue) {
+    t.Errorf("Got %v expected %v", actualValue, expectedValue)
+  }
+  if err := decoded.Validate(); err != nil {
+    t.Errorf("Got %v expected %v", err, nil)
+  }
+  if err := decoded.Decode(strings.NewReader(`[{"key":3,"value":9},{"key":1,"value":1},{"key":2,"value":4}]`)); err != nil {
+    t.Errorf("Got %v expected %v", err, nil)
+  }
+  if actualValue, expectedValue := decoded.Keys(), []int{1, 2, 3}; !slices.Equal(actualValue, expectedValue) {
+    t.Errorf("Got %v expected %v", actualValue, expectedValue)
+  }
+  if err := decoded.Decode(strings.NewReader(`{"key":1}`)); err == nil || decoded.Size() != 3 {
+    t.Errorf("Got %v expected an error and the tree untouched", err)
+  }
+  zero := new(BTree[int, int])
+  if err := zero.Decode(strings.NewReader(`[{"key":2,"value":4},{"key":1,"value":1}]`)); err != nil {
+    t.Errorf("Got %v expected %v", err, nil)
+  }
+  if actualValue, expectedValue := zero.m, DefaultOrder; actualValue != expectedValue {
+    t.Errorf("Got %v expected %v", actualValue, expectedValue)
+  }
+  if actualValue, expectedValue := zero.Keys(), []int{1, 2}; !slices.Equal(actualValue, expectedValue) {
+    t.Errorf("Got %v expected %v", actualValue, expectedValue)
+  }
+}
+
+func TestBTreeErrors(t *testing.T) {
+  if _, err := New[int, string](2, utils.OrderedComparator[int]()); !errors.Is(err, gotree.ErrInvalidOrder) {
+    t.Errorf("Got %v expected %v", err, gotree.ErrInvalidOrder)
+  }
+  func() {
+    defer func() {
+      if err, _ := recover().(error); !errors.Is(err, gotree.ErrInvalidOrder) {
+        t.Errorf("Got %v expected a panic with %v", err, gotree.ErrInvalidOrder)
+      }
+    }()
+    NewBTreeWith[int, string](1, utils.OrderedComparator[int]())
+  }()
+  if err := new(BTree[struct{ X int }, int]).Decode(strings.NewReader(`[]`)); err == nil {
+    t.Errorf("Got %v expected an error for keys without a natural order", err)
+  }
+
+  tree, err := New[int, string](3, utils.OrderedComparator[int]())
+  if err != nil {
+    t.Fatalf("Got %v expected %v", err, nil)
+  }
+  tree.Put(1, "a")
+  tree.Put(2, "b")
+  if _, err := gotree.Lookup[int, string](tree, 3); !errors.Is(err, gotree.ErrKeyNotFound) {
+    t.Errorf("Got %v expected %v", err, gotree.ErrKeyNotFound)
+  }
+  if value, err := gotree.Lookup[int, string](tree, 2); value != "b" || err != nil {
+    t.Errorf("Got %v, %v expected %v, %v", value, err, "b", nil)
+  }
+
+  data, _ := tree.MarshalBinary()
+  reversed, _ := New[int, string](3, func(a, b int) int { return b - a })
+  err = reversed.UnmarshalBinary(data)
+  var decodeError *gotree.DecodeError
+  if !errors.Is(err, gotree.ErrComparatorMismatch) || !errors.As(err, &decodeError) {
+    t.Fatalf("Got %v expected a *gotree.DecodeError wrapping %v", err, gotree.ErrComparatorMismatch)
+  }
+  if actualValue, expectedValue := decodeError.Entry, 1; actualValue != expectedValue {
+    t.Errorf("Got %v expected %v", actualValue, expectedValue)
+  }
+  if actualValue, expectedValue := decodeError.Key, any(2); actualValue != expectedValue {
+    t.Errorf("Got %v expected %v", actualValue, expectedValue)
+  }
+  if decodeError.Offset <= 0 || decodeError.Offset >= int64(len(data)) {
+    t.Errorf("Got offset %v expected one within the %v bytes of data", decodeError.Offset, len(data))
+  }
+
+  err = tree.UnmarshalJSON([]byte(`[{"key":1,"value":"a"},{"key":"x","value":"b"}]`))
+  if !errors.Is(err, gotree.ErrCorruptData) || !errors.As(err, &decodeError) || decodeError.Entry != 1 {
+    t.Errorf("Got %v expected a *gotree.DecodeError of entry 1 wrapping %v", err, gotree.ErrCorruptData)
+  }
+  if err := tree.UnmarshalJSON([]byte(`[{"key":1,`)); !errors.Is(err, gotree.ErrCorruptData) {
+    t.Errorf("Got %v expected %v", err, gotree.ErrCorruptData)
+  }
+  if actualValue, expectedValue := tree.Size(), 2; actualValue != expectedValue {
+    t.Errorf("Got %v expected %v", actualValue, expectedValue)
+  }
+}
+
 func TestBTreeSerialization(t *testing.T) {
-	tree := NewWithStringComparator(3)
+	tree := NewBTree[string, string](3)
 	tree.Put("c", "3")
 	tree.Put("b", "2")
 	tree.Put("a", "1")
@@ -1096,10 +1483,10 @@ func TestBTreeSerialization(t *testing.T) {
 		if actualValue, expectedValue := tree.Size(), 3; actualValue != expectedValue {
 			t.Errorf("Got %v expected %v", actualValue, expectedValue)
 		}
-		if actualValue := tree.Keys(); actualValue[0].(string) != "a" || actualValue[1].(string) != "b" || actualValue[2].(string) != "c" {
+		if actualValue := tree.Keys(); actualValue[0] != "a" || actualValue[1] != "b" || actualValue[2] != "c" {
 			t.Errorf("Got %v expected %v", actualValue, "[a,b,c]")
 		}
-		if actualValue := tree.Values(); actualValue[0].(string) != "1" || actualValue[1].(string) != "2" || actualValue[2].(string) != "3" {
+		if actualValue := tree.Values(); actualValue[0] != "1" || actualValue[1] != "2" || actualValue[2] != "3" {
 			t.Errorf("Got %v expected %v", actualValue, "[1,2,3]")
 		}
 		if err != nil {
@@ -1116,7 +1503,7 @@ func TestBTreeSerialization(t *testing.T) {
 	assert()
 }
 
-func benchmarkGet(b *testing.B, tree *Tree, size int) {
+func benchmarkGet(b *testing.B, tree *BTree[int, any], size int) {
 	for i := 0; i < b.N; i++ {
 		for n := 0; n < size; n++ {
 			tree.Get(n)
@@ -1124,7 +1511,7 @@ func benchmarkGet(b *testing.B, tree *Tree, size int) {
 	}
 }
 
-func benchmarkPut(b *testing.B, tree *Tree, size int) {
+func benchmarkPut(b *testing.B, tree *BTree[int, any], size int) {
 	for i := 0; i < b.N; i++ {
 		for n := 0; n < size; n++ {
 			tree.Put(n, struct{}{})
@@ -1132,7 +1519,7 @@ func benchmarkPut(b *testing.B, tree *Tree, size int) {
 	}
 }
 
-func benchmarkRemove(b *testing.B, tree *Tree, size int) {
+func benchmarkRemove(b *testing.B, tree *BTree[int, any], size int) {
 	for i := 0; i < b.N; i++ {
 		for n := 0; n < size; n++ {
 			tree.Remove(n)
@@ -1143,7 +1530,7 @@ func benchmarkRemove(b *testing.B, tree *Tree, size int) {
 func BenchmarkBTreeGet100(b *testing.B) {
 	b.StopTimer()
 	size := 100
-	tree := NewWithIntComparator(128)
+	tree := NewBTree[int, any](128)
 	for n := 0; n < size; n++ {
 		tree.Put(n, struct{}{})
 	}
@@ -1154,7 +1541,7 @@ func BenchmarkBTreeGet100(b *testing.B) {
 func BenchmarkBTreeGet1000(b *testing.B) {
 	b.StopTimer()
 	size := 1000
-	tree := NewWithIntComparator(128)
+	tree := NewBTree[int, any](128)
 	for n := 0; n < size; n++ {
 		tree.Put(n, struct{}{})
 	}
@@ -1165,7 +1552,7 @@ func BenchmarkBTreeGet1000(b *testing.B) {
 func BenchmarkBTreeGet10000(b *testing.B) {
 	b.StopTimer()
 	size := 10000
-	tree := NewWithIntComparator(128)
+	tree := NewBTree[int, any](128)
 	for n := 0; n < size; n++ {
 		tree.Put(n, struct{}{})
 	}
@@ -1176,7 +1563,7 @@ func BenchmarkBTreeGet10000(b *testing.B) {
 func BenchmarkBTreeGet100000(b *testing.B) {
 	b.StopTimer()
 	size := 100000
-	tree := NewWithIntComparator(128)
+	tree := NewBTree[int, any](128)
 	for n := 0; n < size; n++ {
 		tree.Put(n, struct{}{})
 	}
@@ -1187,7 +1574,7 @@ func BenchmarkBTreeGet100000(b *testing.B) {
 func BenchmarkBTreePut100(b *testing.B) {
 	b.StopTimer()
 	size := 100
-	tree := NewWithIntComparator(128)
+	tree := NewBTree[int, any](128)
 	b.StartTimer()
 	benchmarkPut(b, tree, size)
 }
@@ -1195,7 +1582,7 @@ func BenchmarkBTreePut100(b *testing.B) {
 func BenchmarkBTreePut1000(b *testing.B) {
 	b.StopTimer()
 	size := 1000
-	tree := NewWithIntComparator(128)
+	tree := NewBTree[int, any](128)
 	for n := 0; n < size; n++ {
 		tree.Put(n, struct{}{})
 	}
@@ -1206,7 +1593,7 @@ func BenchmarkBTreePut1000(b *testing.B) {
 func BenchmarkBTreePut10000(b *testing.B) {
 	b.StopTimer()
 	size := 10000
-	tree := NewWithIntComparator(128)
+	tree := NewBTree[int, any](128)
 	for n := 0; n < size; n++ {
 		tree.Put(n, struct{}{})
 	}
@@ -1217,7 +1604,7 @@ func BenchmarkBTreePut10000(b *testing.B) {
 func BenchmarkBTreePut100000(b *testing.B) {
 	b.StopTimer()
 	size := 100000
-	tree := NewWithIntComparator(128)
+	tree := NewBTree[int, any](128)
 	for n := 0; n < size; n++ {
 		tree.Put(n, struct{}{})
 	}
@@ -1228,7 +1615,7 @@ func BenchmarkBTreePut100000(b *testing.B) {
 func BenchmarkBTreeRemove100(b *testing.B) {
 	b.StopTimer()
 	size := 100
-	tree := NewWithIntComparator(128)
+	tree := NewBTree[int, any](128)
 	for n := 0; n < size; n++ {
 		tree.Put(n, struct{}{})
 	}
@@ -1239,7 +1626,7 @@ func BenchmarkBTreeRemove100(b *testing.B) {
 func BenchmarkBTreeRemove1000(b *testing.B) {
 	b.StopTimer()
 	size := 1000
-	tree := NewWithIntComparator(128)
+	tree := NewBTree[int, any](128)
 	for n := 0; n < size; n++ {
 		tree.Put(n, struct{}{})
 	}
@@ -1250,7 +1637,7 @@ func BenchmarkBTreeRemove1000(b *testing.B) {
 func BenchmarkBTreeRemove10000(b *testing.B) {
 	b.StopTimer()
 	size := 10000
-	tree := NewWithIntComparator(128)
+	tree := NewBTree[int, any](128)
 	for n := 0; n < size; n++ {
 		tree.Put(n, struct{}{})
 	}
@@ -1261,7 +1648,7 @@ func BenchmarkBTreeRemove10000(b *testing.B) {
 func BenchmarkBTreeRemove100000(b *testing.B) {
 	b.StopTimer()
 	size := 100000
-	tree := NewWithIntComparator(128)
+	tree := NewBTree[int, any](128)
 	for n := 0; n < size; n++ {
 		tree.Put(n, struct{}{})
 	}
@@ -1,28 +1,36 @@
 package btree
 
 import (
+  "encoding";
   "encoding/json";
-  "github.com/jenazads/goutils";
+  "fmt";
+  "io";
+  "github.com/fmorenovr/gods/trees";
+  "github.com/fmorenovr/gods/utils";
 )
 
 func assertSerializationImplementation() {
-  var _ goutils.JSONSerializer = (*BTree)(nil)
-  var _ goutils.JSONDeserializer = (*BTree)(nil)
+  var _ utils.JSONSerializer = (*BTree[string, int])(nil)
+  var _ utils.JSONDeserializer = (*BTree[string, int])(nil)
+  var _ json.Marshaler = (*BTree[string, int])(nil)
+  var _ json.Unmarshaler = (*BTree[string, int])(nil)
+  var _ encoding.BinaryMarshaler = (*BTree[string, int])(nil)
+  var _ encoding.BinaryUnmarshaler = (*BTree[string, int])(nil)
 }
 
 // ToJSON return JSON format of elements
-func (t *BTree) ToJSON() ([]byte, error) {
-  elements := make(map[string]interface{})
+func (t *BTree[K, V]) ToJSON() ([]byte, error) {
+  elements := make(map[K]V)
   it := t.Iterator()
   for it.Next() {
-    elements[goutils.ToString(it.Key())] = it.Value()
+    elements[it.Key()] = it.Value()
   }
   return json.Marshal(&elements)
 }
 
 // FromJSON Convert to JSON format the elements
-func (t *BTree) FromJSON(data []byte) error {
-  elements := make(map[string]interface{})
+func (t *BTree[K, V]) FromJSON(data []byte) error {
+  elements := make(map[K]V)
   err := json.Unmarshal(data, &elements)
   if err == nil {
     t.Clear()
@@ -30,5 +38,116 @@ func (t *BTree) FromJSON(data []byte) error {
       t.Put(key, value)
     }
   }
-  return err
+  return gotree.CorruptJSON(err)
+}
+
+// SetCodecs sets how the JSON and binary forms encode keys and values, nil keeps the defaults
+// (encoding/json and utils.BinaryCodec). In JSON the codecs must write JSON values.
+func (t *BTree[K, V]) SetCodecs(keys utils.Codec[K], values utils.Codec[V]) {
+  t.keyCodec, t.valueCodec = keys, values
+}
+
+// MarshalJSON writes the tree as [{"key":..,"value":..}, ...] in key order, keys keep their JSON type
+func (t *BTree[K, V]) MarshalJSON() ([]byte, error) {
+  return gotree.MarshalEntries(t.All(), t.keyCodec, t.valueCodec)
+}
+
+// UnmarshalJSON replaces the contents of the tree with the entries written by MarshalJSON.
+// A zero-value tree, as json.Unmarshal allocates it, takes DefaultOrder and orders keys with
+// utils.DefaultComparator.
+func (t *BTree[K, V]) UnmarshalJSON(data []byte) error {
+  order, comparator, err := t.decodingOrder()
+  if err != nil {
+    return err
+  }
+  keys, values, err := gotree.UnmarshalEntries(data, t.keyCodec, t.valueCodec)
+  if err != nil {
+    return err
+  }
+  t.m, t.comparator = order, comparator
+  t.Clear()
+  for i, key := range keys {
+    t.Put(key, values[i])
+  }
+  return nil
+}
+
+// MarshalBinary writes the tree and its order in the binary form of gotree.MarshalBinaryEntries,
+// in key order
+func (t *BTree[K, V]) MarshalBinary() ([]byte, error) {
+  return gotree.MarshalBinaryEntries(gotree.BinaryHeader{Kind: "btree", Order: t.m, Count: t.size}, t.All(), t.keyCodec, t.valueCodec)
+}
+
+// UnmarshalBinary replaces the contents of the tree with the entries written by MarshalBinary,
+// appending the sorted entries to the rightmost leaf instead of searching for each. Corrupt
+// data is an error and leaves the tree untouched. A zero-value tree takes the written order
+// and orders keys with utils.DefaultComparator, a tree with an order keeps it.
+func (t *BTree[K, V]) UnmarshalBinary(data []byte) error {
+  comparator := t.comparator
+  if comparator == nil {
+    if comparator = utils.DefaultComparator[K](); comparator == nil {
+      return fmt.Errorf("btree: no natural order for keys of type %T, build the tree with NewBTreeWith", *new(K))
+    }
+  }
+  header, keys, values, err := gotree.UnmarshalBinaryEntries(data, "btree", comparator, t.keyCodec, t.valueCodec)
+  if err != nil {
+    return err
+  }
+  if t.m < 3 {
+    if header.Order < 3 {
+      return gotree.CorruptEntry(-1, -1, invalidOrder(header.Order))
+    }
+    t.m = header.Order
+  }
+  t.comparator = comparator
+  t.build(keys, values)
+  return nil
+}
+
+// Encode streams the tree to w in the JSON form of MarshalJSON, one entry at a time
+func (t *BTree[K, V]) Encode(w io.Writer) error {
+  return gotree.EncodeEntries(w, t.All(), t.keyCodec, t.valueCodec)
+}
+
+// Decode replaces the contents of the tree with the JSON form of MarshalJSON read from r one entry
+// at a time. Entries in key order, as Encode writes them, are appended to the rightmost leaf as they
+// come; from the first entry out of order on, the rest is put one by one. An error leaves the tree
+// untouched. A zero-value tree takes DefaultOrder and orders keys with utils.DefaultComparator.
+func (t *BTree[K, V]) Decode(r io.Reader) error {
+  order, comparator, err := t.decodingOrder()
+  if err != nil {
+    return err
+  }
+  loaded := &BTree[K, V]{m: order, comparator: comparator}
+  var leaf *BNode[K, V]
+  sorted := true
+  err = gotree.DecodeEntries(r, t.keyCodec, t.valueCodec, func(key K, value V) error {
+    if sorted && (leaf == nil || comparator(leaf.Entries[len(leaf.Entries)-1].Key, key) < 0) {
+      leaf = loaded.appendEntry(leaf, NewEntry(key, value))
+      return nil
+    }
+    sorted = false
+    loaded.Put(key, value)
+    return nil
+  })
+  if err != nil {
+    return err
+  }
+  t.Root, t.size, t.m, t.comparator = loaded.Root, loaded.size, order, comparator
+  return nil
+}
+
+// decodingOrder returns the order and comparator the JSON forms decode with: those of the tree,
+// DefaultOrder and utils.DefaultComparator for a zero-value tree
+func (t *BTree[K, V]) decodingOrder() (int, utils.Comparator[K], error) {
+  order, comparator := t.m, t.comparator
+  if order < 3 {
+    order = DefaultOrder
+  }
+  if comparator == nil {
+    if comparator = utils.DefaultComparator[K](); comparator == nil {
+      return 0, nil, fmt.Errorf("btree: no natural order for keys of type %T, build the tree with NewBTreeWith", *new(K))
+    }
+  }
+  return order, comparator, nil
 }
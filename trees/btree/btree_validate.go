@@ -0,0 +1,85 @@
+package btree
+
+import (
+  "fmt"
+)
+
+// Validate checks the B-tree invariants: entry and child counts against the order,
+// key order inside and across nodes, parent pointers, leaves all at the same depth
+// and the number of keys. It returns nil for a sound tree or an error naming the
+// first offending node.
+func (t *BTree[K, V]) Validate() error {
+  if t.Root == nil {
+    if t.size != 0 {
+      return fmt.Errorf("btree: empty tree has size %v", t.size)
+    }
+    return nil
+  }
+  if t.Root.Parent != nil {
+    return fmt.Errorf("btree: root %v has a parent", t.Root)
+  }
+  leafDepth := -1
+  size, err := t.validate(t.Root, nil, nil, 0, &leafDepth)
+  if err != nil {
+    return err
+  }
+  if size != t.size {
+    return fmt.Errorf("btree: tree size is %v but it holds %v keys", t.size, size)
+  }
+  return nil
+}
+
+// validate checks the subtree of node, whose keys must lie strictly between low and high
+// (nil is unbounded), and returns its number of keys
+func (t *BTree[K, V]) validate(node *BNode[K, V], low, high *Entry[K, V], depth int, leafDepth *int) (int, error) {
+  if len(node.Entries) == 0 {
+    return 0, fmt.Errorf("btree: node at depth %v has no entries", depth)
+  }
+  if len(node.Entries) > t.maxEntries() {
+    return 0, fmt.Errorf("btree: node %v has %v entries, at most %v allowed", node, len(node.Entries), t.maxEntries())
+  }
+  if node != t.Root && len(node.Entries) < t.minEntries() {
+    return 0, fmt.Errorf("btree: node %v has %v entries, at least %v required", node, len(node.Entries), t.minEntries())
+  }
+  for i, entry := range node.Entries {
+    if i > 0 && t.comparator(node.Entries[i-1].Key, entry.Key) >= 0 {
+      return 0, fmt.Errorf("btree: node %v has key %v after %v", node, entry.Key, node.Entries[i-1].Key)
+    }
+    if low != nil && t.comparator(entry.Key, low.Key) <= 0 {
+      return 0, fmt.Errorf("btree: node %v has key %v, not greater than its separator %v", node, entry.Key, low.Key)
+    }
+    if high != nil && t.comparator(entry.Key, high.Key) >= 0 {
+      return 0, fmt.Errorf("btree: node %v has key %v, not smaller than its separator %v", node, entry.Key, high.Key)
+    }
+  }
+  if IsLeaf(node) {
+    if *leafDepth == -1 {
+      *leafDepth = depth
+    } else if *leafDepth != depth {
+      return 0, fmt.Errorf("btree: leaf %v is at depth %v, other leaves at depth %v", node, depth, *leafDepth)
+    }
+    return len(node.Entries), nil
+  }
+  if len(node.Children) != len(node.Entries)+1 || len(node.Children) > t.maxChildren() {
+    return 0, fmt.Errorf("btree: node %v has %v entries and %v children", node, len(node.Entries), len(node.Children))
+  }
+  size := len(node.Entries)
+  for i, child := range node.Children {
+    if child.Parent != node {
+      return 0, fmt.Errorf("btree: node %v has child %v whose parent is not %v", node, child, node)
+    }
+    childLow, childHigh := low, high
+    if i > 0 {
+      childLow = node.Entries[i-1]
+    }
+    if i < len(node.Entries) {
+      childHigh = node.Entries[i]
+    }
+    childSize, err := t.validate(child, childLow, childHigh, depth+1, leafDepth)
+    if err != nil {
+      return 0, err
+    }
+    size += childSize
+  }
+  return size, nil
+}
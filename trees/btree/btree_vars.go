@@ -1,17 +1,16 @@
 package btree
 
 import (
-//  "fmt";
-//  "github.com/jenazads/goutils";
-  "github.com/jenazads/gods/trees";
+  "github.com/fmorenovr/gods/trees";
 )
 
 func assertTreeImplementation() {
-  var _ gotree.GoTree = (*BTree)(nil)
+  var _ gotree.GoTree[int] = (*BTree[string, int])(nil)
+  var _ gotree.RangeMap[string, int] = (*BTree[string, int])(nil)
 }
 
 // Entry is the key-value pair in node
-type Entry struct {
-  Key   interface{}
-  Value interface{}
+type Entry[K comparable, V any] struct {
+  Key   K
+  Value V
 }
@@ -0,0 +1,549 @@
+package disk
+
+import (
+  "errors"
+  "fmt"
+  "os"
+
+  "github.com/fmorenovr/gods/trees"
+  "github.com/fmorenovr/gods/trees/btree"
+  "github.com/fmorenovr/gods/utils"
+)
+
+// Default options of Open
+const (
+  DefaultPageSize  = 4096
+  DefaultOrder     = 32
+  DefaultCacheSize = 128
+)
+
+// ErrEntryTooLarge is returned by Put for a key and value too large to fit m-1 of them in a page
+var ErrEntryTooLarge = errors.New("disk: entry too large for the page size and order")
+
+// ErrClosed is returned by every operation on a closed tree
+var ErrClosed = errors.New("disk: tree is closed")
+
+// Options of Open, zero fields take the defaults
+type Options[K, V any] struct {
+  Order      int            // Order of a new file (maximum number of children), an existing file keeps its own
+  PageSize   int            // Page size of a new file in bytes, an existing file keeps its own
+  CacheSize  int            // Number of decoded pages kept in memory, and of modified ones staged before a Sync
+  KeyCodec   utils.Codec[K] // Encodes keys in the pages, nil for utils.BinaryCodec
+  ValueCodec utils.Codec[V] // Encodes values in the pages, nil for utils.BinaryCodec
+}
+
+// BTree is a B-tree whose nodes are the pages of a file
+type BTree[K comparable, V any] struct {
+  file       *os.File
+  comparator utils.Comparator[K]
+  keyCodec   utils.Codec[K]
+  valueCodec utils.Codec[V]
+  m          int               // order (maximum number of children)
+  pageSize   int               // size of every page in bytes
+  root       uint64            // page of the root node, 0 when the tree is empty
+  size       int               // total number of keys in the tree
+  free       uint64            // first page of the free list, 0 when it is empty
+  pages      uint64            // number of pages in the file, the header included
+  cache      *cache[K, V]      // decoded nodes, the modified ones not staged yet
+  pending    map[uint64][]byte // pages staged since the last Sync, with their checksum
+  modified   bool              // something changed since the last Sync
+  journal    string            // path of the journal
+  err        error             // first I/O error, every later operation returns it
+}
+
+// node is a decoded page, a leaf when it has no children
+type node[K comparable, V any] struct {
+  id       uint64
+  entries  []btree.Entry[K, V]
+  children []uint64
+  dirty    bool // modified since it was read or written
+}
+
+// frame is one node of a path from the root: the index of the child the path goes down to, or,
+// for the last node, of the entry it ends at
+type frame[K comparable, V any] struct {
+  node  *node[K, V]
+  index int
+}
+
+// Open opens the B-tree stored in the file at path, creating the file if it does not exist.
+// A nil comparator stands for utils.DefaultComparator. Options that contradict an existing file,
+// an order or a page size different from the written one, are an error.
+func Open[K comparable, V any](path string, comparator utils.Comparator[K], options Options[K, V]) (*BTree[K, V], error) {
+  if comparator == nil {
+    if comparator = utils.DefaultComparator[K](); comparator == nil {
+      return nil, fmt.Errorf("disk: no natural order for keys of type %T, give a comparator", *new(K))
+    }
+  }
+  if options.CacheSize <= 0 {
+    options.CacheSize = DefaultCacheSize
+  }
+  if options.KeyCodec == nil {
+    options.KeyCodec = utils.BinaryCodec[K]{}
+  }
+  if options.ValueCodec == nil {
+    options.ValueCodec = utils.BinaryCodec[V]{}
+  }
+  file, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE, 0o644)
+  if err != nil {
+    return nil, err
+  }
+  t := &BTree[K, V]{
+    file:       file,
+    comparator: comparator,
+    keyCodec:   options.KeyCodec,
+    valueCodec: options.ValueCodec,
+    cache:      newCache[K, V](options.CacheSize),
+    pending:    make(map[uint64][]byte),
+    journal:    path + JournalSuffix,
+  }
+  if err := replayJournal(file, t.journal); err != nil {
+    file.Close()
+    return nil, fmt.Errorf("disk: %v: %w", t.journal, err)
+  }
+  if err := t.open(options); err != nil {
+    file.Close()
+    return nil, fmt.Errorf("disk: %v: %w", path, err)
+  }
+  return t, nil
+}
+
+// open reads the header of the file, or writes the one of an empty tree in an empty file
+func (t *BTree[K, V]) open(options Options[K, V]) error {
+  info, err := t.file.Stat()
+  if err != nil {
+    return err
+  }
+  if info.Size() == 0 {
+    t.m, t.pageSize, t.pages = options.Order, options.PageSize, 1
+    if t.m == 0 {
+      t.m = DefaultOrder
+    }
+    if t.pageSize == 0 {
+      t.pageSize = DefaultPageSize
+    }
+    if err := t.checkLayout(); err != nil {
+      return err
+    }
+    t.writeHeader()
+    return t.Sync()
+  }
+  if err := t.readHeader(); err != nil {
+    return err
+  }
+  if options.Order != 0 && options.Order != t.m {
+    return fmt.Errorf("%w %v, the file has order %v", gotree.ErrInvalidOrder, options.Order, t.m)
+  }
+  if options.PageSize != 0 && options.PageSize != t.pageSize {
+    return fmt.Errorf("page size %v, the file has pages of %v bytes", options.PageSize, t.pageSize)
+  }
+  return t.checkLayout()
+}
+
+// checkLayout checks that the order is valid and leaves room in a page for m-1 entries
+func (t *BTree[K, V]) checkLayout() error {
+  if t.m < 3 {
+    return fmt.Errorf("%w %v, should be at least 3", gotree.ErrInvalidOrder, t.m)
+  }
+  if t.pageSize < headerSize {
+    return fmt.Errorf("page size %v, should be at least %v", t.pageSize, headerSize)
+  }
+  if t.maxEntrySize() < 2 {
+    return fmt.Errorf("%w %v, no room for its entries in pages of %v bytes", gotree.ErrInvalidOrder, t.m, t.pageSize)
+  }
+  return nil
+}
+
+// Sync writes the modified pages and the header to the file, through the journal, and flushes it
+// to stable storage
+func (t *BTree[K, V]) Sync() error {
+  if t.err != nil {
+    return t.err
+  }
+  if !t.modified {
+    return nil
+  }
+  for _, n := range t.cache.nodes() {
+    if err := t.writeNode(n); err != nil {
+      return t.fail(err)
+    }
+  }
+  t.writeHeader()
+  if err := t.commit(); err != nil {
+    return t.fail(err)
+  }
+  t.modified = false
+  return nil
+}
+
+// Close syncs the tree and closes the file. After an I/O error it closes the file without
+// writing anything more and returns that error.
+func (t *BTree[K, V]) Close() error {
+  if t.err == ErrClosed {
+    return ErrClosed
+  }
+  err := t.Sync()
+  if closeErr := t.file.Close(); err == nil {
+    err = closeErr
+  }
+  t.err, t.cache = ErrClosed, newCache[K, V](0)
+  return err
+}
+
+// fail makes err, if any, the sticky error of the tree
+func (t *BTree[K, V]) fail(err error) error {
+  if err != nil && t.err == nil {
+    t.err = err
+  }
+  return err
+}
+
+// done ends an operation: it evicts the nodes the cache has no room for, staging the modified
+// ones, and syncs once more pages than the cache holds are staged, to bound the memory they take
+func (t *BTree[K, V]) done() error {
+  if t.err != nil {
+    return t.err
+  }
+  for _, n := range t.cache.evict() {
+    if err := t.writeNode(n); err != nil {
+      return t.fail(err)
+    }
+  }
+  if len(t.pending) > t.cache.capacity {
+    return t.Sync()
+  }
+  return nil
+}
+
+// IsEmpty, true if tree doesnt have nodes
+func (t *BTree[K, V]) IsEmpty() bool {
+  return t.size == 0
+}
+
+// Return Size of tree
+func (t *BTree[K, V]) Size() int {
+  return t.size
+}
+
+// Len returns the number of keys in the tree, same as Size
+func (t *BTree[K, V]) Len() int {
+  return t.size
+}
+
+// Order returns the maximum number of children of a node
+func (t *BTree[K, V]) Order() int {
+  return t.m
+}
+
+// Clear removes all keys, drops the changes not synced yet, then syncs and truncates the file to
+// its header
+func (t *BTree[K, V]) Clear() error {
+  if t.err != nil {
+    return t.err
+  }
+  t.cache = newCache[K, V](t.cache.capacity)
+  t.pending = make(map[uint64][]byte)
+  t.root, t.size, t.free, t.pages = 0, 0, 0, 1
+  t.modified = true
+  return t.Sync()
+}
+
+// Put inserts key-value pair node into the tree
+func (t *BTree[K, V]) Put(key K, value V) error {
+  if t.err != nil {
+    return t.err
+  }
+  entry := btree.Entry[K, V]{Key: key, Value: value}
+  if err := t.checkEntry(entry); err != nil {
+    return err
+  }
+  if t.root == 0 {
+    root, err := t.alloc()
+    if err != nil {
+      return t.fail(err)
+    }
+    root.entries = append(root.entries, entry)
+    t.root = root.id
+    t.size++
+    return t.done()
+  }
+  path, found, err := t.searchPath(key)
+  if err != nil {
+    return t.fail(err)
+  }
+  last := path[len(path)-1]
+  if found {
+    last.node.entries[last.index] = entry
+    t.touch(last.node)
+    return t.done()
+  }
+  // Insert entry's key in the middle of the leaf
+  last.node.entries = append(last.node.entries, btree.Entry[K, V]{})
+  copy(last.node.entries[last.index+1:], last.node.entries[last.index:])
+  last.node.entries[last.index] = entry
+  t.touch(last.node)
+  t.size++
+  if err := t.split(path); err != nil {
+    return t.fail(err)
+  }
+  return t.done()
+}
+
+// Get searches the key and returns its value, found is false if the key is not in the tree
+func (t *BTree[K, V]) Get(key K) (value V, found bool, err error) {
+  if t.err != nil {
+    return value, false, t.err
+  }
+  if t.root == 0 {
+    return value, false, nil
+  }
+  path, found, err := t.searchPath(key)
+  if err != nil {
+    return value, false, t.fail(err)
+  }
+  if found {
+    last := path[len(path)-1]
+    value = last.node.entries[last.index].Value
+  }
+  return value, found, t.done()
+}
+
+// Contains returns true if the key is in the tree
+func (t *BTree[K, V]) Contains(key K) (bool, error) {
+  _, found, err := t.Get(key)
+  return found, err
+}
+
+// Remove Node by key, does nothing if the key is not in the tree
+func (t *BTree[K, V]) Remove(key K) error {
+  if t.err != nil {
+    return t.err
+  }
+  if t.root == 0 {
+    return nil
+  }
+  path, found, err := t.searchPath(key)
+  if err != nil {
+    return t.fail(err)
+  }
+  if !found {
+    return t.done()
+  }
+  if err := t.delete(path); err != nil {
+    return t.fail(err)
+  }
+  t.size--
+  return t.done()
+}
+
+// maxEntries, minEntries and middle follow btree.BTree
+func (t *BTree[K, V]) maxEntries() int {
+  return t.m - 1
+}
+
+func (t *BTree[K, V]) minEntries() int {
+  return (t.m+1)/2 - 1 // ceil(m/2) children
+}
+
+func (t *BTree[K, V]) middle() int {
+  return (t.m - 1) / 2 // "-1" to favor right nodes to have more keys when splitting
+}
+
+// search searches only within the single node among its entries
+func (t *BTree[K, V]) search(n *node[K, V], key K) (index int, found bool) {
+  low, high := 0, len(n.entries)-1
+  for low <= high {
+    mid := (high + low) / 2
+    compare := t.comparator(key, n.entries[mid].Key)
+    switch {
+    case compare > 0:
+      low = mid + 1
+    case compare < 0:
+      high = mid - 1
+    default:
+      return mid, true
+    }
+  }
+  return low, false
+}
+
+// searchPath returns the path from the root of a non-empty tree down to the entry of key, or,
+// when key is not in the tree, down to the position in a leaf where it would be inserted
+func (t *BTree[K, V]) searchPath(key K) (path []frame[K, V], found bool, err error) {
+  id := t.root
+  for {
+    n, err := t.node(id)
+    if err != nil {
+      return nil, false, err
+    }
+    index, found := t.search(n, key)
+    path = append(path, frame[K, V]{n, index})
+    if found || n.isLeaf() {
+      return path, found, nil
+    }
+    id = n.children[index]
+  }
+}
+
+// split splits the last node of path if it holds more than m-1 entries, moving its middle entry
+// up into its parent, which may split in turn
+func (t *BTree[K, V]) split(path []frame[K, V]) error {
+  n := path[len(path)-1].node
+  if len(n.entries) <= t.maxEntries() {
+    return nil
+  }
+  middle := t.middle()
+  right, err := t.alloc()
+  if err != nil {
+    return err
+  }
+  separator := n.entries[middle]
+  right.entries = append(right.entries, n.entries[middle+1:]...)
+  n.entries = append([]btree.Entry[K, V](nil), n.entries[:middle]...)
+  // Move children from the node to be split into left and right nodes
+  if !n.isLeaf() {
+    right.children = append(right.children, n.children[middle+1:]...)
+    n.children = append([]uint64(nil), n.children[:middle+1]...)
+  }
+  t.touch(n)
+
+  if len(path) == 1 {
+    // Root is a node with one entry and two children (left and right)
+    root, err := t.alloc()
+    if err != nil {
+      return err
+    }
+    root.entries = []btree.Entry[K, V]{separator}
+    root.children = []uint64{n.id, right.id}
+    t.root = root.id
+    return nil
+  }
+
+  // Insert middle key into parent, right of the child index the path went down to
+  parent := path[len(path)-2]
+  parent.node.entries = append(parent.node.entries, btree.Entry[K, V]{})
+  copy(parent.node.entries[parent.index+1:], parent.node.entries[parent.index:])
+  parent.node.entries[parent.index] = separator
+  parent.node.children = append(parent.node.children, 0)
+  copy(parent.node.children[parent.index+2:], parent.node.children[parent.index+1:])
+  parent.node.children[parent.index+1] = right.id
+  t.touch(parent.node)
+  return t.split(path[:len(path)-1])
+}
+
+// delete deletes the entry the path ends at
+// ref.: https://en.wikipedia.org/wiki/B-tree#Deletion
+func (t *BTree[K, V]) delete(path []frame[K, V]) error {
+  last := path[len(path)-1]
+  if !last.node.isLeaf() {
+    // replace the entry with the largest entry of its left sub-tree, then delete that one
+    id := last.node.children[last.index]
+    for {
+      n, err := t.node(id)
+      if err != nil {
+        return err
+      }
+      if n.isLeaf() {
+        path = append(path, frame[K, V]{n, len(n.entries) - 1})
+        break
+      }
+      path = append(path, frame[K, V]{n, len(n.children) - 1})
+      id = n.children[len(n.children)-1]
+    }
+    leaf := path[len(path)-1]
+    last.node.entries[last.index] = leaf.node.entries[leaf.index]
+    t.touch(last.node)
+    last = leaf
+  }
+  last.node.entries = deleteEntry(last.node.entries, last.index)
+  t.touch(last.node)
+  return t.rebalance(path)
+}
+
+// rebalance rebalances the last node of path after a deletion, borrowing an entry from a sibling
+// through the parent or merging it with a sibling, which may leave the parent to rebalance
+func (t *BTree[K, V]) rebalance(path []frame[K, V]) error {
+  n := path[len(path)-1].node
+  if len(path) == 1 {
+    // the root may only run out of entries: drop it
+    if len(n.entries) > 0 {
+      return nil
+    }
+    t.root = 0
+    if !n.isLeaf() {
+      t.root = n.children[0]
+    }
+    t.release(n)
+    return nil
+  }
+  if len(n.entries) >= t.minEntries() {
+    return nil
+  }
+  parent, index := path[len(path)-2].node, path[len(path)-2].index
+
+  var left, right *node[K, V]
+  var err error
+  if index > 0 {
+    if left, err = t.node(parent.children[index-1]); err != nil {
+      return err
+    }
+  }
+  if index+1 < len(parent.children) {
+    if right, err = t.node(parent.children[index+1]); err != nil {
+      return err
+    }
+  }
+
+  // try to borrow from left sibling
+  if left != nil && len(left.entries) > t.minEntries() {
+    // rotate right
+    n.entries = append([]btree.Entry[K, V]{parent.entries[index-1]}, n.entries...)
+    parent.entries[index-1] = left.entries[len(left.entries)-1]
+    left.entries = deleteEntry(left.entries, len(left.entries)-1)
+    if !left.isLeaf() {
+      n.children = append([]uint64{left.children[len(left.children)-1]}, n.children...)
+      left.children = left.children[:len(left.children)-1]
+    }
+    t.touch(n, left, parent)
+    return nil
+  }
+
+  // try to borrow from right sibling
+  if right != nil && len(right.entries) > t.minEntries() {
+    // rotate left
+    n.entries = append(n.entries, parent.entries[index])
+    parent.entries[index] = right.entries[0]
+    right.entries = deleteEntry(right.entries, 0)
+    if !right.isLeaf() {
+      n.children = append(n.children, right.children[0])
+      right.children = append([]uint64(nil), right.children[1:]...)
+    }
+    t.touch(n, right, parent)
+    return nil
+  }
+
+  // merge with siblings, the right one into the node or the node into the left one
+  if right == nil {
+    n, right, index = left, n, index-1
+  }
+  n.entries = append(n.entries, parent.entries[index])
+  n.entries = append(n.entries, right.entries...)
+  n.children = append(n.children, right.children...)
+  parent.entries = deleteEntry(parent.entries, index)
+  parent.children = append(parent.children[:index+1], parent.children[index+2:]...)
+  t.touch(n, parent)
+  t.release(right)
+
+  // parent might underflow, so try to rebalance if necessary
+  return t.rebalance(path[:len(path)-1])
+}
+
+func (n *node[K, V]) isLeaf() bool {
+  return len(n.children) == 0
+}
+
+func deleteEntry[K comparable, V any](entries []btree.Entry[K, V], index int) []btree.Entry[K, V] {
+  copy(entries[index:], entries[index+1:])
+  entries[len(entries)-1] = btree.Entry[K, V]{}
+  return entries[:len(entries)-1]
+}
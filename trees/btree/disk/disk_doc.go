@@ -0,0 +1,20 @@
+// Package disk implements a B-tree of order m stored in a single file, an embeddable sorted index
+// that survives restarts.
+//
+// Every node is a fixed-size page of the file: page 0 is the header (order, page size, root, number
+// of keys, free list), the others are leaves, internal nodes or free pages linked into the free
+// list, which allocation reuses before growing the file. Pages start with the CRC-32 of the rest of
+// the page, checked on every read. Decoded nodes are kept in a bounded LRU page cache.
+//
+// Put, Get, Remove and the iterators behave as those of btree.BTree, splitting and rebalancing the
+// nodes by the same rules, but return the errors of the file.
+//
+// The file only changes on Sync. Until then the modified pages, those the cache evicts and the
+// freed ones, stay in memory; Sync writes them with the header to a journal next to the file,
+// flushes it, and only then writes them in place. Opening the tree replays a complete journal and
+// discards a torn one, so a crash leaves the tree as the last Sync did: the changes made since are
+// lost, those synced before are not. Sync also runs on its own once more pages are waiting than
+// the cache holds, and on Close.
+//
+// Structure is not thread safe.
+package disk
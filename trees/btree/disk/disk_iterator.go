@@ -0,0 +1,220 @@
+package disk
+
+import (
+  "github.com/fmorenovr/gods/utils"
+)
+
+func assertIteratorImplementation() {
+  var _ utils.ReverseIteratorWithKey[string, int] = (*Iterator[string, int])(nil)
+}
+
+// Iterator holding the iterator's state. Nodes are read as it moves: a read error ends the
+// iteration as if it reached the end, Err returns it.
+type Iterator[K comparable, V any] struct {
+  tree     *BTree[K, V]
+  path     []frame[K, V] // from the root down to the current entry
+  position position
+  err      error
+}
+
+type position byte
+
+// before means the iterator sits just before the entry of path, see IteratorAt
+const (
+  begin, between, end, before position = 0, 1, 2, 3
+)
+
+// Iterator returns a stateful iterator whose elements are key/value pairs.
+func (t *BTree[K, V]) Iterator() *Iterator[K, V] {
+  return &Iterator[K, V]{tree: t, position: begin, err: t.err}
+}
+
+// IteratorAt returns a stateful iterator placed just before the smallest key
+// larger than or equal to key: Next moves to that key, Prev to the one before it.
+func (t *BTree[K, V]) IteratorAt(key K) *Iterator[K, V] {
+  iterator := &Iterator[K, V]{tree: t, position: end, err: t.err}
+  if iterator.err != nil || t.root == 0 {
+    return iterator
+  }
+  path, found, err := t.searchPath(key)
+  if err != nil {
+    iterator.fail(err)
+    return iterator
+  }
+  if err := t.done(); err != nil {
+    iterator.fail(err)
+    return iterator
+  }
+  iterator.path = path
+  // past the last entry of its leaf, the ceiling of key is up in an ancestor
+  if last := path[len(path)-1]; !found && last.index == len(last.node.entries) && !iterator.up() {
+    return iterator
+  }
+  iterator.position = before
+  return iterator
+}
+
+// Moves to the next element
+func (iterator *Iterator[K, V]) Next() bool {
+  switch iterator.position {
+  case end:
+    return false
+  case before:
+    iterator.position = between
+    return true
+  case begin:
+    iterator.path = nil
+    if iterator.err != nil || !iterator.down(iterator.tree.root, false) {
+      iterator.End()
+      return false
+    }
+    iterator.position = between
+    return true
+  }
+  last := &iterator.path[len(iterator.path)-1]
+  if !last.node.isLeaf() {
+    last.index++
+    if !iterator.down(last.node.children[last.index], false) {
+      iterator.End()
+      return false
+    }
+    return true
+  }
+  if last.index+1 < len(last.node.entries) {
+    last.index++
+    return true
+  }
+  if !iterator.up() {
+    iterator.End()
+    return false
+  }
+  return true
+}
+
+// Move to the Prev element
+func (iterator *Iterator[K, V]) Prev() bool {
+  switch iterator.position {
+  case begin:
+    return false
+  case end:
+    iterator.path = nil
+    if iterator.err != nil || !iterator.down(iterator.tree.root, true) {
+      iterator.Begin()
+      return false
+    }
+    iterator.position = between
+    return true
+  }
+  iterator.position = between
+  last := &iterator.path[len(iterator.path)-1]
+  if !last.node.isLeaf() {
+    if !iterator.down(last.node.children[last.index], true) {
+      iterator.Begin()
+      return false
+    }
+    return true
+  }
+  if last.index > 0 {
+    last.index--
+    return true
+  }
+  // climb to the first ancestor not reached from its leftmost child, the entry left of that child is next
+  for len(iterator.path) > 0 && iterator.path[len(iterator.path)-1].index == 0 {
+    iterator.path = iterator.path[:len(iterator.path)-1]
+  }
+  if len(iterator.path) == 0 {
+    iterator.Begin()
+    return false
+  }
+  iterator.path[len(iterator.path)-1].index--
+  return true
+}
+
+// up climbs to the first ancestor not reached from its rightmost child, the entry right of that
+// child is next; false if there is none
+func (iterator *Iterator[K, V]) up() bool {
+  iterator.path = iterator.path[:len(iterator.path)-1]
+  for len(iterator.path) > 0 {
+    last := iterator.path[len(iterator.path)-1]
+    if last.index < len(last.node.entries) {
+      return true
+    }
+    iterator.path = iterator.path[:len(iterator.path)-1]
+  }
+  return false
+}
+
+// down goes from page id to its leftmost entry, or its rightmost one when right is set, false
+// for an empty tree or on a read error
+func (iterator *Iterator[K, V]) down(id uint64, right bool) bool {
+  tree := iterator.tree
+  for id != 0 {
+    n, err := tree.node(id)
+    if err != nil {
+      iterator.fail(err)
+      return false
+    }
+    index := 0
+    if right && n.isLeaf() {
+      index = len(n.entries) - 1
+    } else if right {
+      index = len(n.children) - 1
+    }
+    iterator.path = append(iterator.path, frame[K, V]{n, index})
+    if n.isLeaf() {
+      break
+    }
+    id = n.children[index]
+  }
+  if err := tree.done(); err != nil {
+    iterator.fail(err)
+    return false
+  }
+  return len(iterator.path) > 0
+}
+
+// fail ends the iteration on err
+func (iterator *Iterator[K, V]) fail(err error) {
+  iterator.err = iterator.tree.fail(err)
+}
+
+// Err returns the read error that ended the iteration, if any
+func (iterator *Iterator[K, V]) Err() error {
+  return iterator.err
+}
+
+// Return current value
+func (iterator *Iterator[K, V]) Value() V {
+  last := iterator.path[len(iterator.path)-1]
+  return last.node.entries[last.index].Value
+}
+
+// Return current Key
+func (iterator *Iterator[K, V]) Key() K {
+  last := iterator.path[len(iterator.path)-1]
+  return last.node.entries[last.index].Key
+}
+
+// Set pointer to Begin state
+func (iterator *Iterator[K, V]) Begin() {
+  iterator.path = nil
+  iterator.position = begin
+}
+
+// Set pointer to last state
+func (iterator *Iterator[K, V]) End() {
+  iterator.path = nil
+  iterator.position = end
+}
+
+// Moves to the first element
+func (iterator *Iterator[K, V]) First() bool {
+  iterator.Begin()
+  return iterator.Next()
+}
+
+// Moves to the Last element
+func (iterator *Iterator[K, V]) Last() bool {
+  iterator.End()
+  return iterator.Prev()
+}
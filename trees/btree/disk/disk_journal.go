@@ -0,0 +1,131 @@
+package disk
+
+import (
+  "bytes"
+  "encoding/binary"
+  "errors"
+  "hash/crc32"
+  "os"
+  "path/filepath"
+  "slices"
+)
+
+// JournalSuffix is appended to the path of the tree to name its journal
+const JournalSuffix = ".journal"
+
+// The journal holds the pages of a Sync before they are written in place: the magic "GODSJRNL",
+// the uint32 page size and number of pages, then for every page its uint64 number followed by the
+// page as it goes in the file, and last the CRC-32 (IEEE) of everything before it, all big endian.
+// A journal whose checksum does not match was torn by a crash before the file was touched.
+const journalMagic = "GODSJRNL"
+
+// commit writes the pending pages to the journal, then in place, then drops the journal: a crash
+// before the journal is complete leaves the file as the last Sync wrote it, a crash after it is
+// replayed by Open.
+func (t *BTree[K, V]) commit() error {
+  if err := writeJournal(t.journal, t.pageSize, t.pending); err != nil {
+    return err
+  }
+  for id, page := range t.pending {
+    if err := t.writePage(id, page); err != nil {
+      return err
+    }
+  }
+  // a Clear leaves pages past the last one in use
+  if info, err := t.file.Stat(); err != nil {
+    return err
+  } else if size := int64(t.pages) * int64(t.pageSize); info.Size() > size {
+    if err := t.file.Truncate(size); err != nil {
+      return err
+    }
+  }
+  if err := t.file.Sync(); err != nil {
+    return err
+  }
+  t.pending = make(map[uint64][]byte)
+  // a journal left behind is replayed again on open, which rewrites the same pages
+  return os.Remove(t.journal)
+}
+
+// writeJournal writes the pages to a new journal at path and flushes it, with its directory entry
+func writeJournal(path string, pageSize int, pages map[uint64][]byte) error {
+  data := append([]byte(journalMagic), 0, 0, 0, 0, 0, 0, 0, 0)
+  binary.BigEndian.PutUint32(data[len(journalMagic):], uint32(pageSize))
+  binary.BigEndian.PutUint32(data[len(journalMagic)+4:], uint32(len(pages)))
+  ids := make([]uint64, 0, len(pages))
+  for id := range pages {
+    ids = append(ids, id)
+  }
+  slices.Sort(ids)
+  for _, id := range ids {
+    data = binary.BigEndian.AppendUint64(data, id)
+    data = append(data, pages[id]...)
+  }
+  data = binary.BigEndian.AppendUint32(data, crc32.ChecksumIEEE(data))
+
+  file, err := os.Create(path)
+  if err != nil {
+    return err
+  }
+  if _, err := file.Write(data); err != nil {
+    file.Close()
+    return err
+  }
+  if err := file.Sync(); err != nil {
+    file.Close()
+    return err
+  }
+  if err := file.Close(); err != nil {
+    return err
+  }
+  dir, err := os.Open(filepath.Dir(path))
+  if err != nil {
+    return err
+  }
+  defer dir.Close()
+  return dir.Sync()
+}
+
+// replayJournal writes the pages of a complete journal at path into file, then removes the
+// journal. A torn journal is removed without touching the file.
+func replayJournal(file *os.File, path string) error {
+  data, err := os.ReadFile(path)
+  if errors.Is(err, os.ErrNotExist) {
+    return nil
+  }
+  if err != nil {
+    return err
+  }
+  if pageSize, count, ok := checkJournal(data); ok {
+    body := data[len(journalMagic)+8 : len(data)-4]
+    for i := 0; i < count; i++ {
+      record := body[i*(8+pageSize) : (i+1)*(8+pageSize)]
+      offset := int64(binary.BigEndian.Uint64(record)) * int64(pageSize)
+      if _, err := file.WriteAt(record[8:], offset); err != nil {
+        return err
+      }
+    }
+    if err := file.Sync(); err != nil {
+      return err
+    }
+  }
+  return os.Remove(path)
+}
+
+// checkJournal returns the page size and number of pages of a complete journal, ok is false for a
+// torn one
+func checkJournal(data []byte) (pageSize, count int, ok bool) {
+  header := len(journalMagic) + 8
+  if len(data) < header+4 || !bytes.HasPrefix(data, []byte(journalMagic)) {
+    return 0, 0, false
+  }
+  if crc32.ChecksumIEEE(data[:len(data)-4]) != binary.BigEndian.Uint32(data[len(data)-4:]) {
+    return 0, 0, false
+  }
+  pageSize = int(binary.BigEndian.Uint32(data[len(journalMagic):]))
+  count = int(binary.BigEndian.Uint32(data[len(journalMagic)+4:]))
+  if uint64(len(data)-header-4) != uint64(count)*uint64(8+pageSize) {
+    return 0, 0, false
+  }
+  return pageSize, count, true
+}
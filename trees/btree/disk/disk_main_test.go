@@ -0,0 +1,401 @@
+package disk
+
+import (
+  "bytes"
+  "errors"
+  "fmt"
+  "iter"
+  "maps"
+  "math/rand"
+  "os"
+  "path/filepath"
+  "slices"
+  "strconv"
+  "strings"
+  "testing"
+
+  "github.com/fmorenovr/gods/trees"
+  "github.com/fmorenovr/gods/trees/treetest"
+  "github.com/fmorenovr/gods/utils"
+)
+
+// orderedMap adapts a BTree to gotree.OrderedMap for treetest, any error fails the test
+type orderedMap struct {
+  t    *testing.T
+  tree *BTree[int, string]
+}
+
+func (m orderedMap) check(err error) {
+  if err != nil {
+    m.t.Errorf("Got %v expected %v", err, nil)
+  }
+}
+
+func (m orderedMap) Put(key int, value string) {
+  m.check(m.tree.Put(key, value))
+}
+
+func (m orderedMap) Get(key int) (string, bool) {
+  value, found, err := m.tree.Get(key)
+  m.check(err)
+  return value, found
+}
+
+func (m orderedMap) Remove(key int) {
+  m.check(m.tree.Remove(key))
+}
+
+func (m orderedMap) Contains(key int) bool {
+  found, err := m.tree.Contains(key)
+  m.check(err)
+  return found
+}
+
+func (m orderedMap) entry(it *Iterator[int, string], ok bool) (int, string, bool) {
+  m.check(it.Err())
+  if !ok {
+    return 0, "", false
+  }
+  return it.Key(), it.Value(), true
+}
+
+func (m orderedMap) Min() (int, string, bool) {
+  it := m.tree.Iterator()
+  return m.entry(it, it.First())
+}
+
+func (m orderedMap) Max() (int, string, bool) {
+  it := m.tree.Iterator()
+  return m.entry(it, it.Last())
+}
+
+func (m orderedMap) FloorEntry(key int) (int, string, bool) {
+  it := m.tree.IteratorAt(key)
+  if it.Next() && it.Key() == key {
+    return m.entry(it, true)
+  }
+  it = m.tree.IteratorAt(key)
+  return m.entry(it, it.Prev())
+}
+
+func (m orderedMap) CeilingEntry(key int) (int, string, bool) {
+  it := m.tree.IteratorAt(key)
+  return m.entry(it, it.Next())
+}
+
+func (m orderedMap) Iterator() utils.ReverseIteratorWithKey[int, string] {
+  return m.tree.Iterator()
+}
+
+func (m orderedMap) All() iter.Seq2[int, string] {
+  return func(yield func(int, string) bool) {
+    for it := m.tree.Iterator(); it.Next(); {
+      if !yield(it.Key(), it.Value()) {
+        return
+      }
+    }
+  }
+}
+
+func (m orderedMap) Backward() iter.Seq2[int, string] {
+  return func(yield func(int, string) bool) {
+    it := m.tree.Iterator()
+    it.End()
+    for it.Prev() {
+      if !yield(it.Key(), it.Value()) {
+        return
+      }
+    }
+  }
+}
+
+func (m orderedMap) Len() int {
+  return m.tree.Len()
+}
+
+func (m orderedMap) Validate() error {
+  if m.tree.cache.order.Len() > m.tree.cache.capacity {
+    return fmt.Errorf("cache holds %v pages, at most %v allowed", m.tree.cache.order.Len(), m.tree.cache.capacity)
+  }
+  return m.tree.Validate()
+}
+
+func open(t *testing.T, path string, options Options[int, string]) *BTree[int, string] {
+  t.Helper()
+  tree, err := Open[int, string](path, nil, options)
+  if err != nil {
+    t.Fatalf("Got %v expected %v", err, nil)
+  }
+  t.Cleanup(func() { tree.Close() })
+  return tree
+}
+
+func TestBTreeOrderedMap(t *testing.T) {
+  for _, order := range []int{3, 4, 5, 8} {
+    t.Run(strconv.Itoa(order), func(t *testing.T) {
+      files := 0
+      treetest.Run(t, func() gotree.OrderedMap[int, string] {
+        files++
+        path := filepath.Join(t.TempDir(), fmt.Sprintf("tree%v", files))
+        return orderedMap{t, open(t, path, Options[int, string]{Order: order, PageSize: 256, CacheSize: 4})}
+      }, strconv.Itoa)
+    })
+  }
+}
+
+func TestBTreeReopen(t *testing.T) {
+  path := filepath.Join(t.TempDir(), "tree")
+  tree := open(t, path, Options[int, string]{Order: 4, PageSize: 256, CacheSize: 8})
+  model := map[int]string{}
+  r := rand.New(rand.NewSource(1))
+  for _, key := range r.Perm(1000) {
+    if err := tree.Put(key, strconv.Itoa(key)); err != nil {
+      t.Fatalf("Got %v expected %v", err, nil)
+    }
+    model[key] = strconv.Itoa(key)
+  }
+  for _, key := range r.Perm(1000)[:600] {
+    if err := tree.Remove(key); err != nil {
+      t.Fatalf("Got %v expected %v", err, nil)
+    }
+    delete(model, key)
+  }
+  if err := tree.Close(); err != nil {
+    t.Errorf("Got %v expected %v", err, nil)
+  }
+  if err := tree.Put(1, "1"); !errors.Is(err, ErrClosed) {
+    t.Errorf("Got %v expected %v", err, ErrClosed)
+  }
+
+  tree = open(t, path, Options[int, string]{})
+  if actualValue, expectedValue := tree.Order(), 4; actualValue != expectedValue {
+    t.Errorf("Got %v expected %v", actualValue, expectedValue)
+  }
+  if err := tree.Validate(); err != nil {
+    t.Errorf("Got %v expected %v", err, nil)
+  }
+  keys := make([]int, 0, len(model))
+  for key := range model {
+    keys = append(keys, key)
+  }
+  slices.Sort(keys)
+  var actualKeys []int
+  for it := tree.Iterator(); it.Next(); {
+    if it.Value() != model[it.Key()] {
+      t.Errorf("Got %v expected %v", it.Value(), model[it.Key()])
+    }
+    actualKeys = append(actualKeys, it.Key())
+  }
+  if !slices.Equal(actualKeys, keys) {
+    t.Errorf("Got %v expected %v", actualKeys, keys)
+  }
+  if value, found, err := tree.Get(keys[0]); value != model[keys[0]] || !found || err != nil {
+    t.Errorf("Got %v,%v,%v expected %v,%v,%v", value, found, err, model[keys[0]], true, nil)
+  }
+}
+
+func TestBTreeFreeList(t *testing.T) {
+  tree := open(t, filepath.Join(t.TempDir(), "tree"), Options[int, string]{Order: 3, PageSize: 128, CacheSize: 2})
+  for round := 0; round < 3; round++ {
+    for key := 0; key < 300; key++ {
+      tree.Put(key, strconv.Itoa(key))
+    }
+    pages := tree.pages
+    for key := 0; key < 300; key++ {
+      tree.Remove(key)
+    }
+    if err := tree.Validate(); err != nil {
+      t.Errorf("Got %v expected %v", err, nil)
+    }
+    if round > 0 && tree.pages != pages {
+      t.Errorf("round %v: got %v pages expected the %v freed ones reused", round, tree.pages, pages)
+    }
+  }
+  if err := tree.Clear(); err != nil {
+    t.Errorf("Got %v expected %v", err, nil)
+  }
+  if info, _ := tree.file.Stat(); info.Size() != 128 || tree.Size() != 0 {
+    t.Errorf("Got %v bytes and %v keys expected the header alone", info.Size(), tree.Size())
+  }
+}
+
+func TestBTreeIteratorAt(t *testing.T) {
+  tree := open(t, filepath.Join(t.TempDir(), "tree"), Options[int, string]{Order: 3, PageSize: 128, CacheSize: 2})
+  it := tree.IteratorAt(5)
+  if it.Next() || it.Prev() {
+    t.Errorf("Got an element expected none in an empty tree")
+  }
+  for key := 0; key < 100; key += 2 {
+    tree.Put(key, strconv.Itoa(key))
+  }
+  var keys []int
+  for it := tree.IteratorAt(51); it.Next(); {
+    keys = append(keys, it.Key())
+  }
+  if actualValue, expectedValue := keys, []int{52, 54, 56}; !slices.Equal(actualValue[:3], expectedValue) || len(actualValue) != 24 {
+    t.Errorf("Got %v expected %v... (24 keys)", actualValue, expectedValue)
+  }
+  it = tree.IteratorAt(51)
+  if !it.Prev() || it.Key() != 50 {
+    t.Errorf("Got %v expected %v", it.Key(), 50)
+  }
+  if it := tree.IteratorAt(99); it.Next() || !it.Prev() || it.Key() != 98 {
+    t.Errorf("Got an iterator past the end expected Prev to move to %v", 98)
+  }
+}
+
+func TestBTreeErrors(t *testing.T) {
+  path := filepath.Join(t.TempDir(), "tree")
+  tree := open(t, path, Options[int, string]{Order: 4, PageSize: 128})
+  if err := tree.Put(1, strings.Repeat("x", 100)); !errors.Is(err, ErrEntryTooLarge) || tree.Size() != 0 {
+    t.Errorf("Got %v expected %v and the tree untouched", err, ErrEntryTooLarge)
+  }
+  for key := 0; key < 50; key++ {
+    tree.Put(key, strconv.Itoa(key))
+  }
+  tree.Close()
+
+  if _, err := Open[int, string](path, nil, Options[int, string]{Order: 5}); !errors.Is(err, gotree.ErrInvalidOrder) {
+    t.Errorf("Got %v expected %v", err, gotree.ErrInvalidOrder)
+  }
+  if _, err := Open[int, string](path, nil, Options[int, string]{PageSize: 4096}); err == nil {
+    t.Errorf("Got %v expected a page size error", err)
+  }
+  if _, err := Open[int, string](filepath.Join(t.TempDir(), "small"), nil, Options[int, string]{Order: 100, PageSize: 128}); !errors.Is(err, gotree.ErrInvalidOrder) {
+    t.Errorf("Got %v expected %v", err, gotree.ErrInvalidOrder)
+  }
+
+  data, err := os.ReadFile(path)
+  if err != nil {
+    t.Fatalf("Got %v expected %v", err, nil)
+  }
+  data[128+10] ^= 1 // a byte of page 1
+  if err := os.WriteFile(path, data, 0o644); err != nil {
+    t.Fatalf("Got %v expected %v", err, nil)
+  }
+  tree = open(t, path, Options[int, string]{})
+  var decodeError *gotree.DecodeError
+  if err := tree.Validate(); !errors.Is(err, gotree.ErrCorruptData) || !errors.As(err, &decodeError) || decodeError.Offset != 128 {
+    t.Errorf("Got %v expected %v at offset %v", err, gotree.ErrCorruptData, 128)
+  }
+  if _, _, err := tree.Get(-1); !errors.Is(err, gotree.ErrCorruptData) {
+    t.Errorf("Got %v expected %v", err, gotree.ErrCorruptData)
+  }
+
+  data[0] ^= 1
+  os.WriteFile(path, data, 0o644)
+  if _, err := Open[int, string](path, nil, Options[int, string]{}); !errors.Is(err, gotree.ErrCorruptData) {
+    t.Errorf("Got %v expected %v", err, gotree.ErrCorruptData)
+  }
+}
+
+// assertContents checks that tree is valid and holds the keys and values of model
+func assertContents(t *testing.T, tree *BTree[int, string], model map[int]string) {
+  t.Helper()
+  if err := tree.Validate(); err != nil {
+    t.Errorf("Got %v expected %v", err, nil)
+  }
+  keys := make([]int, 0, len(model))
+  for key := range model {
+    keys = append(keys, key)
+  }
+  slices.Sort(keys)
+  var actualKeys []int
+  it := tree.Iterator()
+  for it.Next() {
+    if it.Value() != model[it.Key()] {
+      t.Errorf("Got %v expected %v for key %v", it.Value(), model[it.Key()], it.Key())
+    }
+    actualKeys = append(actualKeys, it.Key())
+  }
+  if it.Err() != nil || !slices.Equal(actualKeys, keys) {
+    t.Errorf("Got %v, %v expected %v", actualKeys, it.Err(), keys)
+  }
+}
+
+// crash copies the file of the tree at path, and its journal when there is one, as a crash would
+// leave them, and returns the path of the copy
+func crash(t *testing.T, path string, journal []byte) string {
+  t.Helper()
+  copied := filepath.Join(t.TempDir(), "tree")
+  data, err := os.ReadFile(path)
+  if err != nil {
+    t.Fatalf("Got %v expected %v", err, nil)
+  }
+  os.WriteFile(copied, data, 0o644)
+  if journal != nil {
+    os.WriteFile(copied+JournalSuffix, journal, 0o644)
+  }
+  return copied
+}
+
+func TestBTreeCrash(t *testing.T) {
+  path := filepath.Join(t.TempDir(), "tree")
+  options := Options[int, string]{Order: 4, PageSize: 256, CacheSize: 1000}
+  tree := open(t, path, options)
+  synced := map[int]string{}
+  for key := 0; key < 300; key++ {
+    tree.Put(key, strconv.Itoa(key))
+    synced[key] = strconv.Itoa(key)
+  }
+  if err := tree.Sync(); err != nil {
+    t.Fatalf("Got %v expected %v", err, nil)
+  }
+  image, _ := os.ReadFile(path)
+
+  // merges free pages that new splits reuse at once, the file must not change before Sync
+  model := maps.Clone(synced)
+  for key := 0; key < 300; key += 2 {
+    tree.Remove(key)
+    delete(model, key)
+  }
+  for key := 300; key < 350; key++ {
+    tree.Put(key, "new")
+    model[key] = "new"
+  }
+  if data, _ := os.ReadFile(path); !bytes.Equal(data, image) {
+    t.Errorf("Got the file changed expected it as the last Sync wrote it")
+  }
+  assertContents(t, open(t, crash(t, path, nil), Options[int, string]{}), synced)
+
+  // a crash once the journal is complete replays it, a crash while writing it drops it
+  for _, n := range tree.cache.nodes() {
+    tree.writeNode(n)
+  }
+  tree.writeHeader()
+  if err := writeJournal(path+JournalSuffix, tree.pageSize, tree.pending); err != nil {
+    t.Fatalf("Got %v expected %v", err, nil)
+  }
+  journal, _ := os.ReadFile(path + JournalSuffix)
+  copied := crash(t, path, journal)
+  assertContents(t, open(t, copied, Options[int, string]{}), model)
+  if _, err := os.Stat(copied + JournalSuffix); !errors.Is(err, os.ErrNotExist) {
+    t.Errorf("Got %v expected the journal removed once replayed", err)
+  }
+  assertContents(t, open(t, crash(t, path, journal[:len(journal)-1]), Options[int, string]{}), synced)
+
+  if err := tree.Close(); err != nil {
+    t.Errorf("Got %v expected %v", err, nil)
+  }
+  assertContents(t, open(t, path, Options[int, string]{}), model)
+}
+
+func benchmarkPut(b *testing.B, cacheSize int) {
+  tree, err := Open[int, int](filepath.Join(b.TempDir(), "tree"), nil, Options[int, int]{CacheSize: cacheSize})
+  if err != nil {
+    b.Fatal(err)
+  }
+  defer tree.Close()
+  b.ResetTimer()
+  for n := 0; n < b.N; n++ {
+    tree.Put(n, n)
+  }
+}
+
+func BenchmarkBTreePut(b *testing.B) {
+  benchmarkPut(b, DefaultCacheSize)
+}
+
+func BenchmarkBTreePutSmallCache(b *testing.B) {
+  benchmarkPut(b, 2)
+}
@@ -0,0 +1,382 @@
+package disk
+
+import (
+  "bytes"
+  "container/list"
+  "encoding/binary"
+  "fmt"
+  "hash/crc32"
+
+  "github.com/fmorenovr/gods/trees"
+  "github.com/fmorenovr/gods/trees/btree"
+)
+
+// Version is the version of the file layout.
+//
+// Every page starts with the CRC-32 (IEEE) of the rest of the page, big endian. The header, page
+// 0, follows it with the magic "GODSBTRE", the version byte, then the page size and the order as
+// uint32 and the root, number of keys, first free page and number of pages as uint64, all big
+// endian. The other pages follow it with their kind byte. A leaf or internal node holds a uvarint
+// count of entries, each a uvarint length and the bytes of the key then the same for the value, and
+// for an internal node count+1 uvarint page numbers of its children. A free page holds the uint64
+// number of the next free page, 0 ending the list. The pages of a Sync go through the journal
+// first, see JournalSuffix.
+const Version = 1
+
+const magic = "GODSBTRE"
+
+// kinds of page
+const (
+  leafPage, internalPage, freePage byte = 1, 2, 3
+)
+
+// headerSize is the number of bytes of the header page in use, the smallest page size
+const headerSize = 4 + len(magic) + 1 + 4 + 4 + 8*4
+
+// writeHeader stages page 0
+func (t *BTree[K, V]) writeHeader() {
+  body := append([]byte(magic), Version)
+  body = binary.BigEndian.AppendUint32(body, uint32(t.pageSize))
+  body = binary.BigEndian.AppendUint32(body, uint32(t.m))
+  body = binary.BigEndian.AppendUint64(body, t.root)
+  body = binary.BigEndian.AppendUint64(body, uint64(t.size))
+  body = binary.BigEndian.AppendUint64(body, t.free)
+  body = binary.BigEndian.AppendUint64(body, t.pages)
+  t.stage(0, body)
+}
+
+// readHeader reads page 0, whose page size it does not know yet
+func (t *BTree[K, V]) readHeader() error {
+  page := make([]byte, headerSize)
+  if _, err := t.file.ReadAt(page, 0); err != nil {
+    return t.corruptPage(0, "header: %v", err)
+  }
+  body := page[4:]
+  if !bytes.HasPrefix(body, []byte(magic)) {
+    return t.corruptPage(0, "not a B-tree file")
+  }
+  body = body[len(magic):]
+  if body[0] != Version {
+    return t.corruptPage(0, "version %v, only %v is supported", body[0], Version)
+  }
+  t.pageSize = int(binary.BigEndian.Uint32(body[1:]))
+  t.m = int(binary.BigEndian.Uint32(body[5:]))
+  t.root = binary.BigEndian.Uint64(body[9:])
+  t.size = int(binary.BigEndian.Uint64(body[17:]))
+  t.free = binary.BigEndian.Uint64(body[25:])
+  t.pages = binary.BigEndian.Uint64(body[33:])
+  if t.pageSize < headerSize {
+    return t.corruptPage(0, "page size %v", t.pageSize)
+  }
+  // the checksum covers the whole page, now that its size is known
+  if _, err := t.readPage(0); err != nil {
+    return err
+  }
+  if t.pages == 0 || t.root >= t.pages || t.free >= t.pages {
+    return t.corruptPage(0, "root %v and free list %v in %v pages", t.root, t.free, t.pages)
+  }
+  return nil
+}
+
+// stage keeps body, padded and prefixed with its checksum, as the new content of page id until
+// the next Sync writes it: the file only changes on Sync
+func (t *BTree[K, V]) stage(id uint64, body []byte) {
+  page := make([]byte, t.pageSize)
+  copy(page[4:], body)
+  binary.BigEndian.PutUint32(page, crc32.ChecksumIEEE(page[4:]))
+  t.pending[id] = page
+  t.modified = true
+}
+
+// writePage writes a staged page in place
+func (t *BTree[K, V]) writePage(id uint64, page []byte) error {
+  _, err := t.file.WriteAt(page, int64(id)*int64(t.pageSize))
+  return err
+}
+
+// readPage reads page id, staged or from the file, and returns it without its checksum
+func (t *BTree[K, V]) readPage(id uint64) ([]byte, error) {
+  if id >= t.pages {
+    return nil, t.corruptPage(id, "past the last page %v", t.pages-1)
+  }
+  if page, ok := t.pending[id]; ok {
+    return page[4:], nil
+  }
+  page := make([]byte, t.pageSize)
+  if _, err := t.file.ReadAt(page, int64(id)*int64(t.pageSize)); err != nil {
+    return nil, t.corruptPage(id, "%v", err)
+  }
+  if crc32.ChecksumIEEE(page[4:]) != binary.BigEndian.Uint32(page) {
+    return nil, t.corruptPage(id, "checksum mismatch")
+  }
+  return page[4:], nil
+}
+
+// corruptPage returns the error of an unreadable page, a *gotree.DecodeError at its offset
+// wrapping gotree.ErrCorruptData
+func (t *BTree[K, V]) corruptPage(id uint64, format string, args ...any) error {
+  return gotree.CorruptEntry(-1, int64(id)*int64(t.pageSize), fmt.Errorf("page %v: "+format, append([]any{id}, args...)...))
+}
+
+// node returns the node of page id, from the cache or read from the file
+func (t *BTree[K, V]) node(id uint64) (*node[K, V], error) {
+  if n := t.cache.get(id); n != nil {
+    return n, nil
+  }
+  if id == 0 {
+    return nil, t.corruptPage(id, "header is not a node")
+  }
+  body, err := t.readPage(id)
+  if err != nil {
+    return nil, err
+  }
+  n, err := t.decodeNode(id, body)
+  if err != nil {
+    return nil, err
+  }
+  t.cache.add(n)
+  return n, nil
+}
+
+// touch marks the nodes modified
+func (t *BTree[K, V]) touch(nodes ...*node[K, V]) {
+  for _, n := range nodes {
+    n.dirty = true
+    t.cache.add(n)
+  }
+  t.modified = true
+}
+
+// alloc returns a new empty node on the first page of the free list, or on a new page
+func (t *BTree[K, V]) alloc() (*node[K, V], error) {
+  id := t.free
+  if id != 0 {
+    body, err := t.readPage(id)
+    if err != nil {
+      return nil, err
+    }
+    if body[0] != freePage {
+      return nil, t.corruptPage(id, "in the free list but not free")
+    }
+    t.free = binary.BigEndian.Uint64(body[1:])
+  } else {
+    id = t.pages
+    t.pages++
+  }
+  n := &node[K, V]{id: id}
+  t.touch(n)
+  return n, nil
+}
+
+// release puts the page of n at the head of the free list. The page is only overwritten on Sync,
+// with the header that no longer points to it, so it may be reused right away.
+func (t *BTree[K, V]) release(n *node[K, V]) {
+  t.cache.remove(n.id)
+  t.stage(n.id, binary.BigEndian.AppendUint64([]byte{freePage}, t.free))
+  t.free = n.id
+}
+
+// writeNode stages n on its page if it was modified
+func (t *BTree[K, V]) writeNode(n *node[K, V]) error {
+  if !n.dirty {
+    return nil
+  }
+  body, err := t.encodeNode(n)
+  if err != nil {
+    return err
+  }
+  if len(body) > t.pageSize-4 {
+    return fmt.Errorf("disk: node %v takes %v bytes, pages hold %v", n.id, len(body), t.pageSize-4)
+  }
+  t.stage(n.id, body)
+  n.dirty = false
+  return nil
+}
+
+func (t *BTree[K, V]) encodeNode(n *node[K, V]) ([]byte, error) {
+  body := []byte{leafPage}
+  if !n.isLeaf() {
+    body[0] = internalPage
+  }
+  body = binary.AppendUvarint(body, uint64(len(n.entries)))
+  for _, entry := range n.entries {
+    keyData, valueData, err := t.encodeEntry(entry)
+    if err != nil {
+      return nil, err
+    }
+    body = binary.AppendUvarint(body, uint64(len(keyData)))
+    body = append(body, keyData...)
+    body = binary.AppendUvarint(body, uint64(len(valueData)))
+    body = append(body, valueData...)
+  }
+  for _, child := range n.children {
+    body = binary.AppendUvarint(body, child)
+  }
+  return body, nil
+}
+
+func (t *BTree[K, V]) decodeNode(id uint64, body []byte) (*node[K, V], error) {
+  kind := body[0]
+  if kind != leafPage && kind != internalPage {
+    return nil, t.corruptPage(id, "page of kind %v is not a node", kind)
+  }
+  r := pageReader{data: body[1:]}
+  count := r.uvarint()
+  if r.err == nil && count > uint64(t.maxEntries()) {
+    return nil, t.corruptPage(id, "%v entries, at most %v allowed", count, t.maxEntries())
+  }
+  n := &node[K, V]{id: id, entries: make([]btree.Entry[K, V], count)}
+  for i := range n.entries {
+    keyData, valueData := r.bytes(), r.bytes()
+    if r.err != nil {
+      break
+    }
+    key, err := t.keyCodec.Decode(keyData)
+    if err != nil {
+      return nil, t.corruptPage(id, "entry %v, key: %w", i, err)
+    }
+    value, err := t.valueCodec.Decode(valueData)
+    if err != nil {
+      return nil, t.corruptPage(id, "entry %v, key %v, value: %w", i, key, err)
+    }
+    n.entries[i] = btree.Entry[K, V]{Key: key, Value: value}
+  }
+  if kind == internalPage {
+    n.children = make([]uint64, count+1)
+    for i := range n.children {
+      if n.children[i] = r.uvarint(); r.err == nil && (n.children[i] == 0 || n.children[i] >= t.pages) {
+        return nil, t.corruptPage(id, "child %v is page %v of %v", i, n.children[i], t.pages)
+      }
+    }
+  }
+  if r.err != nil {
+    return nil, t.corruptPage(id, "%v", r.err)
+  }
+  return n, nil
+}
+
+// encodeEntry returns the encoded key and value of entry
+func (t *BTree[K, V]) encodeEntry(entry btree.Entry[K, V]) (keyData, valueData []byte, err error) {
+  if keyData, err = t.keyCodec.Encode(entry.Key); err != nil {
+    return nil, nil, fmt.Errorf("disk: key %v: %w", entry.Key, err)
+  }
+  if valueData, err = t.valueCodec.Encode(entry.Value); err != nil {
+    return nil, nil, fmt.Errorf("disk: value of key %v: %w", entry.Key, err)
+  }
+  return keyData, valueData, nil
+}
+
+// checkEntry returns an error unless entry fits in the share of a page of one of m-1 entries,
+// so that any node fits in its page
+func (t *BTree[K, V]) checkEntry(entry btree.Entry[K, V]) error {
+  keyData, valueData, err := t.encodeEntry(entry)
+  if err != nil {
+    return err
+  }
+  size := uvarintSize(len(keyData)) + len(keyData) + uvarintSize(len(valueData)) + len(valueData)
+  if size > t.maxEntrySize() {
+    return fmt.Errorf("%w: key %v takes %v bytes, at most %v", ErrEntryTooLarge, entry.Key, size, t.maxEntrySize())
+  }
+  return nil
+}
+
+// maxEntrySize is the room for an entry in a page full of m-1 entries and m children
+func (t *BTree[K, V]) maxEntrySize() int {
+  room := t.pageSize - 4 - 1 - binary.MaxVarintLen64 - t.m*binary.MaxVarintLen64
+  return room / t.maxEntries()
+}
+
+func uvarintSize(n int) int {
+  return len(binary.AppendUvarint(nil, uint64(n)))
+}
+
+// pageReader reads uvarints and length-prefixed byte strings from data, the first error sticks
+type pageReader struct {
+  data []byte
+  err  error
+}
+
+func (r *pageReader) uvarint() uint64 {
+  if r.err != nil {
+    return 0
+  }
+  n, read := binary.Uvarint(r.data)
+  if read <= 0 {
+    r.err = fmt.Errorf("truncated node")
+    return 0
+  }
+  r.data = r.data[read:]
+  return n
+}
+
+func (r *pageReader) bytes() []byte {
+  n := r.uvarint()
+  if r.err != nil {
+    return nil
+  }
+  if n > uint64(len(r.data)) {
+    r.err = fmt.Errorf("length %v past the end of the page", n)
+    return nil
+  }
+  field := r.data[:n]
+  r.data = r.data[n:]
+  return field
+}
+
+// cache keeps the most recently used nodes, up to capacity between two operations
+type cache[K comparable, V any] struct {
+  capacity int
+  order    *list.List               // nodes, the most recently used first
+  elements map[uint64]*list.Element // elements of order by page
+}
+
+func newCache[K comparable, V any](capacity int) *cache[K, V] {
+  return &cache[K, V]{capacity: capacity, order: list.New(), elements: make(map[uint64]*list.Element)}
+}
+
+// get returns the node of page id and marks it used, nil if it is not cached
+func (c *cache[K, V]) get(id uint64) *node[K, V] {
+  element, ok := c.elements[id]
+  if !ok {
+    return nil
+  }
+  c.order.MoveToFront(element)
+  return element.Value.(*node[K, V])
+}
+
+// add caches n as the most recently used node
+func (c *cache[K, V]) add(n *node[K, V]) {
+  if element, ok := c.elements[n.id]; ok {
+    element.Value = n
+    c.order.MoveToFront(element)
+    return
+  }
+  c.elements[n.id] = c.order.PushFront(n)
+}
+
+func (c *cache[K, V]) remove(id uint64) {
+  if element, ok := c.elements[id]; ok {
+    c.order.Remove(element)
+    delete(c.elements, id)
+  }
+}
+
+// evict drops the least recently used nodes past capacity and returns them
+func (c *cache[K, V]) evict() []*node[K, V] {
+  var evicted []*node[K, V]
+  for c.order.Len() > c.capacity {
+    n := c.order.Remove(c.order.Back()).(*node[K, V])
+    delete(c.elements, n.id)
+    evicted = append(evicted, n)
+  }
+  return evicted
+}
+
+// nodes returns every cached node
+func (c *cache[K, V]) nodes() []*node[K, V] {
+  nodes := make([]*node[K, V], 0, c.order.Len())
+  for element := c.order.Front(); element != nil; element = element.Next() {
+    nodes = append(nodes, element.Value.(*node[K, V]))
+  }
+  return nodes
+}
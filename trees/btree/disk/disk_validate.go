@@ -0,0 +1,117 @@
+package disk
+
+import (
+  "encoding/binary"
+  "fmt"
+
+  "github.com/fmorenovr/gods/trees/btree"
+)
+
+// Validate reads the whole file and checks the B-tree invariants: entry and child counts against
+// the order, key order inside and across nodes, leaves all at the same depth and the number of
+// keys, then that every page is either a node of the tree or in the free list, once. It returns
+// nil for a sound tree or an error naming the first offending page.
+func (t *BTree[K, V]) Validate() error {
+  if t.err != nil {
+    return t.err
+  }
+  seen := map[uint64]bool{0: true}
+  if t.root != 0 {
+    leafDepth := -1
+    size, err := t.validate(t.root, nil, nil, 0, &leafDepth, seen)
+    if err != nil {
+      return err
+    }
+    if size != t.size {
+      return fmt.Errorf("disk: tree size is %v but it holds %v keys", t.size, size)
+    }
+  } else if t.size != 0 {
+    return fmt.Errorf("disk: empty tree has size %v", t.size)
+  }
+  for id := t.free; id != 0; {
+    if seen[id] {
+      return fmt.Errorf("disk: free page %v is used twice", id)
+    }
+    seen[id] = true
+    body, err := t.readPage(id)
+    if err != nil {
+      return err
+    }
+    if body[0] != freePage {
+      return fmt.Errorf("disk: page %v is in the free list but not free", id)
+    }
+    id = binary.BigEndian.Uint64(body[1:])
+  }
+  if uint64(len(seen)) != t.pages {
+    return fmt.Errorf("disk: %v pages in the file, %v of them in use or free", t.pages, len(seen))
+  }
+  return t.done()
+}
+
+// validate checks the subtree of page id, whose keys must lie strictly between low and high
+// (nil is unbounded), and returns its number of keys
+func (t *BTree[K, V]) validate(id uint64, low, high *btree.Entry[K, V], depth int, leafDepth *int, seen map[uint64]bool) (int, error) {
+  if seen[id] {
+    return 0, fmt.Errorf("disk: page %v is used twice", id)
+  }
+  seen[id] = true
+  n, err := t.node(id)
+  if err != nil {
+    return 0, err
+  }
+  if len(n.entries) == 0 {
+    return 0, fmt.Errorf("disk: node %v at depth %v has no entries", n, depth)
+  }
+  if len(n.entries) > t.maxEntries() {
+    return 0, fmt.Errorf("disk: node %v has %v entries, at most %v allowed", n, len(n.entries), t.maxEntries())
+  }
+  if id != t.root && len(n.entries) < t.minEntries() {
+    return 0, fmt.Errorf("disk: node %v has %v entries, at least %v required", n, len(n.entries), t.minEntries())
+  }
+  for i, entry := range n.entries {
+    if i > 0 && t.comparator(n.entries[i-1].Key, entry.Key) >= 0 {
+      return 0, fmt.Errorf("disk: node %v has key %v after %v", n, entry.Key, n.entries[i-1].Key)
+    }
+    if low != nil && t.comparator(entry.Key, low.Key) <= 0 {
+      return 0, fmt.Errorf("disk: node %v has key %v, not greater than its separator %v", n, entry.Key, low.Key)
+    }
+    if high != nil && t.comparator(entry.Key, high.Key) >= 0 {
+      return 0, fmt.Errorf("disk: node %v has key %v, not smaller than its separator %v", n, entry.Key, high.Key)
+    }
+  }
+  if n.isLeaf() {
+    if *leafDepth == -1 {
+      *leafDepth = depth
+    } else if *leafDepth != depth {
+      return 0, fmt.Errorf("disk: leaf %v is at depth %v, other leaves at depth %v", n, depth, *leafDepth)
+    }
+    return len(n.entries), nil
+  }
+  if len(n.children) != len(n.entries)+1 {
+    return 0, fmt.Errorf("disk: node %v has %v entries and %v children", n, len(n.entries), len(n.children))
+  }
+  size := len(n.entries)
+  for i, child := range n.children {
+    childLow, childHigh := low, high
+    if i > 0 {
+      childLow = &n.entries[i-1]
+    }
+    if i < len(n.entries) {
+      childHigh = &n.entries[i]
+    }
+    childSize, err := t.validate(child, childLow, childHigh, depth+1, leafDepth, seen)
+    if err != nil {
+      return 0, err
+    }
+    size += childSize
+  }
+  return size, nil
+}
+
+func (n *node[K, V]) String() string {
+  keys := make([]K, len(n.entries))
+  for i, entry := range n.entries {
+    keys[i] = entry.Key
+  }
+  return fmt.Sprintf("%v (page %v)", keys, n.id)
+}
@@ -1,10 +1,40 @@
 package gotree
 
-import(
-  "github.com/jenazads/goutils"
+import (
+  "iter"
+
+  "github.com/fmorenovr/gods/utils"
 )
 
 // GoTree interface that all trees implement
-type GoTree interface {
-	goutils.Container
+type GoTree[T any] interface {
+  utils.Container[T]
+}
+
+// OrderedMap interface that all sorted key-value trees implement
+type OrderedMap[K, V any] interface {
+  // Put inserts the key-value pair, replacing the value of an existing key
+  Put(key K, value V)
+  // Get returns the value of key, found is false if the key is not in the map
+  Get(key K) (value V, found bool)
+  // Remove deletes key from the map, does nothing if the key is not in the map
+  Remove(key K)
+  // Contains returns true if key is in the map
+  Contains(key K) bool
+  // Min returns the smallest key and its value, found is false if the map is empty
+  Min() (key K, value V, found bool)
+  // Max returns the largest key and its value, found is false if the map is empty
+  Max() (key K, value V, found bool)
+  // FloorEntry returns the largest key smaller than or equal to key
+  FloorEntry(key K) (floor K, value V, found bool)
+  // CeilingEntry returns the smallest key larger than or equal to key
+  CeilingEntry(key K) (ceiling K, value V, found bool)
+  // Iterator returns a stateful iterator over the pairs in key order
+  Iterator() utils.ReverseIteratorWithKey[K, V]
+  // All returns the pairs in key order as a range-over-func sequence
+  All() iter.Seq2[K, V]
+  // Backward returns the pairs in reverse key order as a range-over-func sequence
+  Backward() iter.Seq2[K, V]
+  // Len returns the number of keys in the map
+  Len() int
 }
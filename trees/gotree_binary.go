@@ -0,0 +1,182 @@
+package gotree
+
+import (
+  "bytes"
+  "encoding/binary"
+  "fmt"
+  "hash/crc32"
+  "iter"
+  "reflect"
+
+  "github.com/fmorenovr/gods/utils"
+)
+
+// BinaryVersion is the version of the binary form written by MarshalBinaryEntries.
+//
+// Version 1 is the magic "GODS", the version byte, the header (uvarint length of the kind
+// and the kind, uvarint order, uvarint count), count entries in order, each a uvarint length
+// and the bytes of the key then the same for the value, and the CRC-32 (IEEE) of everything
+// before it, big endian. Values of a zero-size type, like the struct{} of the heap, take no bytes.
+const BinaryVersion = 1
+
+const binaryMagic = "GODS"
+
+// BinaryHeader describes a structure in binary form
+type BinaryHeader struct {
+  Kind  string // Name of the structure, "avltree", "btree", ...
+  Order int    // Order of a B-tree, 0 for the other structures
+  Count int    // Number of entries
+}
+
+// MarshalBinaryEntries writes header and the header.Count entries of the sequence in binary form.
+// Nil codecs stand for utils.BinaryCodec.
+func MarshalBinaryEntries[K, V any](header BinaryHeader, entries iter.Seq2[K, V], keys utils.Codec[K], values utils.Codec[V]) ([]byte, error) {
+  keys, values = binaryCodecs(keys, values)
+  data := append([]byte(binaryMagic), BinaryVersion)
+  data = binary.AppendUvarint(data, uint64(len(header.Kind)))
+  data = append(data, header.Kind...)
+  data = binary.AppendUvarint(data, uint64(header.Order))
+  data = binary.AppendUvarint(data, uint64(header.Count))
+  withValues := hasBytes[V]()
+  i := 0
+  for key, value := range entries {
+    keyData, err := keys.Encode(key)
+    if err != nil {
+      return nil, fmt.Errorf("gotree: entry %v, key %v: %w", i, key, err)
+    }
+    data = binary.AppendUvarint(data, uint64(len(keyData)))
+    data = append(data, keyData...)
+    if withValues {
+      valueData, err := values.Encode(value)
+      if err != nil {
+        return nil, fmt.Errorf("gotree: entry %v, value of key %v: %w", i, key, err)
+      }
+      data = binary.AppendUvarint(data, uint64(len(valueData)))
+      data = append(data, valueData...)
+    }
+    i++
+  }
+  if i != header.Count {
+    return nil, fmt.Errorf("gotree: %v has %v entries, its header says %v", header.Kind, i, header.Count)
+  }
+  return binary.BigEndian.AppendUint32(data, crc32.ChecksumIEEE(data)), nil
+}
+
+// UnmarshalBinaryEntries decodes the binary form of a structure of the given kind. The checksum
+// is verified before anything else is read, and with a comparator the keys must come in strictly
+// ascending order, as the trees write them. Nothing is returned unless every entry decodes; errors
+// are *DecodeError wrapping ErrCorruptData, or ErrComparatorMismatch for keys out of order.
+func UnmarshalBinaryEntries[K, V any](data []byte, kind string, comp utils.Comparator[K], keys utils.Codec[K], values utils.Codec[V]) (BinaryHeader, []K, []V, error) {
+  var header BinaryHeader
+  keys, values = binaryCodecs(keys, values)
+  if len(data) < len(binaryMagic)+1+4 || !bytes.HasPrefix(data, []byte(binaryMagic)) {
+    return header, nil, nil, corrupt(-1, 0, nil, "not the binary form of a structure")
+  }
+  payload, sum := data[:len(data)-4], binary.BigEndian.Uint32(data[len(data)-4:])
+  if crc32.ChecksumIEEE(payload) != sum {
+    return header, nil, nil, corrupt(-1, int64(len(payload)), nil, "checksum mismatch")
+  }
+  if version := payload[len(binaryMagic)]; version != BinaryVersion {
+    return header, nil, nil, corrupt(-1, int64(len(binaryMagic)), nil, "binary version %v, only %v is supported", version, BinaryVersion)
+  }
+  r := binaryReader{data: payload, offset: len(binaryMagic) + 1}
+  header.Kind = string(r.bytes())
+  header.Order = int(r.uvarint())
+  count := r.uvarint()
+  if r.err != nil {
+    return header, nil, nil, corrupt(-1, r.at(), nil, "header: %v", r.err)
+  }
+  if header.Kind != kind {
+    return header, nil, nil, corrupt(-1, int64(len(binaryMagic)+1), nil, "binary form of a %v, expected a %v", header.Kind, kind)
+  }
+  // every entry takes at least one byte, a larger count can only be corrupt
+  if count > uint64(len(payload)-r.offset) {
+    return header, nil, nil, corrupt(-1, r.at(), nil, "%v entries in %v bytes", count, len(payload)-r.offset)
+  }
+  header.Count = int(count)
+  withValues := hasBytes[V]()
+  keyList, valueList := make([]K, header.Count), make([]V, header.Count)
+  for i := range keyList {
+    offset := r.at()
+    keyData := r.bytes()
+    if r.err != nil {
+      return header, nil, nil, corrupt(i, offset, nil, "%v", r.err)
+    }
+    key, err := keys.Decode(keyData)
+    if err != nil {
+      return header, nil, nil, corrupt(i, offset, nil, "key: %w", err)
+    }
+    if comp != nil && i > 0 && comp(keyList[i-1], key) >= 0 {
+      return header, nil, nil, &DecodeError{Entry: i, Offset: offset, Key: key, Err: fmt.Errorf("%w: not after %v, the data is corrupt or was written with another comparator", ErrComparatorMismatch, keyList[i-1])}
+    }
+    keyList[i] = key
+    if withValues {
+      valueData := r.bytes()
+      if r.err != nil {
+        return header, nil, nil, corrupt(i, offset, key, "%v", r.err)
+      }
+      if valueList[i], err = values.Decode(valueData); err != nil {
+        return header, nil, nil, corrupt(i, offset, key, "value: %w", err)
+      }
+    }
+  }
+  if r.offset < len(payload) {
+    return header, nil, nil, corrupt(-1, r.at(), nil, "%v bytes after the last entry", len(payload)-r.offset)
+  }
+  return header, keyList, valueList, nil
+}
+
+func binaryCodecs[K, V any](keys utils.Codec[K], values utils.Codec[V]) (utils.Codec[K], utils.Codec[V]) {
+  if keys == nil {
+    keys = utils.BinaryCodec[K]{}
+  }
+  if values == nil {
+    values = utils.BinaryCodec[V]{}
+  }
+  return keys, values
+}
+
+// hasBytes tells whether values of V are written, zero-size values carry nothing
+func hasBytes[V any]() bool {
+  return reflect.TypeFor[V]().Size() > 0
+}
+
+// binaryReader reads uvarints and length-prefixed byte strings from data at offset, the first
+// error sticks
+type binaryReader struct {
+  data   []byte
+  offset int
+  err    error
+}
+
+// at returns the offset of the next byte to read
+func (r *binaryReader) at() int64 {
+  return int64(r.offset)
+}
+
+func (r *binaryReader) uvarint() uint64 {
+  if r.err != nil {
+    return 0
+  }
+  n, read := binary.Uvarint(r.data[r.offset:])
+  if read <= 0 {
+    r.err = fmt.Errorf("truncated data")
+    return 0
+  }
+  r.offset += read
+  return n
+}
+
+func (r *binaryReader) bytes() []byte {
+  n := r.uvarint()
+  if r.err != nil {
+    return nil
+  }
+  if n > uint64(len(r.data)-r.offset) {
+    r.err = fmt.Errorf("length %v past the end of the data", n)
+    return nil
+  }
+  field := r.data[r.offset : r.offset+int(n)]
+  r.offset += int(n)
+  return field
+}
@@ -1,4 +1,8 @@
 // Package GoTree functions and other helpful libraries for Tree Data Structure
 //
+// Every tree ranges with Go 1.23 iterators: All and Backward yield key/value pairs, KeysSeq and
+// ValuesSeq yield keys and values. Keys and Values keep returning slices for the callers that
+// index or sort them, which is why the sequences carry the Seq suffix.
+//
 // See Readme.md for more info.
 package gotree
@@ -0,0 +1,97 @@
+package gotree
+
+import (
+  "encoding/json"
+  "errors"
+  "fmt"
+  "strings"
+)
+
+// Errors of the structures, wrapped with their context: test them with errors.Is
+var (
+  ErrInvalidOrder       = errors.New("invalid order")                        // B-tree order below 3
+  ErrKeyNotFound        = errors.New("key not found")                        // Lookup of a missing key
+  ErrComparatorMismatch = errors.New("keys out of order for the comparator") // Sorted data read with another comparator
+  ErrCorruptData        = errors.New("corrupt data")                         // Data that does not decode to a valid structure
+)
+
+// KeyError is a failed operation on one key
+type KeyError struct {
+  Key any   // Offending key
+  Err error // Cause, ErrKeyNotFound for a lookup
+}
+
+func (e *KeyError) Error() string {
+  return fmt.Sprintf("gotree: key %v: %v", e.Key, e.Err)
+}
+
+func (e *KeyError) Unwrap() error {
+  return e.Err
+}
+
+// Lookup returns the value of key in m, or a *KeyError wrapping ErrKeyNotFound if it is missing
+func Lookup[K, V any](m interface{ Get(key K) (V, bool) }, key K) (V, error) {
+  value, found := m.Get(key)
+  if !found {
+    return value, &KeyError{Key: key, Err: ErrKeyNotFound}
+  }
+  return value, nil
+}
+
+// DecodeError is a deserialization failure, with where it happened: get it with errors.As
+type DecodeError struct {
+  Entry  int   // Index of the offending entry or node, -1 when the data fails as a whole
+  Offset int64 // Byte offset in the data, -1 when unknown
+  Key    any   // Offending key, nil when it was not decoded
+  Err    error // Cause, wrapping ErrCorruptData or ErrComparatorMismatch
+}
+
+func (e *DecodeError) Error() string {
+  var b strings.Builder
+  b.WriteString("gotree: ")
+  if e.Entry >= 0 {
+    fmt.Fprintf(&b, "entry %v", e.Entry)
+  } else {
+    b.WriteString("data")
+  }
+  if e.Offset >= 0 {
+    fmt.Fprintf(&b, " at offset %v", e.Offset)
+  }
+  if e.Key != nil {
+    fmt.Fprintf(&b, ", key %v", e.Key)
+  }
+  fmt.Fprintf(&b, ": %v", e.Err)
+  return b.String()
+}
+
+func (e *DecodeError) Unwrap() error {
+  return e.Err
+}
+
+// corrupt returns a DecodeError of entry wrapping ErrCorruptData, with format and args as details
+func corrupt(entry int, offset int64, key any, format string, args ...any) *DecodeError {
+  return &DecodeError{Entry: entry, Offset: offset, Key: key, Err: fmt.Errorf("%w: "+format, append([]any{ErrCorruptData}, args...)...)}
+}
+
+// CorruptEntry returns a *DecodeError of entry, -1 for the data as a whole, at offset, -1 when
+// unknown, wrapping both ErrCorruptData and err
+func CorruptEntry(entry int, offset int64, err error) error {
+  return corrupt(entry, offset, nil, "%w", err)
+}
+
+// CorruptJSON returns err, an error of encoding/json, as a *DecodeError wrapping ErrCorruptData
+// at the offset of the syntax or type error; nil stays nil
+func CorruptJSON(err error) error {
+  if err == nil {
+    return nil
+  }
+  offset := int64(-1)
+  var syntaxError *json.SyntaxError
+  var typeError *json.UnmarshalTypeError
+  if errors.As(err, &syntaxError) {
+    offset = syntaxError.Offset
+  } else if errors.As(err, &typeError) {
+    offset = typeError.Offset
+  }
+  return corrupt(-1, offset, nil, "%w", err)
+}
@@ -0,0 +1,183 @@
+package gotree
+
+import (
+  "bufio"
+  "fmt"
+  "io"
+  "strings"
+
+  "github.com/fmorenovr/gods/utils"
+)
+
+// ExportOptions configures the WriteDOT and WriteMermaid exporters of the trees
+type ExportOptions[K any] struct {
+  Name      string // graph name, "tree" when empty
+  Highlight []K    // keys drawn highlighted
+}
+
+// Highlighted returns true if key is one of the highlighted keys under comp
+func (opts ExportOptions[K]) Highlighted(key K, comp utils.Comparator[K]) bool {
+  for _, highlight := range opts.Highlight {
+    if comp(key, highlight) == 0 {
+      return true
+    }
+  }
+  return false
+}
+
+// Graph is the drawing of a tree that WriteDOT and WriteMermaid render
+type Graph struct {
+  Name   string      `json:"name"`
+  Record bool        `json:"record"` // nodes are records of several entries (B-trees) instead of circles
+  Nodes  []GraphNode `json:"nodes"`
+  Edges  []GraphEdge `json:"edges"`
+}
+
+// GraphNode is a node of a Graph
+type GraphNode struct {
+  ID        string   `json:"id"`
+  Labels    []string `json:"labels"`              // the label of the node, or one label per entry of a record
+  Color     string   `json:"color,omitempty"`     // fill color, empty for the default
+  Highlight bool     `json:"highlight,omitempty"`
+  Invisible bool     `json:"invisible,omitempty"` // placeholder that keeps a lone child of a binary node on its side
+}
+
+// GraphEdge goes from a node to one of its children
+type GraphEdge struct {
+  From string `json:"from"`
+  To   string `json:"to"`
+  Port int    `json:"port"` // child index within a record node, ignored for circles
+}
+
+// NewGraph returns an empty graph named name, "tree" if name is empty
+func NewGraph(name string, record bool) *Graph {
+  if name == "" {
+    name = "tree"
+  }
+  return &Graph{Name: name, Record: record}
+}
+
+// AddNode appends a node with a fresh id and returns the id
+func (g *Graph) AddNode(node GraphNode) string {
+  node.ID = fmt.Sprintf("n%d", len(g.Nodes))
+  g.Nodes = append(g.Nodes, node)
+  return node.ID
+}
+
+// AddEdge appends an edge from the port of node from to node to
+func (g *Graph) AddEdge(from, to string, port int) {
+  g.Edges = append(g.Edges, GraphEdge{From: from, To: to, Port: port})
+}
+
+// Root returns the id of the node no edge points to, "" for an empty graph
+func (g *Graph) Root() string {
+  children := map[string]bool{}
+  for _, edge := range g.Edges {
+    children[edge.To] = true
+  }
+  for _, node := range g.Nodes {
+    if !children[node.ID] {
+      return node.ID
+    }
+  }
+  return ""
+}
+
+// WriteDOT renders g in Graphviz DOT
+func (g *Graph) WriteDOT(w io.Writer) error {
+  invisible := map[string]bool{}
+  out := bufio.NewWriter(w)
+  fmt.Fprintf(out, "digraph %s {\n", dotQuote(g.Name))
+  fmt.Fprintln(out, "  graph [ordering=out];")
+  if g.Record {
+    fmt.Fprintln(out, "  node [shape=record];")
+  } else {
+    fmt.Fprintln(out, "  node [shape=circle];")
+  }
+  for _, node := range g.Nodes {
+    var attributes []string
+    if g.Record {
+      fields := make([]string, 0, 2*len(node.Labels)+1)
+      for i, label := range node.Labels {
+        fields = append(fields, fmt.Sprintf("<p%d> ", i), dotRecordEscape(label))
+      }
+      fields = append(fields, fmt.Sprintf("<p%d> ", len(node.Labels)))
+      attributes = append(attributes, "label="+dotQuote(strings.Join(fields, "|")))
+    } else {
+      attributes = append(attributes, "label="+dotQuote(strings.Join(node.Labels, " ")))
+    }
+    if node.Color != "" {
+      attributes = append(attributes, "style=filled", "fillcolor="+dotQuote(node.Color), "fontcolor=white")
+    }
+    if node.Highlight {
+      attributes = append(attributes, `color="#ff8c00"`, "penwidth=3")
+    }
+    if node.Invisible {
+      invisible[node.ID] = true
+      attributes = []string{"style=invis", `label=""`}
+    }
+    fmt.Fprintf(out, "  %s [%s];\n", node.ID, strings.Join(attributes, ", "))
+  }
+  for _, edge := range g.Edges {
+    from := edge.From
+    if g.Record {
+      from = fmt.Sprintf("%s:p%d", edge.From, edge.Port)
+    }
+    if invisible[edge.To] {
+      fmt.Fprintf(out, "  %s -> %s [style=invis];\n", from, edge.To)
+    } else {
+      fmt.Fprintf(out, "  %s -> %s;\n", from, edge.To)
+    }
+  }
+  fmt.Fprintln(out, "}")
+  return out.Flush()
+}
+
+// WriteMermaid renders g as a Mermaid flowchart, invisible placeholders are left out
+func (g *Graph) WriteMermaid(w io.Writer) error {
+  invisible := map[string]bool{}
+  var highlighted []string
+  out := bufio.NewWriter(w)
+  fmt.Fprintf(out, "---\ntitle: %s\n---\n", mermaidEscape(g.Name))
+  fmt.Fprintln(out, "graph TD")
+  for _, node := range g.Nodes {
+    if node.Invisible {
+      invisible[node.ID] = true
+      continue
+    }
+    if g.Record {
+      fmt.Fprintf(out, "  %s[\"%s\"]\n", node.ID, mermaidEscape(strings.Join(node.Labels, " | ")))
+    } else {
+      fmt.Fprintf(out, "  %s((\"%s\"))\n", node.ID, mermaidEscape(strings.Join(node.Labels, " ")))
+    }
+    if node.Color != "" {
+      fmt.Fprintf(out, "  style %s fill:%s,color:#fff\n", node.ID, node.Color)
+    }
+    if node.Highlight {
+      highlighted = append(highlighted, node.ID)
+    }
+  }
+  for _, edge := range g.Edges {
+    if !invisible[edge.To] {
+      fmt.Fprintf(out, "  %s --> %s\n", edge.From, edge.To)
+    }
+  }
+  if len(highlighted) > 0 {
+    fmt.Fprintln(out, "  classDef highlight stroke:#ff8c00,stroke-width:4px")
+    fmt.Fprintf(out, "  class %s highlight\n", strings.Join(highlighted, ","))
+  }
+  return out.Flush()
+}
+
+func dotQuote(s string) string {
+  return `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`).Replace(s) + `"`
+}
+
+// dotRecordEscape escapes the characters that structure a record label
+func dotRecordEscape(s string) string {
+  return strings.NewReplacer(`\`, `\\`, "|", `\|`, "{", `\{`, "}", `\}`, "<", `\<`, ">", `\>`).Replace(s)
+}
+
+func mermaidEscape(s string) string {
+  return strings.NewReplacer(`"`, "#quot;", "\n", " ").Replace(s)
+}
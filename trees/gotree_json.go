@@ -0,0 +1,146 @@
+package gotree
+
+import (
+  "bufio"
+  "bytes"
+  "encoding/json"
+  "fmt"
+  "io"
+  "iter"
+
+  "github.com/fmorenovr/gods/utils"
+)
+
+// jsonEntry is one element of the JSON form of a tree
+type jsonEntry struct {
+  Key   json.RawMessage `json:"key"`
+  Value json.RawMessage `json:"value"`
+}
+
+// MarshalEntries writes entries as a JSON array of {"key":..,"value":..} objects, in the order
+// of the sequence. Nil codecs stand for utils.JSONCodec.
+func MarshalEntries[K, V any](entries iter.Seq2[K, V], keys utils.Codec[K], values utils.Codec[V]) ([]byte, error) {
+  var buffer bytes.Buffer
+  if err := EncodeEntries(&buffer, entries, keys, values); err != nil {
+    return nil, err
+  }
+  return buffer.Bytes(), nil
+}
+
+// EncodeEntries streams the JSON array of MarshalEntries to w, one entry at a time
+func EncodeEntries[K, V any](w io.Writer, entries iter.Seq2[K, V], keys utils.Codec[K], values utils.Codec[V]) error {
+  keys, values = jsonCodecs(keys, values)
+  buffer := bufio.NewWriter(w)
+  buffer.WriteByte('[')
+  i := 0
+  for key, value := range entries {
+    keyData, err := keys.Encode(key)
+    if err != nil {
+      return fmt.Errorf("gotree: entry %v, key %v: %w", i, key, err)
+    }
+    valueData, err := values.Encode(value)
+    if err != nil {
+      return fmt.Errorf("gotree: entry %v, value of key %v: %w", i, key, err)
+    }
+    if i > 0 {
+      buffer.WriteByte(',')
+    }
+    data, err := json.Marshal(jsonEntry{Key: keyData, Value: valueData})
+    if err != nil {
+      return fmt.Errorf("gotree: entry %v, key %v: %w", i, key, err)
+    }
+    if _, err := buffer.Write(data); err != nil {
+      return fmt.Errorf("gotree: entry %v: %w", i, err)
+    }
+    i++
+  }
+  buffer.WriteByte(']')
+  return buffer.Flush()
+}
+
+// DecodeEntries reads the JSON array of MarshalEntries from r one entry at a time and hands every
+// entry to fn, stopping at the first error. JSON null is no entries.
+func DecodeEntries[K, V any](r io.Reader, keys utils.Codec[K], values utils.Codec[V], fn func(key K, value V) error) error {
+  keys, values = jsonCodecs(keys, values)
+  decoder := json.NewDecoder(r)
+  token, err := decoder.Token()
+  if err != nil {
+    return CorruptJSON(err)
+  }
+  if token == nil {
+    return nil
+  }
+  if token != json.Delim('[') {
+    return corrupt(-1, 0, nil, "got %v expected an array", token)
+  }
+  for i := 0; decoder.More(); i++ {
+    offset := decoder.InputOffset()
+    var entry jsonEntry
+    if err := decoder.Decode(&entry); err != nil {
+      return corrupt(i, offset, nil, "%w", err)
+    }
+    key, value, err := decodeEntry(i, offset, entry, keys, values)
+    if err != nil {
+      return err
+    }
+    if err := fn(key, value); err != nil {
+      return err
+    }
+  }
+  if _, err := decoder.Token(); err != nil {
+    return CorruptJSON(err)
+  }
+  return nil
+}
+
+// UnmarshalEntries decodes the array written by MarshalEntries into its keys and values.
+// Nothing is returned unless every entry decodes, so that a tree is left untouched on error;
+// JSON null is no entries.
+func UnmarshalEntries[K, V any](data []byte, keys utils.Codec[K], values utils.Codec[V]) ([]K, []V, error) {
+  keys, values = jsonCodecs(keys, values)
+  var entries []jsonEntry
+  if err := json.Unmarshal(data, &entries); err != nil {
+    return nil, nil, CorruptJSON(err)
+  }
+  keyList, valueList := make([]K, len(entries)), make([]V, len(entries))
+  for i, entry := range entries {
+    key, value, err := decodeEntry(i, -1, entry, keys, values)
+    if err != nil {
+      return nil, nil, err
+    }
+    keyList[i], valueList[i] = key, value
+  }
+  return keyList, valueList, nil
+}
+
+// decodeEntry decodes the i-th entry, at offset in the data (-1 if unknown), with the codecs
+func decodeEntry[K, V any](i int, offset int64, entry jsonEntry, keys utils.Codec[K], values utils.Codec[V]) (key K, value V, err error) {
+  if entry.Key == nil {
+    return key, value, corrupt(i, offset, nil, "no key")
+  }
+  if key, err = keys.Decode(entry.Key); err != nil {
+    return key, value, corrupt(i, offset, string(entry.Key), "%w", err)
+  }
+  if value, err = values.Decode(orNull(entry.Value)); err != nil {
+    return key, value, corrupt(i, offset, key, "value: %w", err)
+  }
+  return key, value, nil
+}
+
+func jsonCodecs[K, V any](keys utils.Codec[K], values utils.Codec[V]) (utils.Codec[K], utils.Codec[V]) {
+  if keys == nil {
+    keys = utils.JSONCodec[K]{}
+  }
+  if values == nil {
+    values = utils.JSONCodec[V]{}
+  }
+  return keys, values
+}
+
+// orNull returns JSON null for a missing value
+func orNull(data json.RawMessage) []byte {
+  if data == nil {
+    return []byte("null")
+  }
+  return data
+}
@@ -0,0 +1,48 @@
+package gotree
+
+import (
+  "github.com/fmorenovr/gods/utils"
+)
+
+// Inclusivity tells a range iterator which of its bounds belong to the range
+type Inclusivity byte
+
+const (
+  IncludeBoth Inclusivity = iota // [lo, hi]
+  ExcludeLow                     // (lo, hi]
+  ExcludeHigh                    // [lo, hi)
+  ExcludeBoth                    // (lo, hi)
+)
+
+// IncludesLow returns true if lo belongs to the range
+func (i Inclusivity) IncludesLow() bool {
+  return i == IncludeBoth || i == ExcludeHigh
+}
+
+// IncludesHigh returns true if hi belongs to the range
+func (i Inclusivity) IncludesHigh() bool {
+  return i == IncludeBoth || i == ExcludeLow
+}
+
+// RangeMap is an OrderedMap whose iterators can start anywhere in O(log n)
+type RangeMap[K, V any] interface {
+  OrderedMap[K, V]
+  // RangeIterator returns an iterator over the keys between lo and hi
+  RangeIterator(lo, hi K, inclusivity Inclusivity) utils.ReverseIteratorWithKey[K, V]
+  // IteratorAt returns an iterator placed just before the smallest key larger than or equal to key
+  IteratorAt(key K) utils.ReverseIteratorWithKey[K, V]
+}
+
+func (i Inclusivity) String() string {
+  switch i {
+  case IncludeBoth:
+    return "[lo, hi]"
+  case ExcludeLow:
+    return "(lo, hi]"
+  case ExcludeHigh:
+    return "[lo, hi)"
+  case ExcludeBoth:
+    return "(lo, hi)"
+  }
+  return "Inclusivity(?)"
+}
@@ -0,0 +1,132 @@
+package gotree
+
+import (
+  "encoding/json"
+  "fmt"
+
+  "github.com/fmorenovr/gods/utils"
+)
+
+// ShapeNode is one node of a binary tree in its shape form
+type ShapeNode[K, V any] struct {
+  Key     K
+  Value   V
+  Balance *int   // Balance factor of an AVL node, nil for the other trees
+  Color   string // "red" or "black" for a red-black node, empty for the other trees
+}
+
+// Shape tells MarshalShape and UnmarshalShape how to walk and rebuild the nodes N of a binary
+// tree, the zero N being no node
+type Shape[N comparable, K, V any] struct {
+  Children func(node N) (left, right N)       // Children of a node
+  Node     func(node N) ShapeNode[K, V]       // Describes a node
+  New      func(s ShapeNode[K, V]) (N, error) // Builds a detached node, an error rejects the data
+  Link     func(parent, left, right N)        // Attaches the children of parent, either may be zero
+}
+
+// shapeNode is a ShapeNode in JSON
+type shapeNode struct {
+  Key     json.RawMessage `json:"key"`
+  Value   json.RawMessage `json:"value"`
+  Balance *int            `json:"bf,omitempty"`
+  Color   string          `json:"color,omitempty"`
+}
+
+// MarshalShape writes the binary tree under root as a LeetCode style level-order JSON array: the
+// root first, then the two children of every node in the array, null standing for a missing child,
+// trailing nulls left out. Nodes are {"key":..,"value":..} objects with "bf" or "color" when set.
+// Nil codecs stand for utils.JSONCodec.
+func MarshalShape[N comparable, K, V any](root N, shape Shape[N, K, V], keys utils.Codec[K], values utils.Codec[V]) ([]byte, error) {
+  keys, values = jsonCodecs(keys, values)
+  var zero N
+  var nodes []*shapeNode
+  queue := []N{root}
+  for i := 0; i < len(queue); i++ {
+    if queue[i] == zero {
+      nodes = append(nodes, nil)
+      continue
+    }
+    s := shape.Node(queue[i])
+    keyData, err := keys.Encode(s.Key)
+    if err != nil {
+      return nil, fmt.Errorf("gotree: node %v, key %v: %w", i, s.Key, err)
+    }
+    valueData, err := values.Encode(s.Value)
+    if err != nil {
+      return nil, fmt.Errorf("gotree: node %v, value of key %v: %w", i, s.Key, err)
+    }
+    nodes = append(nodes, &shapeNode{Key: keyData, Value: valueData, Balance: s.Balance, Color: s.Color})
+    left, right := shape.Children(queue[i])
+    queue = append(queue, left, right)
+  }
+  for len(nodes) > 0 && nodes[len(nodes)-1] == nil {
+    nodes = nodes[:len(nodes)-1]
+  }
+  if nodes == nil {
+    nodes = []*shapeNode{}
+  }
+  return json.Marshal(nodes)
+}
+
+// UnmarshalShape rebuilds the node layout written by MarshalShape and returns its root and its
+// number of nodes. It only checks the layout, the caller validates the tree it forms. Errors are
+// *DecodeError wrapping ErrCorruptData, Entry being the index of the offending element.
+func UnmarshalShape[N comparable, K, V any](data []byte, shape Shape[N, K, V], keys utils.Codec[K], values utils.Codec[V]) (N, int, error) {
+  keys, values = jsonCodecs(keys, values)
+  var zero N
+  var nodes []*shapeNode
+  if err := json.Unmarshal(data, &nodes); err != nil {
+    return zero, 0, CorruptJSON(err)
+  }
+  built := make([]N, len(nodes))
+  size := 0
+  for i, node := range nodes {
+    if node == nil {
+      continue
+    }
+    if node.Key == nil {
+      return zero, 0, corrupt(i, -1, nil, "no key")
+    }
+    key, err := keys.Decode(node.Key)
+    if err != nil {
+      return zero, 0, corrupt(i, -1, string(node.Key), "key: %w", err)
+    }
+    value, err := values.Decode(orNull(node.Value))
+    if err != nil {
+      return zero, 0, corrupt(i, -1, key, "value: %w", err)
+    }
+    if built[i], err = shape.New(ShapeNode[K, V]{Key: key, Value: value, Balance: node.Balance, Color: node.Color}); err != nil {
+      return zero, 0, corrupt(i, -1, key, "%w", err)
+    }
+    size++
+  }
+  if len(built) == 0 {
+    return zero, 0, nil
+  }
+  if built[0] == zero {
+    return zero, 0, corrupt(0, -1, nil, "no root but %v more nodes", len(built)-1)
+  }
+  // every node takes the next two elements as its children, in level order
+  next := 1
+  for i, parent := range built {
+    if next >= len(built) {
+      break
+    }
+    if parent == zero {
+      continue
+    }
+    if i >= next {
+      return zero, 0, corrupt(i, -1, nil, "nobody's child")
+    }
+    left, right := built[next], zero
+    if next+1 < len(built) {
+      right = built[next+1]
+    }
+    shape.Link(parent, left, right)
+    next += 2
+  }
+  if next < len(built) {
+    return zero, 0, corrupt(next, -1, nil, "%v elements past the last node", len(built)-next)
+  }
+  return built[0], size, nil
+}
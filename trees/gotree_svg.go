@@ -0,0 +1,130 @@
+package gotree
+
+import (
+  "bufio"
+  "fmt"
+  "html"
+  "io"
+  "strings"
+)
+
+const (
+  svgCharWidth  = 8  // approximate width of a character of the 14px label font
+  svgRadius     = 18 // smallest horizontal radius of a circle node, drawn as an ellipse around wider labels
+  svgFieldPad   = 12 // padding around the label of a record field
+  svgNodeHeight = 36 // height of every node
+  svgGap        = 16 // horizontal space between two subtrees
+  svgLevel      = 72 // vertical distance between two levels
+  svgMargin     = 24
+)
+
+// svgBox is the computed position of a node, x is its center and y its top
+type svgBox struct {
+  node       *GraphNode
+  x, y       int
+  width      int
+  fieldWidth int // width of each field of a record
+}
+
+// WriteSVG renders g as a standalone SVG image. Leaves are laid out left to right in the
+// order of the edges, every parent is centered over its children and invisible
+// placeholders keep their slot without being drawn.
+func (g *Graph) WriteSVG(w io.Writer) error {
+  children := map[string][]GraphEdge{}
+  for _, edge := range g.Edges {
+    children[edge.From] = append(children[edge.From], edge)
+  }
+  boxes := map[string]*svgBox{}
+  for i := range g.Nodes {
+    node := &g.Nodes[i]
+    box := &svgBox{node: node}
+    if g.Record {
+      box.fieldWidth = svgNodeHeight
+      for _, label := range node.Labels {
+        box.fieldWidth = max(box.fieldWidth, len(label)*svgCharWidth+2*svgFieldPad)
+      }
+      box.width = max(box.fieldWidth*len(node.Labels), svgNodeHeight)
+    } else {
+      box.width = max(2*svgRadius, len(strings.Join(node.Labels, " "))*svgCharWidth+svgFieldPad)
+    }
+    boxes[node.ID] = box
+  }
+  cursor, depth := svgMargin, 0
+  var place func(id string, level int)
+  place = func(id string, level int) {
+    box := boxes[id]
+    box.y = svgMargin + level*svgLevel
+    depth = max(depth, level)
+    if len(children[id]) == 0 {
+      box.x = cursor + box.width/2
+      cursor += box.width + svgGap
+      return
+    }
+    for _, edge := range children[id] {
+      place(edge.To, level+1)
+    }
+    first, last := boxes[children[id][0].To], boxes[children[id][len(children[id])-1].To]
+    box.x = (first.x + last.x) / 2
+    if left := box.x - box.width/2; left < svgMargin {
+      box.x += svgMargin - left
+    }
+    cursor = max(cursor, box.x+box.width/2+svgGap)
+  }
+  if root := g.Root(); root != "" {
+    place(root, 0)
+  }
+  width, height := max(cursor-svgGap+svgMargin, 2*svgMargin), svgMargin*2+depth*svgLevel+svgNodeHeight
+  if len(g.Nodes) == 0 {
+    width, height = 200, 2*svgMargin+svgNodeHeight
+  }
+
+  out := bufio.NewWriter(w)
+  fmt.Fprintf(out, `<svg xmlns="http://www.w3.org/2000/svg" width="%d" height="%d" viewBox="0 0 %d %d" font-family="monospace" font-size="14">`+"\n", width, height, width, height)
+  fmt.Fprintf(out, "  <title>%s</title>\n", html.EscapeString(g.Name))
+  if len(g.Nodes) == 0 {
+    fmt.Fprintf(out, `  <text x="%d" y="%d" text-anchor="middle" fill="#888">empty</text>`+"\n", width/2, height/2)
+  }
+  for _, edge := range g.Edges {
+    from, to := boxes[edge.From], boxes[edge.To]
+    if from == nil || to == nil || to.node.Invisible {
+      continue
+    }
+    x := from.x
+    if g.Record {
+      x = from.x - from.width/2 + edge.Port*from.fieldWidth
+    }
+    fmt.Fprintf(out, `  <line x1="%d" y1="%d" x2="%d" y2="%d" stroke="#555"/>`+"\n", x, from.y+svgNodeHeight, to.x, to.y)
+  }
+  for i := range g.Nodes {
+    box := boxes[g.Nodes[i].ID]
+    node := box.node
+    if node.Invisible {
+      continue
+    }
+    fill, text, stroke, strokeWidth := "#fff", "#000", "#333", 1
+    if node.Color != "" {
+      fill, text = node.Color, "#fff"
+    }
+    if node.Highlight {
+      stroke, strokeWidth = "#ff8c00", 4
+    }
+    fmt.Fprintf(out, `  <g id="%s">`+"\n", html.EscapeString(node.ID))
+    centerY := box.y + svgNodeHeight/2
+    if g.Record {
+      left := box.x - box.width/2
+      fmt.Fprintf(out, `    <rect x="%d" y="%d" width="%d" height="%d" fill="%s" stroke="%s" stroke-width="%d"/>`+"\n", left, box.y, box.width, svgNodeHeight, html.EscapeString(fill), stroke, strokeWidth)
+      for j, label := range node.Labels {
+        if j > 0 {
+          fmt.Fprintf(out, `    <line x1="%d" y1="%d" x2="%d" y2="%d" stroke="%s"/>`+"\n", left+j*box.fieldWidth, box.y, left+j*box.fieldWidth, box.y+svgNodeHeight, stroke)
+        }
+        fmt.Fprintf(out, `    <text x="%d" y="%d" text-anchor="middle" dominant-baseline="central" fill="%s">%s</text>`+"\n", left+j*box.fieldWidth+box.fieldWidth/2, centerY, text, html.EscapeString(label))
+      }
+    } else {
+      fmt.Fprintf(out, `    <ellipse cx="%d" cy="%d" rx="%d" ry="%d" fill="%s" stroke="%s" stroke-width="%d"/>`+"\n", box.x, centerY, box.width/2, svgNodeHeight/2, html.EscapeString(fill), stroke, strokeWidth)
+      fmt.Fprintf(out, `    <text x="%d" y="%d" text-anchor="middle" dominant-baseline="central" fill="%s">%s</text>`+"\n", box.x, centerY, text, html.EscapeString(strings.Join(node.Labels, " ")))
+    }
+    fmt.Fprintln(out, "  </g>")
+  }
+  fmt.Fprintln(out, "</svg>")
+  return out.Flush()
+}
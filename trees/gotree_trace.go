@@ -0,0 +1,176 @@
+package gotree
+
+import (
+  "bufio"
+  "bytes"
+  "encoding/json"
+  "fmt"
+  "io"
+)
+
+// EventKind tells operations requested by the caller apart from the steps the tree takes to rebalance
+type EventKind string
+
+const (
+  OperationEvent EventKind = "operation" // Put, Insert, Remove, Push or Pop called on the structure
+  StepEvent      EventKind = "step"      // rotation, red-black fixup case, B-tree split or rebalance, heap bubbling
+)
+
+// Event is one entry of a trace. Operations carry the keys (and the value, for Put) they were
+// called with, steps carry the keys of the nodes they touch.
+type Event struct {
+  Tree  string    `json:"tree"` // avltree, redblacktree, btree or binaryheap
+  Kind  EventKind `json:"kind"`
+  Name  string    `json:"name"` // method or step name, rotateLeft, insertCase3, splitRoot, bubbleUp...
+  Keys  []any     `json:"keys,omitempty"`
+  Value any       `json:"value,omitempty"`
+}
+
+// Operation returns the event of the operation name called with keys
+func Operation[K any](tree, name string, keys ...K) Event {
+  return Event{Tree: tree, Kind: OperationEvent, Name: name, Keys: anys(keys)}
+}
+
+// Step returns the event of a balancing step touching the nodes of keys
+func Step[K any](tree, name string, keys ...K) Event {
+  return Event{Tree: tree, Kind: StepEvent, Name: name, Keys: anys(keys)}
+}
+
+// WithValue returns a copy of the event carrying value
+func (e Event) WithValue(value any) Event {
+  e.Value = value
+  return e
+}
+
+// String returns the event as "tree kind name keys"
+func (e Event) String() string {
+  return fmt.Sprintf("%v %v %v %v", e.Tree, e.Kind, e.Name, e.Keys)
+}
+
+func anys[K any](keys []K) []any {
+  if len(keys) == 0 {
+    return nil
+  }
+  values := make([]any, len(keys))
+  for i, key := range keys {
+    values[i] = key
+  }
+  return values
+}
+
+// Tracer receives the events of a structure, see SetTracer on the trees and the heap
+type Tracer interface {
+  Trace(event Event)
+}
+
+// TracerFunc adapts a function to a Tracer
+type TracerFunc func(event Event)
+
+// Trace calls f(event)
+func (f TracerFunc) Trace(event Event) {
+  f(event)
+}
+
+// Recorder is a Tracer that keeps the events in memory
+type Recorder struct {
+  Events []Event
+}
+
+// Trace appends event to the recorded events
+func (r *Recorder) Trace(event Event) {
+  r.Events = append(r.Events, event)
+}
+
+// JSONTracer is a Tracer that writes every event as a line of JSON
+type JSONTracer struct {
+  encoder *json.Encoder
+  err     error
+}
+
+// NewJSONTracer returns a tracer writing JSON lines into w
+func NewJSONTracer(w io.Writer) *JSONTracer {
+  return &JSONTracer{encoder: json.NewEncoder(w)}
+}
+
+// Trace writes event as a line of JSON, after a failed write the following events are dropped
+func (t *JSONTracer) Trace(event Event) {
+  if t.err == nil {
+    t.err = t.encoder.Encode(event)
+  }
+}
+
+// Err returns the first error met writing events
+func (t *JSONTracer) Err() error {
+  return t.err
+}
+
+// ReadTrace reads the JSON lines written by a JSONTracer, numbers are kept as json.Number
+// until DecodeEvent gives them their type
+func ReadTrace(r io.Reader) ([]Event, error) {
+  var events []Event
+  scanner := bufio.NewScanner(r)
+  scanner.Buffer(nil, 1<<24)
+  for line := 1; scanner.Scan(); line++ {
+    if len(scanner.Bytes()) == 0 {
+      continue
+    }
+    var event Event
+    decoder := json.NewDecoder(bytes.NewReader(scanner.Bytes()))
+    decoder.UseNumber()
+    if err := decoder.Decode(&event); err != nil {
+      return events, fmt.Errorf("gotree: trace line %v: %w", line, err)
+    }
+    events = append(events, event)
+  }
+  return events, scanner.Err()
+}
+
+// DecodeEvent returns the keys and value of an event as K and V, whether the event was
+// recorded in memory or read back with ReadTrace
+func DecodeEvent[K, V any](e Event) (keys []K, value V, err error) {
+  if err = convert(e.Keys, &keys); err != nil {
+    return nil, value, fmt.Errorf("gotree: keys of %v: %w", e, err)
+  }
+  if e.Value != nil {
+    if err = convert(e.Value, &value); err != nil {
+      return nil, value, fmt.Errorf("gotree: value of %v: %w", e, err)
+    }
+  }
+  return keys, value, nil
+}
+
+// convert copies from into to through their JSON encoding
+func convert(from, to any) error {
+  data, err := json.Marshal(from)
+  if err != nil {
+    return err
+  }
+  return json.Unmarshal(data, to)
+}
+
+// Replay applies the Put, Insert and Remove operations of events to m, in order.
+// Steps are skipped: a tree of the same kind takes them again on its own.
+func Replay[K, V any](events []Event, m OrderedMap[K, V]) error {
+  for _, event := range events {
+    if event.Kind != OperationEvent {
+      continue
+    }
+    keys, value, err := DecodeEvent[K, V](event)
+    if err != nil {
+      return err
+    }
+    switch event.Name {
+    case "Put", "Insert":
+      for _, key := range keys {
+        m.Put(key, value)
+      }
+    case "Remove":
+      for _, key := range keys {
+        m.Remove(key)
+      }
+    default:
+      return fmt.Errorf("gotree: cannot replay operation %v on a map", event)
+    }
+  }
+  return nil
+}
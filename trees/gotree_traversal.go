@@ -0,0 +1,25 @@
+package gotree
+
+// TraversalOrder is the order in which Walk visits the nodes of a tree
+type TraversalOrder byte
+
+const (
+  PreOrder   TraversalOrder = iota // node before its subtrees
+  InOrder                          // left subtree, node, right subtree: ascending keys
+  PostOrder                        // subtrees before their node
+  LevelOrder                       // level by level from the root, left to right
+)
+
+func (order TraversalOrder) String() string {
+  switch order {
+  case PreOrder:
+    return "PreOrder"
+  case InOrder:
+    return "InOrder"
+  case PostOrder:
+    return "PostOrder"
+  case LevelOrder:
+    return "LevelOrder"
+  }
+  return "TraversalOrder(?)"
+}
@@ -0,0 +1,34 @@
+// Copyright (c) 2015, Emir Pasic. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package redblacktree
+
+import (
+	"fmt"
+
+	"github.com/fmorenovr/gods/utils"
+)
+
+// debugCheck runs after every mutation in builds with the godsdebug tag (see utils.Debug).
+// It spot-checks the comparator on key and the keys along its search path down to a leaf,
+// then re-validates the tree, and panics with a report if anything is wrong.
+func (tree *Tree[K, V]) debugCheck(operation string, key K) {
+	keys := []K{key}
+	for node := tree.Root; node != nil; {
+		keys = append(keys, node.Key)
+		// keep going past an equal key, its subtree is where a bad comparator hurts
+		if tree.Comparator(key, node.Key) < 0 {
+			node = node.Left
+		} else {
+			node = node.Right
+		}
+	}
+	err := utils.CheckComparator(tree.Comparator, keys...)
+	if err == nil {
+		err = tree.Validate()
+	}
+	if err != nil {
+		utils.DebugFailure("redblacktree.Tree", fmt.Sprintf("%v(%v)", operation, key), err, tree)
+	}
+}
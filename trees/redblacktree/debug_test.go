@@ -0,0 +1,31 @@
+// Copyright (c) 2015, Emir Pasic. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+//go:build godsdebug
+
+package redblacktree
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestRedBlackTreeDebugBadComparator(t *testing.T) {
+	// not antisymmetric: the first argument always wins unless the keys are equal
+	tree := NewWith[int, string](func(a, b int) int {
+		if a == b {
+			return 0
+		}
+		return 1
+	})
+	defer func() {
+		err, _ := recover().(error)
+		if err == nil || !strings.Contains(err.Error(), "redblacktree.Tree.Put(") || !strings.Contains(err.Error(), "antisymmetric") {
+			t.Errorf("Got %v expected a comparator report from Put", err)
+		}
+	}()
+	for _, key := range []int{1, 2, 3} {
+		tree.Put(key, "")
+	}
+}
@@ -0,0 +1,51 @@
+// Copyright (c) 2015, Emir Pasic. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package redblacktree
+
+import (
+	"fmt"
+	"github.com/fmorenovr/gods/trees"
+	"io"
+)
+
+// WriteDOT writes the tree in Graphviz DOT, nodes filled with their red or black color.
+func (tree *Tree[K, V]) WriteDOT(w io.Writer, opts gotree.ExportOptions[K]) error {
+	return tree.Graph(opts).WriteDOT(w)
+}
+
+// WriteMermaid writes the tree as a Mermaid flowchart, nodes filled with their red or black color.
+func (tree *Tree[K, V]) WriteMermaid(w io.Writer, opts gotree.ExportOptions[K]) error {
+	return tree.Graph(opts).WriteMermaid(w)
+}
+
+// Graph returns the drawing that WriteDOT and WriteMermaid render.
+func (tree *Tree[K, V]) Graph(opts gotree.ExportOptions[K]) *gotree.Graph {
+	g := gotree.NewGraph(opts.Name, false)
+	if tree.Root != nil {
+		tree.addGraph(g, tree.Root, opts)
+	}
+	return g
+}
+
+// addGraph adds the subtree of node to g and returns the id of node.
+// A lone child gets an invisible sibling so that it is drawn on its side.
+func (tree *Tree[K, V]) addGraph(g *gotree.Graph, node *Node[K, V], opts gotree.ExportOptions[K]) string {
+	id := g.AddNode(gotree.GraphNode{
+		Labels:    []string{fmt.Sprintf("%v", node.Key)},
+		Color:     node.color.String(),
+		Highlight: opts.Highlighted(node.Key, tree.Comparator),
+	})
+	if node.Left == nil && node.Right == nil {
+		return id
+	}
+	for _, child := range []*Node[K, V]{node.Left, node.Right} {
+		if child == nil {
+			g.AddEdge(id, g.AddNode(gotree.GraphNode{Invisible: true}), 0)
+		} else {
+			g.AddEdge(id, tree.addGraph(g, child, opts), 0)
+		}
+	}
+	return id
+}
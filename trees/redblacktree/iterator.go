@@ -4,46 +4,113 @@
 
 package redblacktree
 
-import "github.com/emirpasic/gods/containers"
+import (
+	"iter"
+
+	"github.com/fmorenovr/gods/trees"
+	"github.com/fmorenovr/gods/utils"
+)
 
 func assertIteratorImplementation() {
-	var _ containers.ReverseIteratorWithKey = (*Iterator)(nil)
+	var _ utils.ReverseIteratorWithKey[string, int] = (*Iterator[string, int])(nil)
 }
 
 // Iterator holding the iterator's state
-type Iterator struct {
-	tree     *Tree
-	node     *Node
+type Iterator[K comparable, V any] struct {
+	tree     *Tree[K, V]
+	node     *Node[K, V]
 	position position
+	bounded  bool        // true for range iterators
+	low      *Node[K, V] // first node of the range, nil if the range is empty
+	high     *Node[K, V] // last node of the range, nil if the range is empty
 }
 
 type position byte
 
+// before means that the iterator sits just before its node, see IteratorAt.
 const (
-	begin, between, end position = 0, 1, 2
+	begin, between, end, before position = 0, 1, 2, 3
 )
 
 // Iterator returns a stateful iterator whose elements are key/value pairs.
-func (tree *Tree) Iterator() Iterator {
-	return Iterator{tree: tree, node: nil, position: begin}
+func (tree *Tree[K, V]) Iterator() utils.ReverseIteratorWithKey[K, V] {
+	return &Iterator[K, V]{tree: tree, node: nil, position: begin}
+}
+
+// RangeIterator returns a stateful iterator over the keys between lo and hi.
+// Inclusivity tells whether lo and hi themselves belong to the range.
+//
+// The iterator is positioned in O(log n). Begin(), End(), First() and Last()
+// refer to the bounds of the range rather than to the whole tree.
+func (tree *Tree[K, V]) RangeIterator(lo, hi K, inclusivity gotree.Inclusivity) utils.ReverseIteratorWithKey[K, V] {
+	var low, high *Node[K, V]
+	if inclusivity.IncludesLow() {
+		low, _ = tree.Ceiling(lo)
+	} else {
+		low, _ = tree.Higher(lo)
+	}
+	if inclusivity.IncludesHigh() {
+		high, _ = tree.Floor(hi)
+	} else {
+		high, _ = tree.Lower(hi)
+	}
+	if low == nil || high == nil || tree.Comparator(low.Key, high.Key) > 0 {
+		low, high = nil, nil
+	}
+	return &Iterator[K, V]{tree: tree, node: nil, position: begin, bounded: true, low: low, high: high}
+}
+
+// IteratorAt returns a stateful iterator positioned just before the smallest key
+// that is larger than or equal to the given key, in O(log n).
+// Next() moves to that key, Prev() moves to the largest key smaller than the given key.
+//
+// Key should adhere to the comparator's type assertion, otherwise method panics.
+func (tree *Tree[K, V]) IteratorAt(key K) utils.ReverseIteratorWithKey[K, V] {
+	node, found := tree.Ceiling(key)
+	if !found {
+		return &Iterator[K, V]{tree: tree, node: nil, position: end}
+	}
+	return &Iterator[K, V]{tree: tree, node: node, position: before}
+}
+
+// lowest returns the first node the iterator can reach.
+func (iterator *Iterator[K, V]) lowest() *Node[K, V] {
+	if iterator.bounded {
+		return iterator.low
+	}
+	return iterator.tree.Left()
+}
+
+// highest returns the last node the iterator can reach.
+func (iterator *Iterator[K, V]) highest() *Node[K, V] {
+	if iterator.bounded {
+		return iterator.high
+	}
+	return iterator.tree.Right()
 }
 
 // Next moves the iterator to the next element and returns true if there was a next element in the container.
 // If Next() returns true, then next element's key and value can be retrieved by Key() and Value().
 // If Next() was called for the first time, then it will point the iterator to the first element if it exists.
 // Modifies the state of the iterator.
-func (iterator *Iterator) Next() bool {
+func (iterator *Iterator[K, V]) Next() bool {
 	if iterator.position == end {
 		goto end
 	}
 	if iterator.position == begin {
-		left := iterator.tree.Left()
+		left := iterator.lowest()
 		if left == nil {
 			goto end
 		}
 		iterator.node = left
 		goto between
 	}
+	if iterator.position == before {
+		goto between
+	}
+	if iterator.bounded && iterator.node == iterator.high {
+		goto end
+	}
 	if iterator.node.Right != nil {
 		iterator.node = iterator.node.Right
 		for iterator.node.Left != nil {
@@ -74,18 +141,21 @@ between:
 // Prev moves the iterator to the previous element and returns true if there was a previous element in the container.
 // If Prev() returns true, then previous element's key and value can be retrieved by Key() and Value().
 // Modifies the state of the iterator.
-func (iterator *Iterator) Prev() bool {
+func (iterator *Iterator[K, V]) Prev() bool {
 	if iterator.position == begin {
 		goto begin
 	}
 	if iterator.position == end {
-		right := iterator.tree.Right()
+		right := iterator.highest()
 		if right == nil {
 			goto begin
 		}
 		iterator.node = right
 		goto between
 	}
+	if iterator.bounded && iterator.node == iterator.low {
+		goto begin
+	}
 	if iterator.node.Left != nil {
 		iterator.node = iterator.node.Left
 		for iterator.node.Right != nil {
@@ -115,26 +185,26 @@ between:
 
 // Value returns the current element's value.
 // Does not modify the state of the iterator.
-func (iterator *Iterator) Value() interface{} {
+func (iterator *Iterator[K, V]) Value() V {
 	return iterator.node.Value
 }
 
 // Key returns the current element's key.
 // Does not modify the state of the iterator.
-func (iterator *Iterator) Key() interface{} {
+func (iterator *Iterator[K, V]) Key() K {
 	return iterator.node.Key
 }
 
 // Begin resets the iterator to its initial state (one-before-first)
 // Call Next() to fetch the first element if any.
-func (iterator *Iterator) Begin() {
+func (iterator *Iterator[K, V]) Begin() {
 	iterator.node = nil
 	iterator.position = begin
 }
 
 // End moves the iterator past the last element (one-past-the-end).
 // Call Prev() to fetch the last element if any.
-func (iterator *Iterator) End() {
+func (iterator *Iterator[K, V]) End() {
 	iterator.node = nil
 	iterator.position = end
 }
@@ -142,7 +212,7 @@ func (iterator *Iterator) End() {
 // First moves the iterator to the first element and returns true if there was a first element in the container.
 // If First() returns true, then first element's key and value can be retrieved by Key() and Value().
 // Modifies the state of the iterator
-func (iterator *Iterator) First() bool {
+func (iterator *Iterator[K, V]) First() bool {
 	iterator.Begin()
 	return iterator.Next()
 }
@@ -150,7 +220,56 @@ func (iterator *Iterator) First() bool {
 // Last moves the iterator to the last element and returns true if there was a last element in the container.
 // If Last() returns true, then last element's key and value can be retrieved by Key() and Value().
 // Modifies the state of the iterator.
-func (iterator *Iterator) Last() bool {
+func (iterator *Iterator[K, V]) Last() bool {
 	iterator.End()
 	return iterator.Prev()
 }
+
+// All returns an iterator over the key/value pairs in key order, for use with for-range.
+// Stopping the loop early stops the traversal.
+func (tree *Tree[K, V]) All() iter.Seq2[K, V] {
+	return func(yield func(K, V) bool) {
+		it := tree.Iterator()
+		for it.Next() {
+			if !yield(it.Key(), it.Value()) {
+				return
+			}
+		}
+	}
+}
+
+// Backward returns an iterator over the key/value pairs in reverse key order.
+func (tree *Tree[K, V]) Backward() iter.Seq2[K, V] {
+	return func(yield func(K, V) bool) {
+		it := tree.Iterator()
+		it.End()
+		for it.Prev() {
+			if !yield(it.Key(), it.Value()) {
+				return
+			}
+		}
+	}
+}
+
+// KeysSeq returns an iterator over the keys in order, the lazy form of Keys().
+// Collect it with slices.Collect(tree.KeysSeq()).
+func (tree *Tree[K, V]) KeysSeq() iter.Seq[K] {
+	return func(yield func(K) bool) {
+		for key := range tree.All() {
+			if !yield(key) {
+				return
+			}
+		}
+	}
+}
+
+// ValuesSeq returns an iterator over the values in key order, the lazy form of Values().
+func (tree *Tree[K, V]) ValuesSeq() iter.Seq[V] {
+	return func(yield func(V) bool) {
+		for _, value := range tree.All() {
+			if !yield(value) {
+				return
+			}
+		}
+	}
+}
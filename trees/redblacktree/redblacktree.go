@@ -12,13 +12,16 @@
 package redblacktree
 
 import (
+	"cmp"
 	"fmt"
-	"github.com/emirpasic/gods/trees"
-	"github.com/emirpasic/gods/utils"
+	"github.com/fmorenovr/gods/trees"
+	"github.com/fmorenovr/gods/utils"
+	"iter"
 )
 
 func assertTreeImplementation() {
-	var _ trees.Tree = (*Tree)(nil)
+	var _ gotree.GoTree[int] = (*Tree[string, int])(nil)
+	var _ gotree.RangeMap[string, int] = (*Tree[string, int])(nil)
 }
 
 type color bool
@@ -27,44 +30,57 @@ const (
 	black, red color = true, false
 )
 
+// String returns "black" or "red".
+func (c color) String() string {
+	if c == black {
+		return "black"
+	}
+	return "red"
+}
+
 // Tree holds elements of the red-black tree
-type Tree struct {
-	Root       *Node
+type Tree[K comparable, V any] struct {
+	Root       *Node[K, V]
 	size       int
-	Comparator utils.Comparator
+	Comparator utils.Comparator[K]
+	tracer     gotree.Tracer
+	keyCodec   utils.Codec[K] // nil for the default codec
+	valueCodec utils.Codec[V] // nil for the default codec
 }
 
 // Node is a single element within the tree
-type Node struct {
-	Key    interface{}
-	Value  interface{}
+type Node[K comparable, V any] struct {
+	Key    K
+	Value  V
 	color  color
-	Left   *Node
-	Right  *Node
-	Parent *Node
-}
-
-// NewWith instantiates a red-black tree with the custom comparator.
-func NewWith(comparator utils.Comparator) *Tree {
-	return &Tree{Comparator: comparator}
+	Left   *Node[K, V]
+	Right  *Node[K, V]
+	Parent *Node[K, V]
+	size   int // number of nodes in the subtree rooted at this node
 }
 
-// NewWithIntComparator instantiates a red-black tree with the IntComparator, i.e. keys are of type int.
-func NewWithIntComparator() *Tree {
-	return &Tree{Comparator: utils.IntComparator}
+// New instantiates a red-black tree ordered by the natural order of K.
+func New[K cmp.Ordered, V any]() *Tree[K, V] {
+	return &Tree[K, V]{Comparator: utils.OrderedComparator[K]()}
 }
 
-// NewWithStringComparator instantiates a red-black tree with the StringComparator, i.e. keys are of type string.
-func NewWithStringComparator() *Tree {
-	return &Tree{Comparator: utils.StringComparator}
+// NewWith instantiates a red-black tree with the custom comparator.
+func NewWith[K comparable, V any](comparator utils.Comparator[K]) *Tree[K, V] {
+	return &Tree[K, V]{Comparator: comparator}
 }
 
 // Put inserts node into the tree.
 // Key should adhere to the comparator's type assertion, otherwise method panics.
-func (tree *Tree) Put(key interface{}, value interface{}) {
-	var insertedNode *Node
+func (tree *Tree[K, V]) Put(key K, value V) {
+	if utils.Debug {
+		defer tree.debugCheck("Put", key)
+	}
+	if tree.tracer != nil {
+		tree.tracer.Trace(gotree.Operation("redblacktree", "Put", key).WithValue(value))
+	}
+	var insertedNode *Node[K, V]
 	if tree.Root == nil {
-		tree.Root = &Node{Key: key, Value: value, color: red}
+		tree.Root = &Node[K, V]{Key: key, Value: value, color: red, size: 1}
 		insertedNode = tree.Root
 	} else {
 		node := tree.Root
@@ -78,7 +94,7 @@ func (tree *Tree) Put(key interface{}, value interface{}) {
 				return
 			case compare < 0:
 				if node.Left == nil {
-					node.Left = &Node{Key: key, Value: value, color: red}
+					node.Left = &Node[K, V]{Key: key, Value: value, color: red, size: 1}
 					insertedNode = node.Left
 					loop = false
 				} else {
@@ -86,7 +102,7 @@ func (tree *Tree) Put(key interface{}, value interface{}) {
 				}
 			case compare > 0:
 				if node.Right == nil {
-					node.Right = &Node{Key: key, Value: value, color: red}
+					node.Right = &Node[K, V]{Key: key, Value: value, color: red, size: 1}
 					insertedNode = node.Right
 					loop = false
 				} else {
@@ -95,26 +111,47 @@ func (tree *Tree) Put(key interface{}, value interface{}) {
 			}
 		}
 		insertedNode.Parent = node
+		for ; node != nil; node = node.Parent {
+			node.size++
+		}
 	}
 	tree.insertCase1(insertedNode)
 	tree.size++
 }
 
-// Get searches the node in the tree by key and returns its value or nil if key is not found in tree.
+// SetTracer sends every Put and Remove to tracer, followed by the rotations and the
+// insertCase/deleteCase fixups they go through. A nil tracer stops tracing.
+func (tree *Tree[K, V]) SetTracer(tracer gotree.Tracer) {
+	tree.tracer = tracer
+}
+
+// Get searches the node in the tree by key and returns its value or the zero value if key is not found in tree.
 // Second return parameter is true if key was found, otherwise false.
 // Key should adhere to the comparator's type assertion, otherwise method panics.
-func (tree *Tree) Get(key interface{}) (value interface{}, found bool) {
+func (tree *Tree[K, V]) Get(key K) (value V, found bool) {
 	node := tree.lookup(key)
 	if node != nil {
 		return node.Value, true
 	}
-	return nil, false
+	return value, false
+}
+
+// Contains returns true if key is found in the tree.
+// Key should adhere to the comparator's type assertion, otherwise method panics.
+func (tree *Tree[K, V]) Contains(key K) bool {
+	return tree.lookup(key) != nil
 }
 
 // Remove remove the node from the tree by key.
 // Key should adhere to the comparator's type assertion, otherwise method panics.
-func (tree *Tree) Remove(key interface{}) {
-	var child *Node
+func (tree *Tree[K, V]) Remove(key K) {
+	if utils.Debug {
+		defer tree.debugCheck("Remove", key)
+	}
+	if tree.tracer != nil {
+		tree.tracer.Trace(gotree.Operation("redblacktree", "Remove", key))
+	}
+	var child *Node[K, V]
 	node := tree.lookup(key)
 	if node == nil {
 		return
@@ -139,23 +176,36 @@ func (tree *Tree) Remove(key interface{}) {
 		if node.Parent == nil && child != nil {
 			child.color = black
 		}
+		for parent := node.Parent; parent != nil; parent = parent.Parent {
+			parent.size--
+		}
 	}
 	tree.size--
 }
 
 // Empty returns true if tree does not contain any nodes
-func (tree *Tree) Empty() bool {
+func (tree *Tree[K, V]) Empty() bool {
 	return tree.size == 0
 }
 
+// IsEmpty returns true if tree does not contain any nodes, same as Empty
+func (tree *Tree[K, V]) IsEmpty() bool {
+	return tree.Empty()
+}
+
 // Size returns number of nodes in the tree.
-func (tree *Tree) Size() int {
+func (tree *Tree[K, V]) Size() int {
+	return tree.size
+}
+
+// Len returns number of nodes in the tree, same as Size.
+func (tree *Tree[K, V]) Len() int {
 	return tree.size
 }
 
 // Keys returns all keys in-order
-func (tree *Tree) Keys() []interface{} {
-	keys := make([]interface{}, tree.size)
+func (tree *Tree[K, V]) Keys() []K {
+	keys := make([]K, tree.size)
 	it := tree.Iterator()
 	for i := 0; it.Next(); i++ {
 		keys[i] = it.Key()
@@ -164,8 +214,8 @@ func (tree *Tree) Keys() []interface{} {
 }
 
 // Values returns all values in-order based on the key.
-func (tree *Tree) Values() []interface{} {
-	values := make([]interface{}, tree.size)
+func (tree *Tree[K, V]) Values() []V {
+	values := make([]V, tree.size)
 	it := tree.Iterator()
 	for i := 0; it.Next(); i++ {
 		values[i] = it.Value()
@@ -174,8 +224,8 @@ func (tree *Tree) Values() []interface{} {
 }
 
 // Left returns the left-most (min) node or nil if tree is empty.
-func (tree *Tree) Left() *Node {
-	var parent *Node
+func (tree *Tree[K, V]) Left() *Node[K, V] {
+	var parent *Node[K, V]
 	current := tree.Root
 	for current != nil {
 		parent = current
@@ -185,8 +235,8 @@ func (tree *Tree) Left() *Node {
 }
 
 // Right returns the right-most (max) node or nil if tree is empty.
-func (tree *Tree) Right() *Node {
-	var parent *Node
+func (tree *Tree[K, V]) Right() *Node[K, V] {
+	var parent *Node[K, V]
 	current := tree.Root
 	for current != nil {
 		parent = current
@@ -195,6 +245,18 @@ func (tree *Tree) Right() *Node {
 	return parent
 }
 
+// Min returns the minimum key and its value.
+// Third return parameter is false if the tree is empty.
+func (tree *Tree[K, V]) Min() (key K, value V, found bool) {
+	return nodeEntry(tree.Left())
+}
+
+// Max returns the maximum key and its value.
+// Third return parameter is false if the tree is empty.
+func (tree *Tree[K, V]) Max() (key K, value V, found bool) {
+	return nodeEntry(tree.Right())
+}
+
 // Floor Finds floor node of the input key, return the floor node or nil if no ceiling is found.
 // Second return parameter is true if floor was found, otherwise false.
 //
@@ -203,7 +265,7 @@ func (tree *Tree) Right() *Node {
 // all nodes in the tree is larger than the given node.
 //
 // Key should adhere to the comparator's type assertion, otherwise method panics.
-func (tree *Tree) Floor(key interface{}) (floor *Node, found bool) {
+func (tree *Tree[K, V]) Floor(key K) (floor *Node[K, V], found bool) {
 	found = false
 	node := tree.Root
 	for node != nil {
@@ -232,7 +294,7 @@ func (tree *Tree) Floor(key interface{}) (floor *Node, found bool) {
 // all nodes in the tree is smaller than the given node.
 //
 // Key should adhere to the comparator's type assertion, otherwise method panics.
-func (tree *Tree) Ceiling(key interface{}) (ceiling *Node, found bool) {
+func (tree *Tree[K, V]) Ceiling(key K) (ceiling *Node[K, V], found bool) {
 	found = false
 	node := tree.Root
 	for node != nil {
@@ -253,14 +315,137 @@ func (tree *Tree) Ceiling(key interface{}) (ceiling *Node, found bool) {
 	return nil, false
 }
 
+// Lower finds the largest node that is strictly smaller than the given key.
+// Second return parameter is true if a lower node was found, otherwise false.
+//
+// Key should adhere to the comparator's type assertion, otherwise method panics.
+func (tree *Tree[K, V]) Lower(key K) (lower *Node[K, V], found bool) {
+	node := tree.Root
+	for node != nil {
+		if tree.Comparator(key, node.Key) <= 0 {
+			node = node.Left
+		} else {
+			lower, found = node, true
+			node = node.Right
+		}
+	}
+	return lower, found
+}
+
+// Higher finds the smallest node that is strictly larger than the given key.
+// Second return parameter is true if a higher node was found, otherwise false.
+//
+// Key should adhere to the comparator's type assertion, otherwise method panics.
+func (tree *Tree[K, V]) Higher(key K) (higher *Node[K, V], found bool) {
+	node := tree.Root
+	for node != nil {
+		if tree.Comparator(key, node.Key) < 0 {
+			higher, found = node, true
+			node = node.Left
+		} else {
+			node = node.Right
+		}
+	}
+	return higher, found
+}
+
+// FloorEntry returns the key and value of the floor node of the input key.
+// Third return parameter is false if no floor was found.
+func (tree *Tree[K, V]) FloorEntry(key K) (floor K, value V, found bool) {
+	node, _ := tree.Floor(key)
+	return nodeEntry(node)
+}
+
+// CeilingEntry returns the key and value of the ceiling node of the input key.
+// Third return parameter is false if no ceiling was found.
+func (tree *Tree[K, V]) CeilingEntry(key K) (ceiling K, value V, found bool) {
+	node, _ := tree.Ceiling(key)
+	return nodeEntry(node)
+}
+
+// Select returns the node holding the k-th smallest key, counting from 0.
+// Second return parameter is false if k is out of range [0, Size()).
+//
+// Runs in O(log n) using the subtree sizes kept in every node.
+func (tree *Tree[K, V]) Select(k int) (node *Node[K, V], found bool) {
+	if k < 0 || k >= tree.size {
+		return nil, false
+	}
+	node = tree.Root
+	for node != nil {
+		left := nodeSize(node.Left)
+		switch {
+		case k < left:
+			node = node.Left
+		case k > left:
+			k -= left + 1
+			node = node.Right
+		default:
+			return node, true
+		}
+	}
+	return nil, false
+}
+
+// Rank returns the number of keys in the tree that are strictly smaller than the given key.
+// The key does not need to be in the tree; if it is, Select(Rank(key)) returns its node.
+//
+// Key should adhere to the comparator's type assertion, otherwise method panics.
+func (tree *Tree[K, V]) Rank(key K) int {
+	return tree.rank(key, false)
+}
+
+// CountRange returns the number of keys k in the tree with lo <= k <= hi.
+//
+// Key should adhere to the comparator's type assertion, otherwise method panics.
+func (tree *Tree[K, V]) CountRange(lo, hi K) int {
+	if tree.Comparator(lo, hi) > 0 {
+		return 0
+	}
+	return tree.rank(hi, true) - tree.rank(lo, false)
+}
+
+// rank counts the keys smaller than key, or smaller than or equal to it if inclusive.
+func (tree *Tree[K, V]) rank(key K, inclusive bool) int {
+	rank := 0
+	node := tree.Root
+	for node != nil {
+		compare := tree.Comparator(key, node.Key)
+		if compare < 0 || (compare == 0 && !inclusive) {
+			node = node.Left
+		} else {
+			rank += nodeSize(node.Left) + 1
+			node = node.Right
+		}
+	}
+	return rank
+}
+
+// Walk visits the nodes in the given order, passing their key and value to visitor,
+// until visitor returns false. InOrder visits the keys in ascending order.
+func (tree *Tree[K, V]) Walk(order gotree.TraversalOrder, visitor func(key K, value V) bool) {
+	if order == gotree.LevelOrder {
+		walkLevels(tree.Root, visitor)
+	} else {
+		walk(tree.Root, order, visitor)
+	}
+}
+
+// Traverse returns an iterator over the key/value pairs in the given order, for use with for-range.
+func (tree *Tree[K, V]) Traverse(order gotree.TraversalOrder) iter.Seq2[K, V] {
+	return func(yield func(K, V) bool) {
+		tree.Walk(order, yield)
+	}
+}
+
 // Clear removes all nodes from the tree.
-func (tree *Tree) Clear() {
+func (tree *Tree[K, V]) Clear() {
 	tree.Root = nil
 	tree.size = 0
 }
 
 // String returns a string representation of container
-func (tree *Tree) String() string {
+func (tree *Tree[K, V]) String() string {
 	str := "RedBlackTree\n"
 	if !tree.Empty() {
 		output(tree.Root, "", true, &str)
@@ -268,11 +453,11 @@ func (tree *Tree) String() string {
 	return str
 }
 
-func (node *Node) String() string {
+func (node *Node[K, V]) String() string {
 	return fmt.Sprintf("%v", node.Key)
 }
 
-func output(node *Node, prefix string, isTail bool, str *string) {
+func output[K comparable, V any](node *Node[K, V], prefix string, isTail bool, str *string) {
 	if node.Right != nil {
 		newPrefix := prefix
 		if isTail {
@@ -300,7 +485,7 @@ func output(node *Node, prefix string, isTail bool, str *string) {
 	}
 }
 
-func (tree *Tree) lookup(key interface{}) *Node {
+func (tree *Tree[K, V]) lookup(key K) *Node[K, V] {
 	node := tree.Root
 	for node != nil {
 		compare := tree.Comparator(key, node.Key)
@@ -316,21 +501,21 @@ func (tree *Tree) lookup(key interface{}) *Node {
 	return nil
 }
 
-func (node *Node) grandparent() *Node {
+func (node *Node[K, V]) grandparent() *Node[K, V] {
 	if node != nil && node.Parent != nil {
 		return node.Parent.Parent
 	}
 	return nil
 }
 
-func (node *Node) uncle() *Node {
+func (node *Node[K, V]) uncle() *Node[K, V] {
 	if node == nil || node.Parent == nil || node.Parent.Parent == nil {
 		return nil
 	}
 	return node.Parent.sibling()
 }
 
-func (node *Node) sibling() *Node {
+func (node *Node[K, V]) sibling() *Node[K, V] {
 	if node == nil || node.Parent == nil {
 		return nil
 	}
@@ -340,8 +525,9 @@ func (node *Node) sibling() *Node {
 	return node.Parent.Left
 }
 
-func (tree *Tree) rotateLeft(node *Node) {
+func (tree *Tree[K, V]) rotateLeft(node *Node[K, V]) {
 	right := node.Right
+	tree.trace("rotateLeft", node, right)
 	tree.replaceNode(node, right)
 	node.Right = right.Left
 	if right.Left != nil {
@@ -349,10 +535,13 @@ func (tree *Tree) rotateLeft(node *Node) {
 	}
 	right.Left = node
 	node.Parent = right
+	right.size = node.size
+	node.size = 1 + nodeSize(node.Left) + nodeSize(node.Right)
 }
 
-func (tree *Tree) rotateRight(node *Node) {
+func (tree *Tree[K, V]) rotateRight(node *Node[K, V]) {
 	left := node.Left
+	tree.trace("rotateRight", node, left)
 	tree.replaceNode(node, left)
 	node.Left = left.Right
 	if left.Right != nil {
@@ -360,9 +549,11 @@ func (tree *Tree) rotateRight(node *Node) {
 	}
 	left.Right = node
 	node.Parent = left
+	left.size = node.size
+	node.size = 1 + nodeSize(node.Left) + nodeSize(node.Right)
 }
 
-func (tree *Tree) replaceNode(old *Node, new *Node) {
+func (tree *Tree[K, V]) replaceNode(old *Node[K, V], new *Node[K, V]) {
 	if old.Parent == nil {
 		tree.Root = new
 	} else {
@@ -377,7 +568,22 @@ func (tree *Tree) replaceNode(old *Node, new *Node) {
 	}
 }
 
-func (tree *Tree) insertCase1(node *Node) {
+// trace sends the step name touching nodes to the tracer, if any; nil nodes are left out.
+func (tree *Tree[K, V]) trace(name string, nodes ...*Node[K, V]) {
+	if tree.tracer == nil {
+		return
+	}
+	keys := make([]K, 0, len(nodes))
+	for _, node := range nodes {
+		if node != nil {
+			keys = append(keys, node.Key)
+		}
+	}
+	tree.tracer.Trace(gotree.Step("redblacktree", name, keys...))
+}
+
+func (tree *Tree[K, V]) insertCase1(node *Node[K, V]) {
+	tree.trace("insertCase1", node, node.Parent)
 	if node.Parent == nil {
 		node.color = black
 	} else {
@@ -385,15 +591,17 @@ func (tree *Tree) insertCase1(node *Node) {
 	}
 }
 
-func (tree *Tree) insertCase2(node *Node) {
+func (tree *Tree[K, V]) insertCase2(node *Node[K, V]) {
+	tree.trace("insertCase2", node, node.Parent)
 	if nodeColor(node.Parent) == black {
 		return
 	}
 	tree.insertCase3(node)
 }
 
-func (tree *Tree) insertCase3(node *Node) {
+func (tree *Tree[K, V]) insertCase3(node *Node[K, V]) {
 	uncle := node.uncle()
+	tree.trace("insertCase3", node, node.Parent, uncle, node.grandparent())
 	if nodeColor(uncle) == red {
 		node.Parent.color = black
 		uncle.color = black
@@ -404,8 +612,9 @@ func (tree *Tree) insertCase3(node *Node) {
 	}
 }
 
-func (tree *Tree) insertCase4(node *Node) {
+func (tree *Tree[K, V]) insertCase4(node *Node[K, V]) {
 	grandparent := node.grandparent()
+	tree.trace("insertCase4", node, node.Parent, grandparent)
 	if node == node.Parent.Right && node.Parent == grandparent.Left {
 		tree.rotateLeft(node.Parent)
 		node = node.Left
@@ -416,7 +625,8 @@ func (tree *Tree) insertCase4(node *Node) {
 	tree.insertCase5(node)
 }
 
-func (tree *Tree) insertCase5(node *Node) {
+func (tree *Tree[K, V]) insertCase5(node *Node[K, V]) {
+	tree.trace("insertCase5", node, node.Parent, node.grandparent())
 	node.Parent.color = black
 	grandparent := node.grandparent()
 	grandparent.color = red
@@ -427,7 +637,7 @@ func (tree *Tree) insertCase5(node *Node) {
 	}
 }
 
-func (node *Node) maximumNode() *Node {
+func (node *Node[K, V]) maximumNode() *Node[K, V] {
 	if node == nil {
 		return nil
 	}
@@ -437,15 +647,17 @@ func (node *Node) maximumNode() *Node {
 	return node
 }
 
-func (tree *Tree) deleteCase1(node *Node) {
+func (tree *Tree[K, V]) deleteCase1(node *Node[K, V]) {
+	tree.trace("deleteCase1", node, node.Parent)
 	if node.Parent == nil {
 		return
 	}
 	tree.deleteCase2(node)
 }
 
-func (tree *Tree) deleteCase2(node *Node) {
+func (tree *Tree[K, V]) deleteCase2(node *Node[K, V]) {
 	sibling := node.sibling()
+	tree.trace("deleteCase2", node, node.Parent, sibling)
 	if nodeColor(sibling) == red {
 		node.Parent.color = red
 		sibling.color = black
@@ -458,8 +670,9 @@ func (tree *Tree) deleteCase2(node *Node) {
 	tree.deleteCase3(node)
 }
 
-func (tree *Tree) deleteCase3(node *Node) {
+func (tree *Tree[K, V]) deleteCase3(node *Node[K, V]) {
 	sibling := node.sibling()
+	tree.trace("deleteCase3", node, node.Parent, sibling)
 	if nodeColor(node.Parent) == black &&
 		nodeColor(sibling) == black &&
 		nodeColor(sibling.Left) == black &&
@@ -471,8 +684,9 @@ func (tree *Tree) deleteCase3(node *Node) {
 	}
 }
 
-func (tree *Tree) deleteCase4(node *Node) {
+func (tree *Tree[K, V]) deleteCase4(node *Node[K, V]) {
 	sibling := node.sibling()
+	tree.trace("deleteCase4", node, node.Parent, sibling)
 	if nodeColor(node.Parent) == red &&
 		nodeColor(sibling) == black &&
 		nodeColor(sibling.Left) == black &&
@@ -484,8 +698,9 @@ func (tree *Tree) deleteCase4(node *Node) {
 	}
 }
 
-func (tree *Tree) deleteCase5(node *Node) {
+func (tree *Tree[K, V]) deleteCase5(node *Node[K, V]) {
 	sibling := node.sibling()
+	tree.trace("deleteCase5", node, node.Parent, sibling)
 	if node == node.Parent.Left &&
 		nodeColor(sibling) == black &&
 		nodeColor(sibling.Left) == red &&
@@ -504,8 +719,9 @@ func (tree *Tree) deleteCase5(node *Node) {
 	tree.deleteCase6(node)
 }
 
-func (tree *Tree) deleteCase6(node *Node) {
+func (tree *Tree[K, V]) deleteCase6(node *Node[K, V]) {
 	sibling := node.sibling()
+	tree.trace("deleteCase6", node, node.Parent, sibling)
 	sibling.color = nodeColor(node.Parent)
 	node.Parent.color = black
 	if node == node.Parent.Left && nodeColor(sibling.Right) == red {
@@ -517,7 +733,62 @@ func (tree *Tree) deleteCase6(node *Node) {
 	}
 }
 
-func nodeColor(node *Node) color {
+// walk visits node depth-first in the given order and returns false if the visitor stopped the walk.
+func walk[K comparable, V any](node *Node[K, V], order gotree.TraversalOrder, visitor func(K, V) bool) bool {
+	if node == nil {
+		return true
+	}
+	if order == gotree.PreOrder && !visitor(node.Key, node.Value) {
+		return false
+	}
+	if !walk(node.Left, order, visitor) {
+		return false
+	}
+	if order == gotree.InOrder && !visitor(node.Key, node.Value) {
+		return false
+	}
+	if !walk(node.Right, order, visitor) {
+		return false
+	}
+	return order != gotree.PostOrder || visitor(node.Key, node.Value)
+}
+
+// walkLevels visits node breadth-first until the visitor returns false.
+func walkLevels[K comparable, V any](node *Node[K, V], visitor func(K, V) bool) {
+	if node == nil {
+		return
+	}
+	queue := []*Node[K, V]{node}
+	for len(queue) > 0 {
+		node = queue[0]
+		queue = queue[1:]
+		if !visitor(node.Key, node.Value) {
+			return
+		}
+		if node.Left != nil {
+			queue = append(queue, node.Left)
+		}
+		if node.Right != nil {
+			queue = append(queue, node.Right)
+		}
+	}
+}
+
+func nodeEntry[K comparable, V any](node *Node[K, V]) (key K, value V, found bool) {
+	if node == nil {
+		return key, value, false
+	}
+	return node.Key, node.Value, true
+}
+
+func nodeSize[K comparable, V any](node *Node[K, V]) int {
+	if node == nil {
+		return 0
+	}
+	return node.size
+}
+
+func nodeColor[K comparable, V any](node *Node[K, V]) color {
 	if node == nil {
 		return black
 	}
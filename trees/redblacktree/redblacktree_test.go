@@ -5,12 +5,19 @@
 package redblacktree
 
 import (
+	"bytes"
+	"encoding/json"
 	"fmt"
+	"github.com/fmorenovr/gods/trees"
+	"github.com/fmorenovr/gods/trees/treetest"
+	"slices"
+	"strconv"
+	"strings"
 	"testing"
 )
 
 func TestRedBlackTreePut(t *testing.T) {
-	tree := NewWithIntComparator()
+	tree := New[int, any]()
 	tree.Put(5, "e")
 	tree.Put(6, "f")
 	tree.Put(7, "g")
@@ -23,7 +30,7 @@ func TestRedBlackTreePut(t *testing.T) {
 	if actualValue := tree.Size(); actualValue != 7 {
 		t.Errorf("Got %v expected %v", actualValue, 7)
 	}
-	if actualValue, expectedValue := fmt.Sprintf("%d%d%d%d%d%d%d", tree.Keys()...), "1234567"; actualValue != expectedValue {
+	if actualValue, expectedValue := fmt.Sprint(tree.Keys()), "[1 2 3 4 5 6 7]"; actualValue != expectedValue {
 		t.Errorf("Got %v expected %v", actualValue, expectedValue)
 	}
 	if actualValue, expectedValue := fmt.Sprintf("%s%s%s%s%s%s%s", tree.Values()...), "abcdefg"; actualValue != expectedValue {
@@ -43,15 +50,425 @@ func TestRedBlackTreePut(t *testing.T) {
 
 	for _, test := range tests1 {
 		// retrievals
-		actualValue, actualFound := tree.Get(test[0])
+		actualValue, actualFound := tree.Get(test[0].(int))
 		if actualValue != test[1] || actualFound != test[2] {
 			t.Errorf("Got %v expected %v", actualValue, test[1])
 		}
 	}
 }
 
+func TestRedBlackTreeOrderedMap(t *testing.T) {
+	treetest.Run(t, func() gotree.OrderedMap[int, string] {
+		return New[int, string]()
+	}, strconv.Itoa)
+}
+
+func TestRedBlackTreeRangeIterator(t *testing.T) {
+	treetest.RunRange(t, func() gotree.RangeMap[int, string] {
+		return New[int, string]()
+	}, strconv.Itoa)
+}
+
+func TestRedBlackTreeSequences(t *testing.T) {
+	tree := New[int, string]()
+	for _, key := range []int{5, 1, 4, 2, 3} {
+		tree.Put(key, strconv.Itoa(key))
+	}
+	if actualValue, expectedValue := slices.Collect(tree.KeysSeq()), tree.Keys(); !slices.Equal(actualValue, expectedValue) {
+		t.Errorf("Got %v expected %v", actualValue, expectedValue)
+	}
+	if actualValue, expectedValue := slices.Collect(tree.ValuesSeq()), tree.Values(); !slices.Equal(actualValue, expectedValue) {
+		t.Errorf("Got %v expected %v", actualValue, expectedValue)
+	}
+	var keys []int
+	for key := range tree.Backward() {
+		if key == 2 {
+			break
+		}
+		keys = append(keys, key)
+	}
+	if actualValue, expectedValue := keys, []int{5, 4, 3}; !slices.Equal(actualValue, expectedValue) {
+		t.Errorf("Got %v expected %v", actualValue, expectedValue)
+	}
+}
+
+func TestRedBlackTreeWalk(t *testing.T) {
+	tree := New[int, string]()
+	for _, key := range []int{4, 2, 6, 1, 3, 5, 7} {
+		tree.Put(key, strconv.Itoa(key))
+	}
+	tests := []struct {
+		order    gotree.TraversalOrder
+		expected []int
+	}{
+		{gotree.PreOrder, []int{4, 2, 1, 3, 6, 5, 7}},
+		{gotree.InOrder, []int{1, 2, 3, 4, 5, 6, 7}},
+		{gotree.PostOrder, []int{1, 3, 2, 5, 7, 6, 4}},
+		{gotree.LevelOrder, []int{4, 2, 6, 1, 3, 5, 7}},
+	}
+	for _, test := range tests {
+		var keys []int
+		tree.Walk(test.order, func(key int, _ string) bool {
+			keys = append(keys, key)
+			return true
+		})
+		if !slices.Equal(keys, test.expected) {
+			t.Errorf("Walk(%v) got %v expected %v", test.order, keys, test.expected)
+		}
+		keys = keys[:0]
+		for key := range tree.Traverse(test.order) {
+			if len(keys) == 3 {
+				break
+			}
+			keys = append(keys, key)
+		}
+		if !slices.Equal(keys, test.expected[:3]) {
+			t.Errorf("Traverse(%v) got %v expected %v", test.order, keys, test.expected[:3])
+		}
+	}
+}
+
+func TestRedBlackTreeExport(t *testing.T) {
+	tree := New[int, string]()
+	for _, key := range []int{1, 2, 3, 4} {
+		tree.Put(key, strconv.Itoa(key))
+	}
+	var dot strings.Builder
+	if err := tree.WriteDOT(&dot, gotree.ExportOptions[int]{Highlight: []int{2}}); err != nil {
+		t.Errorf("Got %v expected %v", err, nil)
+	}
+	for _, expectedValue := range []string{
+		`n0 [label="2", style=filled, fillcolor="black", fontcolor=white, color="#ff8c00", penwidth=3];`,
+		`n4 [label="4", style=filled, fillcolor="red", fontcolor=white];`,
+		"n2 -> n3 [style=invis];",
+	} {
+		if actualValue := dot.String(); !strings.Contains(actualValue, expectedValue) {
+			t.Errorf("Got %v expected it to contain %v", actualValue, expectedValue)
+		}
+	}
+	var mermaid strings.Builder
+	if err := tree.WriteMermaid(&mermaid, gotree.ExportOptions[int]{}); err != nil {
+		t.Errorf("Got %v expected %v", err, nil)
+	}
+	for _, expectedValue := range []string{"style n4 fill:red,color:#fff", "style n1 fill:black,color:#fff", "n2 --> n4"} {
+		if actualValue := mermaid.String(); !strings.Contains(actualValue, expectedValue) {
+			t.Errorf("Got %v expected it to contain %v", actualValue, expectedValue)
+		}
+	}
+}
+
+func TestRedBlackTreeTracer(t *testing.T) {
+	tree := New[int, string]()
+	var trace strings.Builder
+	tree.SetTracer(gotree.NewJSONTracer(&trace))
+	for _, key := range []int{1, 2, 3} {
+		tree.Put(key, strconv.Itoa(key))
+	}
+	tree.Remove(1)
+	lines := strings.Split(strings.TrimSpace(trace.String()), "\n")
+	if actualValue, expectedValue := lines[0], `{"tree":"redblacktree","kind":"operation","name":"Put","keys":[1],"value":"1"}`; actualValue != expectedValue {
+		t.Errorf("Got %v expected %v", actualValue, expectedValue)
+	}
+	events, err := gotree.ReadTrace(strings.NewReader(trace.String()))
+	if err != nil {
+		t.Errorf("Got %v expected %v", err, nil)
+	}
+	var names []string
+	for _, event := range events {
+		names = append(names, event.Name)
+	}
+	expectedNames := []string{
+		"Put", "insertCase1",
+		"Put", "insertCase1", "insertCase2",
+		"Put", "insertCase1", "insertCase2", "insertCase3", "insertCase4", "insertCase5", "rotateLeft",
+		"Remove",
+	}
+	if !slices.Equal(names, expectedNames) {
+		t.Errorf("Got %v expected %v", names, expectedNames)
+	}
+	replayed := New[int, string]()
+	if err := gotree.Replay[int, string](events, replayed); err != nil {
+		t.Errorf("Got %v expected %v", err, nil)
+	}
+	if actualValue, expectedValue := replayed.String(), tree.String(); actualValue != expectedValue {
+		t.Errorf("Got %v expected %v", actualValue, expectedValue)
+	}
+}
+
+type duration int64
+
+func TestRedBlackTreeMarshalJSON(t *testing.T) {
+	tree := New[duration, bool]()
+	tree.Put(30, true)
+	tree.Put(-5, false)
+	data, err := json.Marshal(map[string]any{"timeouts": tree})
+	if actualValue, expectedValue := string(data), `{"timeouts":[{"key":-5,"value":false},{"key":30,"value":true}]}`; err != nil || actualValue != expectedValue {
+		t.Errorf("Got %v, %v expected %v", actualValue, err, expectedValue)
+	}
+	var decoded struct {
+		Timeouts Tree[duration, bool] `json:"timeouts"`
+	}
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		t.Errorf("Got %v expected %v", err, nil)
+	}
+	if actualValue, expectedValue := decoded.Timeouts.Keys(), []duration{-5, 30}; !slices.Equal(actualValue, expectedValue) {
+		t.Errorf("Got %v expected %v", actualValue, expectedValue)
+	}
+	if err := decoded.Timeouts.Validate(); err != nil {
+		t.Errorf("Got %v expected %v", err, nil)
+	}
+	if err := json.Unmarshal([]byte(`[{"value":true}]`), tree); err == nil || !strings.Contains(err.Error(), "no key") {
+		t.Errorf("Got %v expected an error for the missing key", err)
+	}
+}
+
+func TestRedBlackTreeMarshalBinary(t *testing.T) {
+	for _, size := range []int{0, 1, 2, 3, 7, 8, 100} {
+		tree := New[duration, string]()
+		for i := 0; i < size; i++ {
+			tree.Put(duration(i*10), strconv.Itoa(i))
+		}
+		data, err := tree.MarshalBinary()
+		if err != nil {
+			t.Errorf("Got %v expected %v", err, nil)
+		}
+		var decoded Tree[duration, string]
+		if err := decoded.UnmarshalBinary(data); err != nil {
+			t.Errorf("Got %v expected %v", err, nil)
+		}
+		if actualValue, expectedValue := decoded.Keys(), tree.Keys(); !slices.Equal(actualValue, expectedValue) {
+			t.Errorf("Got %v expected %v", actualValue, expectedValue)
+		}
+		if err := decoded.Validate(); err != nil {
+			t.Errorf("size %v: got %v expected %v", size, err, nil)
+		}
+		decoded.Put(-1, "-1")
+		decoded.Remove(0)
+		if err := decoded.Validate(); err != nil {
+			t.Errorf("size %v: got %v expected %v", size, err, nil)
+		}
+	}
+	tree := New[duration, string]()
+	tree.Put(1, "1")
+	data, _ := tree.MarshalBinary()
+	data[0] = 'X'
+	if err := tree.UnmarshalBinary(data); err == nil || tree.Size() != 1 {
+		t.Errorf("Got %v expected an error and the tree untouched", err)
+	}
+	var zero Tree[duration, string]
+	if err := zero.UnmarshalBinary(data); err == nil || zero.Comparator != nil {
+		t.Errorf("Got %v expected an error and the tree untouched", err)
+	}
+	if err := zero.UnmarshalJSON([]byte(`{"key":1}`)); err == nil || zero.Comparator != nil {
+		t.Errorf("Got %v expected an error and the tree untouched", err)
+	}
+}
+
+func TestRedBlackTreeMarshalShape(t *testing.T) {
+	tree := New[int, string]()
+	for _, key := range []int{1, 2, 3, 4} {
+		tree.Put(key, strconv.Itoa(key))
+	}
+	data, err := tree.MarshalShape()
+	expected := `[{"key":2,"value":"2","color":"black"},{"key":1,"value":"1","color":"black"},{"key":3,"value":"3","color":"black"},null,null,null,{"key":4,"value":"4","color":"red"}]`
+	if actualValue := string(data); err != nil || actualValue != expected {
+		t.Errorf("Got %v, %v expected %v", actualValue, err, expected)
+	}
+	var decoded Tree[int, string]
+	if err := decoded.UnmarshalShape(data); err != nil {
+		t.Errorf("Got %v expected %v", err, nil)
+	}
+	if actualValue, expectedValue := decoded.String(), tree.String(); actualValue != expectedValue {
+		t.Errorf("Got %v expected %v", actualValue, expectedValue)
+	}
+	if actualValue, expectedValue := decoded.Root.Right.Right.color, red; actualValue != expectedValue {
+		t.Errorf("Got %v expected %v", actualValue, expectedValue)
+	}
+	if node, _ := decoded.Select(3); node.Key != 4 {
+		t.Errorf("Got %v expected %v", node.Key, 4)
+	}
+	for _, data := range []string{
+		`[{"key":2,"color":"black"},{"key":1,"color":"red"},{"key":3,"color":"black"}]`,
+		`[{"key":2,"color":"red"}]`,
+		`[{"key":2}]`,
+	} {
+		if err := decoded.UnmarshalShape([]byte(data)); err == nil || decoded.Size() != 4 {
+			t.Errorf("Got %v expected an error for %v and the tree untouched", err, data)
+		}
+	}
+}
+
+func TestRedBlackTreeEncodeDecode(t *testing.T) {
+	tree := New[string, int]()
+	for i := 0; i < 50; i++ {
+		tree.Put(fmt.Sprintf("%03d", i), i)
+	}
+	var buffer bytes.Buffer
+	if err := tree.Encode(&buffer); err != nil {
+		t.Errorf("Got %v expected %v", err, nil)
+	}
+	var decoded Tree[string, int]
+	if err := decoded.Decode(&buffer); err != nil {
+		t.Errorf("Got %v expected %v", err, nil)
+	}
+	if actualValue, expectedValue := decoded.Keys(), tree.Keys(); !slices.Equal(actualValue, expectedValue) {
+		t.Errorf("Got %v expected %v", actualValue, expectedValue)
+	}
+	if err := decoded.Validate(); err != nil {
+		t.Errorf("Got %v expected %v", err, nil)
+	}
+	if err := decoded.Decode(strings.NewReader(`[{"key":"b","value":1},{"key":"c","value":2},{"key":"a","value":3}]`)); err != nil {
+		t.Errorf("Got %v expected %v", err, nil)
+	}
+	if actualValue, expectedValue := decoded.Keys(), []string{"a", "b", "c"}; !slices.Equal(actualValue, expectedValue) {
+		t.Errorf("Got %v expected %v", actualValue, expectedValue)
+	}
+	if err := decoded.Validate(); err != nil {
+		t.Errorf("Got %v expected %v", err, nil)
+	}
+}
+
+func TestRedBlackTreeValidate(t *testing.T) {
+	tree := New[int, string]()
+	if err := tree.Validate(); err != nil {
+		t.Errorf("Got %v expected %v", err, nil)
+	}
+	for _, key := range []int{4, 2, 6, 1, 3, 5, 7} {
+		tree.Put(key, strconv.Itoa(key))
+	}
+	if err := tree.Validate(); err != nil {
+		t.Errorf("Got %v expected %v", err, nil)
+	}
+	tree.Root.color = red
+	if err := tree.Validate(); err == nil || !strings.Contains(err.Error(), "root 4") {
+		t.Errorf("Got %v expected an error naming the root", err)
+	}
+	tree.Root.color = black
+	tree.Root.Left.Left.color = black
+	if err := tree.Validate(); err == nil || !strings.Contains(err.Error(), "node 2") {
+		t.Errorf("Got %v expected an error naming node 2", err)
+	}
+	tree.Root.Left.Left.color = red
+	tree.Root.Left.color = red
+	if err := tree.Validate(); err == nil || !strings.Contains(err.Error(), "red node 2") {
+		t.Errorf("Got %v expected an error naming node 2", err)
+	}
+	tree.Root.Left.color = black
+	tree.Root.Right.size = 1
+	if err := tree.Validate(); err == nil || !strings.Contains(err.Error(), "node 6") {
+		t.Errorf("Got %v expected an error naming node 6", err)
+	}
+}
+
+func TestRedBlackTreeFloorCeilingLowerHigher(t *testing.T) {
+	tree := New[int, string]()
+	if node, found := tree.Floor(1); node != nil || found {
+		t.Errorf("Got %v expected %v", node, nil)
+	}
+	if node, found := tree.Higher(1); node != nil || found {
+		t.Errorf("Got %v expected %v", node, nil)
+	}
+	for key := 2; key <= 20; key += 2 {
+		tree.Put(key, strconv.Itoa(key))
+	}
+	key := func(node *Node[int, string], found bool) int {
+		if !found {
+			return -1
+		}
+		return node.Key
+	}
+	// key, floor, ceiling, lower, higher (-1 is not found)
+	tests := [][]int{
+		{1, -1, 2, -1, 2},
+		{2, 2, 2, -1, 4},
+		{3, 2, 4, 2, 4},
+		{10, 10, 10, 8, 12},
+		{11, 10, 12, 10, 12},
+		{20, 20, 20, 18, -1},
+		{21, 20, -1, 20, -1},
+	}
+	for _, test := range tests {
+		if actual := key(tree.Floor(test[0])); actual != test[1] {
+			t.Errorf("Floor(%v) got %v expected %v", test[0], actual, test[1])
+		}
+		if actual := key(tree.Ceiling(test[0])); actual != test[2] {
+			t.Errorf("Ceiling(%v) got %v expected %v", test[0], actual, test[2])
+		}
+		if actual := key(tree.Lower(test[0])); actual != test[3] {
+			t.Errorf("Lower(%v) got %v expected %v", test[0], actual, test[3])
+		}
+		if actual := key(tree.Higher(test[0])); actual != test[4] {
+			t.Errorf("Higher(%v) got %v expected %v", test[0], actual, test[4])
+		}
+	}
+}
+
+func TestRedBlackTreeSelectRankCountRange(t *testing.T) {
+	tree := New[int, string]()
+	if node, found := tree.Select(0); node != nil || found {
+		t.Errorf("Got %v expected %v", node, nil)
+	}
+	if actualValue := tree.Rank(1); actualValue != 0 {
+		t.Errorf("Got %v expected %v", actualValue, 0)
+	}
+	// even keys 0..398 inserted in a scrambled order, then every third removed
+	var keys []int
+	for i := 0; i < 200; i++ {
+		tree.Put((i*67%200)*2, strconv.Itoa(i))
+	}
+	for key := 0; key < 400; key += 2 {
+		if key%3 == 0 {
+			tree.Remove(key)
+		} else {
+			keys = append(keys, key)
+		}
+	}
+	var checkSize func(node *Node[int, string]) int
+	checkSize = func(node *Node[int, string]) int {
+		if node == nil {
+			return 0
+		}
+		size := 1 + checkSize(node.Left) + checkSize(node.Right)
+		if node.size != size {
+			t.Errorf("node %v has size %v expected %v", node.Key, node.size, size)
+		}
+		return size
+	}
+	checkSize(tree.Root)
+	for i, key := range keys {
+		if node, found := tree.Select(i); !found || node.Key != key {
+			t.Errorf("Select(%v) got %v expected %v", i, node, key)
+		}
+		if actualValue := tree.Rank(key); actualValue != i {
+			t.Errorf("Rank(%v) got %v expected %v", key, actualValue, i)
+		}
+		if actualValue := tree.Rank(key + 1); actualValue != i+1 {
+			t.Errorf("Rank(%v) got %v expected %v", key+1, actualValue, i+1)
+		}
+	}
+	for _, k := range []int{-1, len(keys)} {
+		if node, found := tree.Select(k); node != nil || found {
+			t.Errorf("Select(%v) got %v expected %v", k, node, nil)
+		}
+	}
+	tests := [][]int{
+		{-10, 500, len(keys)},
+		{2, 4, 2},
+		{3, 5, 1},
+		{6, 6, 0},
+		{8, 8, 1},
+		{10, 2, 0},
+		{398, 1000, 1},
+	}
+	for _, test := range tests {
+		if actualValue := tree.CountRange(test[0], test[1]); actualValue != test[2] {
+			t.Errorf("CountRange(%v, %v) got %v expected %v", test[0], test[1], actualValue, test[2])
+		}
+	}
+}
+
 func TestRedBlackTreeRemove(t *testing.T) {
-	tree := NewWithIntComparator()
+	tree := New[int, any]()
 	tree.Put(5, "e")
 	tree.Put(6, "f")
 	tree.Put(7, "g")
@@ -67,7 +484,7 @@ func TestRedBlackTreeRemove(t *testing.T) {
 	tree.Remove(8)
 	tree.Remove(5)
 
-	if actualValue, expectedValue := fmt.Sprintf("%d%d%d%d", tree.Keys()...), "1234"; actualValue != expectedValue {
+	if actualValue, expectedValue := fmt.Sprint(tree.Keys()), "[1 2 3 4]"; actualValue != expectedValue {
 		t.Errorf("Got %v expected %v", actualValue, expectedValue)
 	}
 	if actualValue, expectedValue := fmt.Sprintf("%s%s%s%s", tree.Values()...), "abcd"; actualValue != expectedValue {
@@ -92,7 +509,7 @@ func TestRedBlackTreeRemove(t *testing.T) {
 	}
 
 	for _, test := range tests2 {
-		actualValue, actualFound := tree.Get(test[0])
+		actualValue, actualFound := tree.Get(test[0].(int))
 		if actualValue != test[1] || actualFound != test[2] {
 			t.Errorf("Got %v expected %v", actualValue, test[1])
 		}
@@ -105,7 +522,7 @@ func TestRedBlackTreeRemove(t *testing.T) {
 	tree.Remove(2)
 	tree.Remove(2)
 
-	if actualValue, expectedValue := fmt.Sprintf("%s", tree.Keys()), "[]"; actualValue != expectedValue {
+	if actualValue, expectedValue := fmt.Sprint(tree.Keys()), "[]"; actualValue != expectedValue {
 		t.Errorf("Got %v expected %v", actualValue, expectedValue)
 	}
 	if actualValue, expectedValue := fmt.Sprintf("%s", tree.Values()), "[]"; actualValue != expectedValue {
@@ -118,7 +535,7 @@ func TestRedBlackTreeRemove(t *testing.T) {
 }
 
 func TestRedBlackTreeLeftAndRight(t *testing.T) {
-	tree := NewWithIntComparator()
+	tree := New[int, any]()
 
 	if actualValue := tree.Left(); actualValue != nil {
 		t.Errorf("Got %v expected %v", actualValue, nil)
@@ -152,7 +569,7 @@ func TestRedBlackTreeLeftAndRight(t *testing.T) {
 }
 
 func TestRedBlackTreeCeilingAndFloor(t *testing.T) {
-	tree := NewWithIntComparator()
+	tree := New[int, any]()
 
 	if node, found := tree.Floor(0); node != nil || found {
 		t.Errorf("Got %v expected %v", node, "<nil>")
@@ -185,7 +602,7 @@ func TestRedBlackTreeCeilingAndFloor(t *testing.T) {
 }
 
 func TestRedBlackTreeIteratorNextOnEmpty(t *testing.T) {
-	tree := NewWithIntComparator()
+	tree := New[int, any]()
 	it := tree.Iterator()
 	for it.Next() {
 		t.Errorf("Shouldn't iterate on empty tree")
@@ -193,7 +610,7 @@ func TestRedBlackTreeIteratorNextOnEmpty(t *testing.T) {
 }
 
 func TestRedBlackTreeIteratorPrevOnEmpty(t *testing.T) {
-	tree := NewWithIntComparator()
+	tree := New[int, any]()
 	it := tree.Iterator()
 	for it.Prev() {
 		t.Errorf("Shouldn't iterate on empty tree")
@@ -201,7 +618,7 @@ func TestRedBlackTreeIteratorPrevOnEmpty(t *testing.T) {
 }
 
 func TestRedBlackTreeIterator1Next(t *testing.T) {
-	tree := NewWithIntComparator()
+	tree := New[int, any]()
 	tree.Put(5, "e")
 	tree.Put(6, "f")
 	tree.Put(7, "g")
@@ -239,7 +656,7 @@ func TestRedBlackTreeIterator1Next(t *testing.T) {
 }
 
 func TestRedBlackTreeIterator1Prev(t *testing.T) {
-	tree := NewWithIntComparator()
+	tree := New[int, any]()
 	tree.Put(5, "e")
 	tree.Put(6, "f")
 	tree.Put(7, "g")
@@ -279,7 +696,7 @@ func TestRedBlackTreeIterator1Prev(t *testing.T) {
 }
 
 func TestRedBlackTreeIterator2Next(t *testing.T) {
-	tree := NewWithIntComparator()
+	tree := New[int, any]()
 	tree.Put(3, "c")
 	tree.Put(1, "a")
 	tree.Put(2, "b")
@@ -305,7 +722,7 @@ func TestRedBlackTreeIterator2Next(t *testing.T) {
 }
 
 func TestRedBlackTreeIterator2Prev(t *testing.T) {
-	tree := NewWithIntComparator()
+	tree := New[int, any]()
 	tree.Put(3, "c")
 	tree.Put(1, "a")
 	tree.Put(2, "b")
@@ -333,7 +750,7 @@ func TestRedBlackTreeIterator2Prev(t *testing.T) {
 }
 
 func TestRedBlackTreeIterator3Next(t *testing.T) {
-	tree := NewWithIntComparator()
+	tree := New[int, any]()
 	tree.Put(1, "a")
 	it := tree.Iterator()
 	count := 0
@@ -357,7 +774,7 @@ func TestRedBlackTreeIterator3Next(t *testing.T) {
 }
 
 func TestRedBlackTreeIterator3Prev(t *testing.T) {
-	tree := NewWithIntComparator()
+	tree := New[int, any]()
 	tree.Put(1, "a")
 	it := tree.Iterator()
 	for it.Next() {
@@ -383,7 +800,7 @@ func TestRedBlackTreeIterator3Prev(t *testing.T) {
 }
 
 func TestRedBlackTreeIterator4Next(t *testing.T) {
-	tree := NewWithIntComparator()
+	tree := New[int, any]()
 	tree.Put(13, 5)
 	tree.Put(8, 3)
 	tree.Put(17, 7)
@@ -426,7 +843,7 @@ func TestRedBlackTreeIterator4Next(t *testing.T) {
 }
 
 func TestRedBlackTreeIterator4Prev(t *testing.T) {
-	tree := NewWithIntComparator()
+	tree := New[int, any]()
 	tree.Put(13, 5)
 	tree.Put(8, 3)
 	tree.Put(17, 7)
@@ -471,11 +888,11 @@ func TestRedBlackTreeIterator4Prev(t *testing.T) {
 }
 
 func TestRedBlackTreeIteratorBegin(t *testing.T) {
-	tree := NewWithIntComparator()
+	tree := New[int, any]()
 	tree.Put(3, "c")
 	tree.Put(1, "a")
 	tree.Put(2, "b")
-	it := tree.Iterator()
+	it := tree.Iterator().(*Iterator[int, any])
 
 	if it.node != nil {
 		t.Errorf("Got %v expected %v", it.node, nil)
@@ -503,8 +920,8 @@ func TestRedBlackTreeIteratorBegin(t *testing.T) {
 }
 
 func TestRedBlackTreeIteratorEnd(t *testing.T) {
-	tree := NewWithIntComparator()
-	it := tree.Iterator()
+	tree := New[int, any]()
+	it := tree.Iterator().(*Iterator[int, any])
 
 	if it.node != nil {
 		t.Errorf("Got %v expected %v", it.node, nil)
@@ -530,7 +947,7 @@ func TestRedBlackTreeIteratorEnd(t *testing.T) {
 }
 
 func TestRedBlackTreeIteratorFirst(t *testing.T) {
-	tree := NewWithIntComparator()
+	tree := New[int, any]()
 	tree.Put(3, "c")
 	tree.Put(1, "a")
 	tree.Put(2, "b")
@@ -544,7 +961,7 @@ func TestRedBlackTreeIteratorFirst(t *testing.T) {
 }
 
 func TestRedBlackTreeIteratorLast(t *testing.T) {
-	tree := NewWithIntComparator()
+	tree := New[int, any]()
 	tree.Put(3, "c")
 	tree.Put(1, "a")
 	tree.Put(2, "b")
@@ -558,7 +975,7 @@ func TestRedBlackTreeIteratorLast(t *testing.T) {
 }
 
 func TestRedBlackTreeSerialization(t *testing.T) {
-	tree := NewWithStringComparator()
+	tree := New[string, string]()
 	tree.Put("c", "3")
 	tree.Put("b", "2")
 	tree.Put("a", "1")
@@ -568,10 +985,10 @@ func TestRedBlackTreeSerialization(t *testing.T) {
 		if actualValue, expectedValue := tree.Size(), 3; actualValue != expectedValue {
 			t.Errorf("Got %v expected %v", actualValue, expectedValue)
 		}
-		if actualValue := tree.Keys(); actualValue[0].(string) != "a" || actualValue[1].(string) != "b" || actualValue[2].(string) != "c" {
+		if actualValue := tree.Keys(); actualValue[0] != "a" || actualValue[1] != "b" || actualValue[2] != "c" {
 			t.Errorf("Got %v expected %v", actualValue, "[a,b,c]")
 		}
-		if actualValue := tree.Values(); actualValue[0].(string) != "1" || actualValue[1].(string) != "2" || actualValue[2].(string) != "3" {
+		if actualValue := tree.Values(); actualValue[0] != "1" || actualValue[1] != "2" || actualValue[2] != "3" {
 			t.Errorf("Got %v expected %v", actualValue, "[1,2,3]")
 		}
 		if err != nil {
@@ -588,7 +1005,7 @@ func TestRedBlackTreeSerialization(t *testing.T) {
 	assert()
 }
 
-func benchmarkGet(b *testing.B, tree *Tree, size int) {
+func benchmarkGet(b *testing.B, tree *Tree[int, any], size int) {
 	for i := 0; i < b.N; i++ {
 		for n := 0; n < size; n++ {
 			tree.Get(n)
@@ -596,7 +1013,7 @@ func benchmarkGet(b *testing.B, tree *Tree, size int) {
 	}
 }
 
-func benchmarkPut(b *testing.B, tree *Tree, size int) {
+func benchmarkPut(b *testing.B, tree *Tree[int, any], size int) {
 	for i := 0; i < b.N; i++ {
 		for n := 0; n < size; n++ {
 			tree.Put(n, struct{}{})
@@ -604,7 +1021,7 @@ func benchmarkPut(b *testing.B, tree *Tree, size int) {
 	}
 }
 
-func benchmarkRemove(b *testing.B, tree *Tree, size int) {
+func benchmarkRemove(b *testing.B, tree *Tree[int, any], size int) {
 	for i := 0; i < b.N; i++ {
 		for n := 0; n < size; n++ {
 			tree.Remove(n)
@@ -615,7 +1032,7 @@ func benchmarkRemove(b *testing.B, tree *Tree, size int) {
 func BenchmarkRedBlackTreeGet100(b *testing.B) {
 	b.StopTimer()
 	size := 100
-	tree := NewWithIntComparator()
+	tree := New[int, any]()
 	for n := 0; n < size; n++ {
 		tree.Put(n, struct{}{})
 	}
@@ -626,7 +1043,7 @@ func BenchmarkRedBlackTreeGet100(b *testing.B) {
 func BenchmarkRedBlackTreeGet1000(b *testing.B) {
 	b.StopTimer()
 	size := 1000
-	tree := NewWithIntComparator()
+	tree := New[int, any]()
 	for n := 0; n < size; n++ {
 		tree.Put(n, struct{}{})
 	}
@@ -637,7 +1054,7 @@ func BenchmarkRedBlackTreeGet1000(b *testing.B) {
 func BenchmarkRedBlackTreeGet10000(b *testing.B) {
 	b.StopTimer()
 	size := 10000
-	tree := NewWithIntComparator()
+	tree := New[int, any]()
 	for n := 0; n < size; n++ {
 		tree.Put(n, struct{}{})
 	}
@@ -648,7 +1065,7 @@ func BenchmarkRedBlackTreeGet10000(b *testing.B) {
 func BenchmarkRedBlackTreeGet100000(b *testing.B) {
 	b.StopTimer()
 	size := 100000
-	tree := NewWithIntComparator()
+	tree := New[int, any]()
 	for n := 0; n < size; n++ {
 		tree.Put(n, struct{}{})
 	}
@@ -659,7 +1076,7 @@ func BenchmarkRedBlackTreeGet100000(b *testing.B) {
 func BenchmarkRedBlackTreePut100(b *testing.B) {
 	b.StopTimer()
 	size := 100
-	tree := NewWithIntComparator()
+	tree := New[int, any]()
 	b.StartTimer()
 	benchmarkPut(b, tree, size)
 }
@@ -667,7 +1084,7 @@ func BenchmarkRedBlackTreePut100(b *testing.B) {
 func BenchmarkRedBlackTreePut1000(b *testing.B) {
 	b.StopTimer()
 	size := 1000
-	tree := NewWithIntComparator()
+	tree := New[int, any]()
 	for n := 0; n < size; n++ {
 		tree.Put(n, struct{}{})
 	}
@@ -678,7 +1095,7 @@ func BenchmarkRedBlackTreePut1000(b *testing.B) {
 func BenchmarkRedBlackTreePut10000(b *testing.B) {
 	b.StopTimer()
 	size := 10000
-	tree := NewWithIntComparator()
+	tree := New[int, any]()
 	for n := 0; n < size; n++ {
 		tree.Put(n, struct{}{})
 	}
@@ -689,7 +1106,7 @@ func BenchmarkRedBlackTreePut10000(b *testing.B) {
 func BenchmarkRedBlackTreePut100000(b *testing.B) {
 	b.StopTimer()
 	size := 100000
-	tree := NewWithIntComparator()
+	tree := New[int, any]()
 	for n := 0; n < size; n++ {
 		tree.Put(n, struct{}{})
 	}
@@ -700,7 +1117,7 @@ func BenchmarkRedBlackTreePut100000(b *testing.B) {
 func BenchmarkRedBlackTreeRemove100(b *testing.B) {
 	b.StopTimer()
 	size := 100
-	tree := NewWithIntComparator()
+	tree := New[int, any]()
 	for n := 0; n < size; n++ {
 		tree.Put(n, struct{}{})
 	}
@@ -711,7 +1128,7 @@ func BenchmarkRedBlackTreeRemove100(b *testing.B) {
 func BenchmarkRedBlackTreeRemove1000(b *testing.B) {
 	b.StopTimer()
 	size := 1000
-	tree := NewWithIntComparator()
+	tree := New[int, any]()
 	for n := 0; n < size; n++ {
 		tree.Put(n, struct{}{})
 	}
@@ -722,7 +1139,7 @@ func BenchmarkRedBlackTreeRemove1000(b *testing.B) {
 func BenchmarkRedBlackTreeRemove10000(b *testing.B) {
 	b.StopTimer()
 	size := 10000
-	tree := NewWithIntComparator()
+	tree := New[int, any]()
 	for n := 0; n < size; n++ {
 		tree.Put(n, struct{}{})
 	}
@@ -733,7 +1150,7 @@ func BenchmarkRedBlackTreeRemove10000(b *testing.B) {
 func BenchmarkRedBlackTreeRemove100000(b *testing.B) {
 	b.StopTimer()
 	size := 100000
-	tree := NewWithIntComparator()
+	tree := New[int, any]()
 	for n := 0; n < size; n++ {
 		tree.Put(n, struct{}{})
 	}
@@ -5,29 +5,37 @@
 package redblacktree
 
 import (
+	"encoding"
 	"encoding/json"
-	"github.com/emirpasic/gods/containers"
-	"github.com/emirpasic/gods/utils"
+	"fmt"
+	"github.com/fmorenovr/gods/trees"
+	"github.com/fmorenovr/gods/utils"
+	"io"
+	"math/bits"
 )
 
 func assertSerializationImplementation() {
-	var _ containers.JSONSerializer = (*Tree)(nil)
-	var _ containers.JSONDeserializer = (*Tree)(nil)
+	var _ utils.JSONSerializer = (*Tree[string, int])(nil)
+	var _ utils.JSONDeserializer = (*Tree[string, int])(nil)
+	var _ json.Marshaler = (*Tree[string, int])(nil)
+	var _ json.Unmarshaler = (*Tree[string, int])(nil)
+	var _ encoding.BinaryMarshaler = (*Tree[string, int])(nil)
+	var _ encoding.BinaryUnmarshaler = (*Tree[string, int])(nil)
 }
 
 // ToJSON outputs the JSON representation of list's elements.
-func (tree *Tree) ToJSON() ([]byte, error) {
-	elements := make(map[string]interface{})
+func (tree *Tree[K, V]) ToJSON() ([]byte, error) {
+	elements := make(map[K]V)
 	it := tree.Iterator()
 	for it.Next() {
-		elements[utils.ToString(it.Key())] = it.Value()
+		elements[it.Key()] = it.Value()
 	}
 	return json.Marshal(&elements)
 }
 
 // FromJSON populates list's elements from the input JSON representation.
-func (tree *Tree) FromJSON(data []byte) error {
-	elements := make(map[string]interface{})
+func (tree *Tree[K, V]) FromJSON(data []byte) error {
+	elements := make(map[K]V)
 	err := json.Unmarshal(data, &elements)
 	if err == nil {
 		tree.Clear()
@@ -35,5 +43,200 @@ func (tree *Tree) FromJSON(data []byte) error {
 			tree.Put(key, value)
 		}
 	}
-	return err
+	return gotree.CorruptJSON(err)
+}
+
+// SetCodecs sets how the JSON and binary forms encode keys and values, nil keeps the defaults
+// (encoding/json and utils.BinaryCodec). In JSON the codecs must write JSON values.
+func (tree *Tree[K, V]) SetCodecs(keys utils.Codec[K], values utils.Codec[V]) {
+	tree.keyCodec, tree.valueCodec = keys, values
+}
+
+// MarshalJSON writes the tree as [{"key":..,"value":..}, ...] in key order.
+// Unlike ToJSON, keys keep their JSON type and need not be strings or numbers.
+func (tree *Tree[K, V]) MarshalJSON() ([]byte, error) {
+	return gotree.MarshalEntries(tree.All(), tree.keyCodec, tree.valueCodec)
+}
+
+// UnmarshalJSON replaces the contents of the tree with the entries written by MarshalJSON.
+// A zero-value tree, as json.Unmarshal allocates it, orders keys with utils.DefaultComparator.
+func (tree *Tree[K, V]) UnmarshalJSON(data []byte) error {
+	comparator := tree.Comparator
+	if comparator == nil {
+		if comparator = utils.DefaultComparator[K](); comparator == nil {
+			return fmt.Errorf("redblacktree: no natural order for keys of type %T, build the tree with NewWith", *new(K))
+		}
+	}
+	keys, values, err := gotree.UnmarshalEntries(data, tree.keyCodec, tree.valueCodec)
+	if err != nil {
+		return err
+	}
+	tree.Comparator = comparator
+	tree.Clear()
+	for i, key := range keys {
+		tree.Put(key, values[i])
+	}
+	return nil
+}
+
+// MarshalBinary writes the tree in the binary form of gotree.MarshalBinaryEntries, in key order.
+func (tree *Tree[K, V]) MarshalBinary() ([]byte, error) {
+	return gotree.MarshalBinaryEntries(gotree.BinaryHeader{Kind: "redblacktree", Count: tree.size}, tree.All(), tree.keyCodec, tree.valueCodec)
+}
+
+// UnmarshalBinary replaces the contents of the tree with the entries written by MarshalBinary,
+// building a balanced tree from the sorted entries in O(n). Corrupt data is an error and leaves
+// the tree untouched. A zero-value tree orders keys with utils.DefaultComparator.
+func (tree *Tree[K, V]) UnmarshalBinary(data []byte) error {
+	comparator := tree.Comparator
+	if comparator == nil {
+		if comparator = utils.DefaultComparator[K](); comparator == nil {
+			return fmt.Errorf("redblacktree: no natural order for keys of type %T, build the tree with NewWith", *new(K))
+		}
+	}
+	_, keys, values, err := gotree.UnmarshalBinaryEntries(data, "redblacktree", comparator, tree.keyCodec, tree.valueCodec)
+	if err != nil {
+		return err
+	}
+	tree.Comparator = comparator
+	nodes := make([]*Node[K, V], len(keys))
+	for i, key := range keys {
+		nodes[i] = &Node[K, V]{Key: key, Value: values[i]}
+	}
+	tree.Root = buildTree(nodes)
+	tree.size = len(nodes)
+	return nil
+}
+
+// build links the sorted nodes into a tree, the middle node at the root, and returns it. Every
+// level above the deepest one is full, so coloring the deepest level red and the others black
+// gives every path the same number of black nodes.
+func build[K comparable, V any](nodes []*Node[K, V], parent *Node[K, V], depth, deepest int) *Node[K, V] {
+	if len(nodes) == 0 {
+		return nil
+	}
+	middle := len(nodes) / 2
+	node := nodes[middle]
+	node.Parent, node.color, node.size = parent, black, len(nodes)
+	if depth == deepest && depth > 0 {
+		node.color = red
+	}
+	node.Left = build(nodes[:middle], node, depth+1, deepest)
+	node.Right = build(nodes[middle+1:], node, depth+1, deepest)
+	return node
+}
+
+// buildTree links the sorted nodes into a balanced red-black tree and returns its root.
+func buildTree[K comparable, V any](nodes []*Node[K, V]) *Node[K, V] {
+	return build(nodes, nil, 0, bits.Len(uint(len(nodes)))-1)
+}
+
+// Encode streams the tree to w in the JSON form of MarshalJSON, one entry at a time.
+func (tree *Tree[K, V]) Encode(w io.Writer) error {
+	return gotree.EncodeEntries(w, tree.All(), tree.keyCodec, tree.valueCodec)
+}
+
+// Decode replaces the contents of the tree with the JSON form of MarshalJSON read from r one entry
+// at a time. Entries in key order, as Encode writes them, become nodes linked and colored at the
+// end without insertion fixups; from the first entry out of order on, the rest is put one by one.
+// An error leaves the tree untouched. A zero-value tree orders keys with utils.DefaultComparator.
+func (tree *Tree[K, V]) Decode(r io.Reader) error {
+	comparator := tree.Comparator
+	if comparator == nil {
+		if comparator = utils.DefaultComparator[K](); comparator == nil {
+			return fmt.Errorf("redblacktree: no natural order for keys of type %T, build the tree with NewWith", *new(K))
+		}
+	}
+	loaded := &Tree[K, V]{Comparator: comparator}
+	var nodes []*Node[K, V]
+	err := gotree.DecodeEntries(r, tree.keyCodec, tree.valueCodec, func(key K, value V) error {
+		if loaded.Root == nil && (len(nodes) == 0 || comparator(nodes[len(nodes)-1].Key, key) < 0) {
+			nodes = append(nodes, &Node[K, V]{Key: key, Value: value})
+			return nil
+		}
+		if loaded.Root == nil {
+			loaded.Root, loaded.size = buildTree(nodes), len(nodes)
+		}
+		loaded.Put(key, value)
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	if loaded.Root == nil {
+		loaded.Root, loaded.size = buildTree(nodes), len(nodes)
+	}
+	tree.Root, tree.size, tree.Comparator = loaded.Root, loaded.size, comparator
+	return nil
+}
+
+// MarshalShape writes the exact layout of the tree as the level-order JSON array of
+// gotree.MarshalShape, every node with its color: [{"key":2,"value":..,"color":"black"},..].
+func (tree *Tree[K, V]) MarshalShape() ([]byte, error) {
+	return gotree.MarshalShape(tree.Root, shape[K, V](), tree.keyCodec, tree.valueCodec)
+}
+
+// UnmarshalShape replaces the tree with the layout and colors written by MarshalShape. The rebuilt
+// tree must be a valid red-black tree, otherwise it is an error and the tree is left untouched.
+// A zero-value tree orders keys with utils.DefaultComparator.
+func (tree *Tree[K, V]) UnmarshalShape(data []byte) error {
+	comparator := tree.Comparator
+	if comparator == nil {
+		if comparator = utils.DefaultComparator[K](); comparator == nil {
+			return fmt.Errorf("redblacktree: no natural order for keys of type %T, build the tree with NewWith", *new(K))
+		}
+	}
+	root, size, err := gotree.UnmarshalShape(data, shape[K, V](), tree.keyCodec, tree.valueCodec)
+	if err != nil {
+		return err
+	}
+	restoreSizes(root)
+	loaded := &Tree[K, V]{Root: root, size: size, Comparator: comparator}
+	if err := loaded.Validate(); err != nil {
+		return gotree.CorruptEntry(-1, -1, err)
+	}
+	tree.Root, tree.size, tree.Comparator = loaded.Root, loaded.size, comparator
+	return nil
+}
+
+// shape returns how gotree walks and rebuilds the nodes of a tree.
+func shape[K comparable, V any]() gotree.Shape[*Node[K, V], K, V] {
+	return gotree.Shape[*Node[K, V], K, V]{
+		Children: func(node *Node[K, V]) (*Node[K, V], *Node[K, V]) {
+			return node.Left, node.Right
+		},
+		Node: func(node *Node[K, V]) gotree.ShapeNode[K, V] {
+			return gotree.ShapeNode[K, V]{Key: node.Key, Value: node.Value, Color: node.color.String()}
+		},
+		New: func(s gotree.ShapeNode[K, V]) (*Node[K, V], error) {
+			node := &Node[K, V]{Key: s.Key, Value: s.Value, size: 1}
+			switch s.Color {
+			case "black":
+				node.color = black
+			case "red":
+				node.color = red
+			default:
+				return nil, fmt.Errorf("color %q, expected red or black", s.Color)
+			}
+			return node, nil
+		},
+		Link: func(parent, left, right *Node[K, V]) {
+			parent.Left, parent.Right = left, right
+			if left != nil {
+				left.Parent = parent
+			}
+			if right != nil {
+				right.Parent = parent
+			}
+		},
+	}
+}
+
+// restoreSizes recomputes the subtree sizes under node.
+func restoreSizes[K comparable, V any](node *Node[K, V]) int {
+	if node == nil {
+		return 0
+	}
+	node.size = 1 + restoreSizes(node.Left) + restoreSizes(node.Right)
+	return node.size
 }
@@ -0,0 +1,74 @@
+// Copyright (c) 2015, Emir Pasic. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package redblacktree
+
+import "fmt"
+
+// Validate checks the red-black tree invariants and returns nil if they all hold:
+// the root is black, no red node has a red child, every path from a node down to its
+// leaves has the same number of black nodes, keys are ordered, parent pointers and
+// subtree sizes are consistent and the tree size matches its number of nodes.
+//
+// Otherwise it returns an error that names the first offending node.
+func (tree *Tree[K, V]) Validate() error {
+	if tree.Root != nil {
+		if tree.Root.Parent != nil {
+			return fmt.Errorf("redblacktree: root %v has parent %v", tree.Root, tree.Root.Parent)
+		}
+		if tree.Root.color != black {
+			return fmt.Errorf("redblacktree: root %v is red", tree.Root)
+		}
+	}
+	if _, err := tree.validate(tree.Root, nil, nil); err != nil {
+		return err
+	}
+	if size := nodeSize(tree.Root); size != tree.size {
+		return fmt.Errorf("redblacktree: tree size is %v but it holds %v nodes", tree.size, size)
+	}
+	return nil
+}
+
+// validate checks the subtree of node, whose keys must lie strictly between the keys of
+// low and high (nil means unbounded), and returns its black height.
+func (tree *Tree[K, V]) validate(node, low, high *Node[K, V]) (int, error) {
+	if node == nil {
+		return 1, nil
+	}
+	if low != nil && tree.Comparator(node.Key, low.Key) <= 0 {
+		return 0, fmt.Errorf("redblacktree: node %v is not greater than its ancestor %v", node, low)
+	}
+	if high != nil && tree.Comparator(node.Key, high.Key) >= 0 {
+		return 0, fmt.Errorf("redblacktree: node %v is not smaller than its ancestor %v", node, high)
+	}
+	for _, child := range []*Node[K, V]{node.Left, node.Right} {
+		if child == nil {
+			continue
+		}
+		if child.Parent != node {
+			return 0, fmt.Errorf("redblacktree: node %v has child %v whose parent is not %v", node, child, node)
+		}
+		if node.color == red && child.color == red {
+			return 0, fmt.Errorf("redblacktree: red node %v has red child %v", node, child)
+		}
+	}
+	left, err := tree.validate(node.Left, low, node)
+	if err != nil {
+		return 0, err
+	}
+	right, err := tree.validate(node.Right, node, high)
+	if err != nil {
+		return 0, err
+	}
+	if left != right {
+		return 0, fmt.Errorf("redblacktree: node %v has black height %v on the left and %v on the right", node, left, right)
+	}
+	if size := 1 + nodeSize(node.Left) + nodeSize(node.Right); node.size != size {
+		return 0, fmt.Errorf("redblacktree: node %v caches size %v but has size %v", node, node.size, size)
+	}
+	if node.color == black {
+		left++
+	}
+	return left, nil
+}
@@ -0,0 +1,317 @@
+package treetest
+
+import (
+  "fmt"
+  "math/rand"
+  "reflect"
+  "slices"
+  "testing"
+
+  "github.com/fmorenovr/gods/trees"
+  "github.com/fmorenovr/gods/utils"
+)
+
+// Run checks that the maps built by newMap behave like a sorted map of int keys,
+// value builds the value stored under a key. Every call of newMap must return an empty map.
+func Run[V any](t *testing.T, newMap func() gotree.OrderedMap[int, V], value func(key int) V) {
+  t.Run("Empty", func(t *testing.T) {
+    checkMap(t, newMap(), map[int]V{})
+  })
+
+  t.Run("PutAndGet", func(t *testing.T) {
+    m, model := newMap(), map[int]V{}
+    for _, key := range rand.New(rand.NewSource(1)).Perm(200) {
+      putBoth(t, m, model, key*2, value(key*2))
+    }
+    checkMap(t, m, model)
+  })
+
+  t.Run("Ascending", func(t *testing.T) {
+    m, model := newMap(), map[int]V{}
+    for key := 0; key < 100; key++ {
+      putBoth(t, m, model, key, value(key))
+    }
+    checkMap(t, m, model)
+  })
+
+  t.Run("Descending", func(t *testing.T) {
+    m, model := newMap(), map[int]V{}
+    for key := 100; key > 0; key-- {
+      putBoth(t, m, model, key, value(key))
+    }
+    checkMap(t, m, model)
+  })
+
+  t.Run("Overwrite", func(t *testing.T) {
+    m, model := newMap(), map[int]V{}
+    for key := 0; key < 50; key++ {
+      putBoth(t, m, model, key, value(key))
+    }
+    for key := 0; key < 50; key += 3 {
+      putBoth(t, m, model, key, value(key+1000))
+    }
+    checkMap(t, m, model)
+  })
+
+  t.Run("Remove", func(t *testing.T) {
+    r := rand.New(rand.NewSource(2))
+    m, model := newMap(), map[int]V{}
+    for _, key := range r.Perm(300) {
+      putBoth(t, m, model, key, value(key))
+    }
+    for i, key := range r.Perm(400) {
+      removeBoth(t, m, model, key)
+      if i%50 == 0 {
+        checkMap(t, m, model)
+      }
+    }
+    checkMap(t, m, model)
+  })
+
+  t.Run("Mixed", func(t *testing.T) {
+    r := rand.New(rand.NewSource(3))
+    m, model := newMap(), map[int]V{}
+    for i := 0; i < 2000; i++ {
+      key := r.Intn(100)
+      if r.Intn(3) == 0 {
+        removeBoth(t, m, model, key)
+      } else {
+        putBoth(t, m, model, key, value(key+i))
+      }
+    }
+    checkMap(t, m, model)
+  })
+}
+
+// RunRange checks the seek iterators of the maps built by newMap against a model,
+// over every pair of bounds and every inclusivity. Every call of newMap must return an empty map.
+func RunRange[V any](t *testing.T, newMap func() gotree.RangeMap[int, V], value func(key int) V) {
+  t.Run("RangeEmpty", func(t *testing.T) {
+    checkRanges(t, newMap(), map[int]V{})
+  })
+
+  t.Run("Range", func(t *testing.T) {
+    m, model := newMap(), map[int]V{}
+    for _, key := range rand.New(rand.NewSource(4)).Perm(30) {
+      putBoth(t, m, model, key*2, value(key*2))
+    }
+    checkRanges(t, m, model)
+  })
+}
+
+// putBoth puts key and value in m and model, then validates m
+func putBoth[V any](t *testing.T, m gotree.OrderedMap[int, V], model map[int]V, key int, value V) {
+  t.Helper()
+  m.Put(key, value)
+  model[key] = value
+  validate(t, m, "Put", key)
+}
+
+// removeBoth removes key from m and model, then validates m
+func removeBoth[V any](t *testing.T, m gotree.OrderedMap[int, V], model map[int]V, key int) {
+  t.Helper()
+  m.Remove(key)
+  delete(model, key)
+  validate(t, m, "Remove", key)
+}
+
+// validate checks the invariants of m when it has a Validate() error method
+func validate[V any](t *testing.T, m gotree.OrderedMap[int, V], operation string, key int) {
+  t.Helper()
+  if validator, ok := m.(interface{ Validate() error }); ok {
+    if err := validator.Validate(); err != nil {
+      t.Fatalf("Validate after %v(%v): %v", operation, key, err)
+    }
+  }
+}
+
+// checkMap compares every read operation of m against model
+func checkMap[V any](t *testing.T, m gotree.OrderedMap[int, V], model map[int]V) {
+  t.Helper()
+  keys := make([]int, 0, len(model))
+  for key := range model {
+    keys = append(keys, key)
+  }
+  slices.Sort(keys)
+
+  if validator, ok := m.(interface{ Validate() error }); ok {
+    if err := validator.Validate(); err != nil {
+      t.Fatalf("Validate: %v", err)
+    }
+  }
+  if actual, expected := m.Len(), len(keys); actual != expected {
+    t.Fatalf("Len: got %v expected %v", actual, expected)
+  }
+
+  low, high := -2, 2
+  if len(keys) > 0 {
+    low, high = keys[0]-2, keys[len(keys)-1]+2
+  }
+  for key := low; key <= high; key++ {
+    expected, exists := model[key]
+    if actual, found := m.Get(key); found != exists || (exists && !reflect.DeepEqual(actual, expected)) {
+      t.Fatalf("Get(%v): got %v,%v expected %v,%v", key, actual, found, expected, exists)
+    }
+    if actual := m.Contains(key); actual != exists {
+      t.Fatalf("Contains(%v): got %v expected %v", key, actual, exists)
+    }
+
+    // floor is the last key <= key, ceiling the first key >= key
+    position, _ := slices.BinarySearch(keys, key)
+    floorIndex, ceilingIndex := position-1, position
+    if exists {
+      floorIndex = position
+    }
+    checkEntry(t, "FloorEntry", key, keys, model, floorIndex)(m.FloorEntry(key))
+    checkEntry(t, "CeilingEntry", key, keys, model, ceilingIndex)(m.CeilingEntry(key))
+  }
+
+  checkEntry(t, "Min", 0, keys, model, 0)(m.Min())
+  checkEntry(t, "Max", 0, keys, model, len(keys)-1)(m.Max())
+
+  it := m.Iterator()
+  for i := 0; i < 2; i++ {
+    index := 0
+    for it.Next() {
+      if index >= len(keys) || it.Key() != keys[index] || !reflect.DeepEqual(it.Value(), model[keys[index]]) {
+        t.Fatalf("Iterator.Next: got %v at position %v of %v", it.Key(), index, keys)
+      }
+      index++
+    }
+    if index != len(keys) {
+      t.Fatalf("Iterator.Next: visited %v keys expected %v", index, len(keys))
+    }
+    for it.Prev() {
+      index--
+      if index < 0 || it.Key() != keys[index] || !reflect.DeepEqual(it.Value(), model[keys[index]]) {
+        t.Fatalf("Iterator.Prev: got %v at position %v of %v", it.Key(), index, keys)
+      }
+    }
+    if index != 0 {
+      t.Fatalf("Iterator.Prev: stopped at position %v expected 0", index)
+    }
+    it.Begin()
+  }
+
+  index := 0
+  for key, value := range m.All() {
+    if index >= len(keys) || key != keys[index] || !reflect.DeepEqual(value, model[keys[index]]) {
+      t.Fatalf("All: got %v at position %v of %v", key, index, keys)
+    }
+    index++
+  }
+  if index != len(keys) {
+    t.Fatalf("All: visited %v keys expected %v", index, len(keys))
+  }
+  for key, value := range m.Backward() {
+    index--
+    if index < 0 || key != keys[index] || !reflect.DeepEqual(value, model[keys[index]]) {
+      t.Fatalf("Backward: got %v at position %v of %v", key, index, keys)
+    }
+  }
+  if index != 0 {
+    t.Fatalf("Backward: stopped at position %v expected 0", index)
+  }
+  for key := range m.All() {
+    if key != keys[0] {
+      t.Fatalf("All: got %v expected %v", key, keys[0])
+    }
+    break
+  }
+
+  if actual, expected := it.First(), len(keys) > 0; actual != expected {
+    t.Fatalf("Iterator.First: got %v expected %v", actual, expected)
+  } else if actual && it.Key() != keys[0] {
+    t.Fatalf("Iterator.First: got %v expected %v", it.Key(), keys[0])
+  }
+  if actual, expected := it.Last(), len(keys) > 0; actual != expected {
+    t.Fatalf("Iterator.Last: got %v expected %v", actual, expected)
+  } else if actual && it.Key() != keys[len(keys)-1] {
+    t.Fatalf("Iterator.Last: got %v expected %v", it.Key(), keys[len(keys)-1])
+  }
+}
+
+// checkEntry returns a checker for a (key, value, found) result that should be keys[index],
+// an index out of keys means no entry should be found
+func checkEntry[V any](t *testing.T, name string, probe int, keys []int, model map[int]V, index int) func(int, V, bool) {
+  return func(key int, value V, found bool) {
+    t.Helper()
+    if index < 0 || index >= len(keys) {
+      if found {
+        t.Fatalf("%v(%v): got %v expected not found", name, probe, key)
+      }
+      return
+    }
+    if !found || key != keys[index] || !reflect.DeepEqual(value, model[keys[index]]) {
+      t.Fatalf("%v(%v): got %v,%v,%v expected %v,%v,true", name, probe, key, value, found, keys[index], model[keys[index]])
+    }
+  }
+}
+
+// checkRanges compares RangeIterator and IteratorAt of m against model
+func checkRanges[V any](t *testing.T, m gotree.RangeMap[int, V], model map[int]V) {
+  t.Helper()
+  keys := make([]int, 0, len(model))
+  for key := range model {
+    keys = append(keys, key)
+  }
+  slices.Sort(keys)
+
+  low, high := -2, 2
+  if len(keys) > 0 {
+    low, high = keys[0]-2, keys[len(keys)-1]+2
+  }
+  inclusivities := []gotree.Inclusivity{gotree.IncludeBoth, gotree.ExcludeLow, gotree.ExcludeHigh, gotree.ExcludeBoth}
+  for lo := low; lo <= high; lo++ {
+    for hi := lo - 1; hi <= high; hi++ {
+      for _, inclusivity := range inclusivities {
+        var expected []int
+        for _, key := range keys {
+          if (key > lo || (key == lo && inclusivity.IncludesLow())) && (key < hi || (key == hi && inclusivity.IncludesHigh())) {
+            expected = append(expected, key)
+          }
+        }
+        it := m.RangeIterator(lo, hi, inclusivity)
+        name := fmt.Sprintf("RangeIterator(%v, %v, %v)", lo, hi, inclusivity)
+        checkSequence(t, name+".Next", it.Next, it, expected, model)
+        checkSequence(t, name+".Prev", it.Prev, it, reversed(expected), model)
+        if actual, exists := it.First(), len(expected) > 0; actual != exists || (exists && it.Key() != expected[0]) {
+          t.Fatalf("%v.First: got %v expected %v", name, actual, exists)
+        }
+        if actual, exists := it.Last(), len(expected) > 0; actual != exists || (exists && it.Key() != expected[len(expected)-1]) {
+          t.Fatalf("%v.Last: got %v expected %v", name, actual, exists)
+        }
+      }
+    }
+  }
+
+  for key := low; key <= high; key++ {
+    position, _ := slices.BinarySearch(keys, key)
+    name := fmt.Sprintf("IteratorAt(%v)", key)
+    it := m.IteratorAt(key)
+    checkSequence(t, name+".Next", it.Next, it, keys[position:], model)
+    it = m.IteratorAt(key)
+    checkSequence(t, name+".Prev", it.Prev, it, reversed(keys[:position]), model)
+  }
+}
+
+// checkSequence moves it with step until it returns false, expecting exactly the keys of expected
+func checkSequence[V any](t *testing.T, name string, step func() bool, it utils.ReverseIteratorWithKey[int, V], expected []int, model map[int]V) {
+  t.Helper()
+  index := 0
+  for step() {
+    if index >= len(expected) || it.Key() != expected[index] || !reflect.DeepEqual(it.Value(), model[expected[index]]) {
+      t.Fatalf("%v: got %v at position %v of %v", name, it.Key(), index, expected)
+    }
+    index++
+  }
+  if index != len(expected) {
+    t.Fatalf("%v: visited %v keys expected %v", name, index, expected)
+  }
+}
+
+func reversed(keys []int) []int {
+  keys = slices.Clone(keys)
+  slices.Reverse(keys)
+  return keys
+}
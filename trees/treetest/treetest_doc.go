@@ -0,0 +1,4 @@
+// Package treetest is a conformance suite for gotree.OrderedMap and gotree.RangeMap implementations,
+// any sorted map (including ones outside gods) can run it from its own tests. Maps with a
+// Validate() error method are validated after every Put and Remove.
+package treetest
@@ -0,0 +1,195 @@
+package treeviz
+
+import (
+  "encoding/json"
+  "fmt"
+  "html"
+  "io"
+  "net/http"
+  "strconv"
+  "strings"
+  "sync"
+
+  "github.com/fmorenovr/gods/trees"
+)
+
+// Handler serves one tree over HTTP:
+//
+//   GET  /              page with the tree and insert/remove/clear controls
+//   GET  /tree.svg      the tree as an SVG image, ?highlight=1,2 outlines keys
+//   GET  /tree.json     the tree as a State
+//   POST /insert?key=1  inserts keys (a comma separated list), answers the new State
+//   POST /remove?key=1  removes keys, the heap pops its top once per key (once without keys)
+//   POST /clear         removes everything
+//
+// With trace=1 the mutations answer the State with one Frame per balancing step, the
+// page plays them to animate rotations and splits. Handler is safe for concurrent use.
+type Handler struct {
+  mu    sync.Mutex
+  kind  string
+  order int
+  tree  tree
+  mux   *http.ServeMux
+}
+
+// State is the JSON form of the hosted tree
+type State struct {
+  Kind   string        `json:"kind"`
+  Order  int           `json:"order,omitempty"`
+  Size   int           `json:"size"`
+  Keys   []int         `json:"keys"`
+  Graph  *gotree.Graph `json:"graph"`
+  Frames []Frame       `json:"frames,omitempty"`
+}
+
+// Frame is the tree drawn just before a balancing step, the keys of the step highlighted.
+// The last frame of a trace has no event and shows the tree after the operation.
+type Frame struct {
+  Event *gotree.Event `json:"event,omitempty"`
+  SVG   string        `json:"svg"`
+}
+
+// NewHandler returns a handler hosting an empty tree of the given kind (see Kinds),
+// order is the order of a btree and is ignored by the other kinds
+func NewHandler(kind string, order int) (*Handler, error) {
+  t, err := newTree(kind, order)
+  if err != nil {
+    return nil, err
+  }
+  h := &Handler{kind: kind, order: order, tree: t, mux: http.NewServeMux()}
+  if kind != "btree" {
+    h.order = 0
+  }
+  h.mux.HandleFunc("GET /{$}", h.page)
+  h.mux.HandleFunc("GET /tree.svg", h.svg)
+  h.mux.HandleFunc("GET /tree.json", h.json)
+  h.mux.HandleFunc("POST /insert", h.mutate(tree.Insert, false))
+  h.mux.HandleFunc("POST /remove", h.mutate(tree.Remove, kind == "heap"))
+  h.mux.HandleFunc("POST /clear", h.clear)
+  return h, nil
+}
+
+// Insert inserts keys into the hosted tree, as POST /insert does
+func (h *Handler) Insert(keys ...int) {
+  h.mu.Lock()
+  defer h.mu.Unlock()
+  for _, key := range keys {
+    h.tree.Insert(key)
+  }
+}
+
+// ServeHTTP implements http.Handler
+func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+  h.mux.ServeHTTP(w, r)
+}
+
+func (h *Handler) page(w http.ResponseWriter, r *http.Request) {
+  w.Header().Set("Content-Type", "text/html; charset=utf-8")
+  io.WriteString(w, strings.ReplaceAll(page, "{{kind}}", html.EscapeString(h.kind)))
+}
+
+func (h *Handler) svg(w http.ResponseWriter, r *http.Request) {
+  highlight, err := parseKeys(r.FormValue("highlight"))
+  if err != nil {
+    http.Error(w, err.Error(), http.StatusBadRequest)
+    return
+  }
+  h.mu.Lock()
+  defer h.mu.Unlock()
+  w.Header().Set("Content-Type", "image/svg+xml")
+  h.graph(highlight).WriteSVG(w)
+}
+
+func (h *Handler) json(w http.ResponseWriter, r *http.Request) {
+  h.mu.Lock()
+  defer h.mu.Unlock()
+  h.writeState(w, nil)
+}
+
+func (h *Handler) clear(w http.ResponseWriter, r *http.Request) {
+  h.mu.Lock()
+  defer h.mu.Unlock()
+  h.tree.Clear()
+  h.writeState(w, nil)
+}
+
+// mutate returns the handler applying apply to every key of the request,
+// once to a zero key if keyless and the request has none
+func (h *Handler) mutate(apply func(tree, int), keyless bool) http.HandlerFunc {
+  return func(w http.ResponseWriter, r *http.Request) {
+    keys, err := parseKeys(r.FormValue("key"))
+    if err == nil && len(keys) == 0 {
+      if keyless {
+        keys = []int{0}
+      } else {
+        err = fmt.Errorf("treeviz: missing key")
+      }
+    }
+    if err != nil {
+      http.Error(w, err.Error(), http.StatusBadRequest)
+      return
+    }
+    h.mu.Lock()
+    defer h.mu.Unlock()
+    var frames []Frame
+    trace := r.FormValue("trace") == "1"
+    if trace {
+      h.tree.SetTracer(gotree.TracerFunc(func(event gotree.Event) {
+        if event.Kind == gotree.StepEvent {
+          frames = append(frames, h.frame(&event))
+        }
+      }))
+      defer h.tree.SetTracer(nil)
+    }
+    for _, key := range keys {
+      apply(h.tree, key)
+    }
+    if trace {
+      frames = append(frames, h.frame(nil))
+    }
+    h.writeState(w, frames)
+  }
+}
+
+// frame draws the tree with the keys of event highlighted
+func (h *Handler) frame(event *gotree.Event) Frame {
+  var highlight []int
+  if event != nil {
+    highlight, _, _ = gotree.DecodeEvent[int, any](*event)
+  }
+  var svg strings.Builder
+  h.graph(highlight).WriteSVG(&svg)
+  return Frame{Event: event, SVG: svg.String()}
+}
+
+func (h *Handler) graph(highlight []int) *gotree.Graph {
+  return h.tree.Graph(gotree.ExportOptions[int]{Name: h.kind, Highlight: highlight})
+}
+
+func (h *Handler) writeState(w http.ResponseWriter, frames []Frame) {
+  w.Header().Set("Content-Type", "application/json")
+  json.NewEncoder(w).Encode(State{
+    Kind:   h.kind,
+    Order:  h.order,
+    Size:   h.tree.Size(),
+    Keys:   h.tree.Keys(),
+    Graph:  h.graph(nil),
+    Frames: frames,
+  })
+}
+
+// parseKeys parses a comma separated list of int keys, the empty string is no key
+func parseKeys(list string) ([]int, error) {
+  var keys []int
+  for _, field := range strings.Split(list, ",") {
+    if field = strings.TrimSpace(field); field == "" {
+      continue
+    }
+    key, err := strconv.Atoi(field)
+    if err != nil {
+      return nil, fmt.Errorf("treeviz: key %q is not an integer", field)
+    }
+    keys = append(keys, key)
+  }
+  return keys, nil
+}
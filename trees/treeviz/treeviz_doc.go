@@ -0,0 +1,5 @@
+// Package treeviz serves a live tree over HTTP: a page to insert and remove keys, the tree
+// drawn as SVG or returned as JSON, and optionally every rotation and split of an operation
+// as a step by step animation. cmd/godsviz runs it as a standalone program, Handler embeds it
+// in any http.ServeMux.
+package treeviz
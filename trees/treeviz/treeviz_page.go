@@ -0,0 +1,66 @@
+package treeviz
+
+// page is the single page application of the handler, {{kind}} is replaced by the hosted kind
+const page = `<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>gods {{kind}}</title>
+<style>
+  body { font-family: sans-serif; margin: 1.5em; }
+  #tree { margin-top: 1em; overflow: auto; }
+  #step { font-family: monospace; color: #555; min-height: 1.2em; }
+</style>
+</head>
+<body>
+<h1>{{kind}}</h1>
+<form id="controls">
+  <input id="key" placeholder="keys, e.g. 5 or 1,2,3" autofocus>
+  <button data-action="insert">Insert</button>
+  <button data-action="remove">Remove</button>
+  <button data-action="clear">Clear</button>
+  <label><input id="animate" type="checkbox"> animate steps</label>
+</form>
+<div id="step"></div>
+<div id="tree"></div>
+<script>
+  const tree = document.getElementById("tree");
+  const step = document.getElementById("step");
+  let timer = null;
+
+  function show(state) {
+    clearInterval(timer);
+    step.textContent = "size " + state.size + ": " + state.keys.join(" ");
+    if (!state.frames) {
+      fetch("tree.svg").then(r => r.text()).then(svg => { tree.innerHTML = svg; });
+      return;
+    }
+    let i = 0;
+    const next = () => {
+      const frame = state.frames[i++];
+      tree.innerHTML = frame.svg;
+      step.textContent = frame.event ? frame.event.name + " " + JSON.stringify(frame.event.keys) : "size " + state.size + ": " + state.keys.join(" ");
+      if (i === state.frames.length) clearInterval(timer);
+    };
+    next();
+    timer = setInterval(next, 900);
+  }
+
+  document.getElementById("controls").addEventListener("click", event => {
+    const action = event.target.dataset.action;
+    if (!action) return;
+    event.preventDefault();
+    const params = new URLSearchParams();
+    params.set("key", document.getElementById("key").value);
+    if (document.getElementById("animate").checked) params.set("trace", "1");
+    fetch(action, { method: "POST", body: params }).then(r => {
+      if (!r.ok) return r.text().then(text => { step.textContent = text; });
+      return r.json().then(show);
+    });
+  });
+
+  fetch("tree.json").then(r => r.json()).then(show);
+</script>
+</body>
+</html>
+`
@@ -0,0 +1,121 @@
+package treeviz
+
+import (
+  "encoding/json"
+  "net/http"
+  "net/http/httptest"
+  "slices"
+  "strings"
+  "testing"
+)
+
+// do sends a request to h and decodes the State it answers, failing t on an unexpected status
+func do(t *testing.T, h http.Handler, method, target string, status int) State {
+  t.Helper()
+  recorder := httptest.NewRecorder()
+  h.ServeHTTP(recorder, httptest.NewRequest(method, target, nil))
+  if recorder.Code != status {
+    t.Fatalf("%v %v: got status %v expected %v: %v", method, target, recorder.Code, status, recorder.Body)
+  }
+  var state State
+  if status == http.StatusOK && strings.HasPrefix(recorder.Header().Get("Content-Type"), "application/json") {
+    if err := json.NewDecoder(recorder.Body).Decode(&state); err != nil {
+      t.Fatalf("%v %v: %v", method, target, err)
+    }
+  }
+  return state
+}
+
+func TestHandlerKinds(t *testing.T) {
+  for _, kind := range Kinds {
+    h, err := NewHandler(kind, 3)
+    if err != nil {
+      t.Fatalf("Got %v expected %v", err, nil)
+    }
+    state := do(t, h, "POST", "/insert?key=3,1,2", http.StatusOK)
+    if actualValue, expectedValue := state.Size, 3; actualValue != expectedValue {
+      t.Errorf("%v: got %v expected %v", kind, actualValue, expectedValue)
+    }
+    if actualValue, expectedValue := len(state.Graph.Nodes), 1; actualValue < expectedValue {
+      t.Errorf("%v: got %v nodes expected at least %v", kind, actualValue, expectedValue)
+    }
+    state = do(t, h, "POST", "/remove?key=1", http.StatusOK)
+    if actualValue, expectedValue := state.Size, 2; actualValue != expectedValue {
+      t.Errorf("%v: got %v expected %v", kind, actualValue, expectedValue)
+    }
+    state = do(t, h, "POST", "/clear", http.StatusOK)
+    if actualValue, expectedValue := state.Size, 0; actualValue != expectedValue {
+      t.Errorf("%v: got %v expected %v", kind, actualValue, expectedValue)
+    }
+  }
+}
+
+func TestHandlerSVGAndPage(t *testing.T) {
+  h, _ := NewHandler("redblack", 0)
+  h.Insert(1, 2, 3)
+  recorder := httptest.NewRecorder()
+  h.ServeHTTP(recorder, httptest.NewRequest("GET", "/tree.svg?highlight=2", nil))
+  if actualValue, expectedValue := recorder.Header().Get("Content-Type"), "image/svg+xml"; actualValue != expectedValue {
+    t.Errorf("Got %v expected %v", actualValue, expectedValue)
+  }
+  svg := recorder.Body.String()
+  for _, expectedValue := range []string{"<svg", `fill="red"`, `stroke="#ff8c00"`, ">2</text>", "</svg>"} {
+    if !strings.Contains(svg, expectedValue) {
+      t.Errorf("Got %v expected it to contain %v", svg, expectedValue)
+    }
+  }
+  recorder = httptest.NewRecorder()
+  h.ServeHTTP(recorder, httptest.NewRequest("GET", "/", nil))
+  if page := recorder.Body.String(); !strings.Contains(page, "<h1>redblack</h1>") {
+    t.Errorf("Got %v expected the page of redblack", page)
+  }
+  state := do(t, h, "GET", "/tree.json", http.StatusOK)
+  if actualValue, expectedValue := state.Keys, []int{1, 2, 3}; !slices.Equal(actualValue, expectedValue) {
+    t.Errorf("Got %v expected %v", actualValue, expectedValue)
+  }
+}
+
+func TestHandlerTrace(t *testing.T) {
+  h, _ := NewHandler("avl", 0)
+  state := do(t, h, "POST", "/insert?key=1,2,3&trace=1", http.StatusOK)
+  var steps []string
+  for _, frame := range state.Frames {
+    if frame.Event != nil {
+      steps = append(steps, frame.Event.Name)
+    }
+    if !strings.HasPrefix(frame.SVG, "<svg") {
+      t.Errorf("Got %v expected an SVG frame", frame.SVG)
+    }
+  }
+  if actualValue, expectedValue := steps, []string{"rotateLeft"}; !slices.Equal(actualValue, expectedValue) {
+    t.Errorf("Got %v expected %v", actualValue, expectedValue)
+  }
+  if last := state.Frames[len(state.Frames)-1]; last.Event != nil {
+    t.Errorf("Got %v expected the final frame last", last.Event)
+  }
+  if !strings.Contains(state.Frames[0].SVG, `stroke="#ff8c00"`) {
+    t.Errorf("Got %v expected the rotated keys highlighted", state.Frames[0].SVG)
+  }
+  state = do(t, h, "POST", "/insert?key=4", http.StatusOK)
+  if state.Frames != nil {
+    t.Errorf("Got %v expected no frames without trace", state.Frames)
+  }
+}
+
+func TestHandlerErrors(t *testing.T) {
+  if _, err := NewHandler("splay", 0); err == nil {
+    t.Errorf("Got %v expected an unknown kind error", err)
+  }
+  if _, err := NewHandler("btree", 2); err == nil {
+    t.Errorf("Got %v expected an order error", err)
+  }
+  h, _ := NewHandler("bst", 0)
+  do(t, h, "POST", "/insert?key=x", http.StatusBadRequest)
+  do(t, h, "POST", "/insert", http.StatusBadRequest)
+  do(t, h, "GET", "/insert?key=1", http.StatusMethodNotAllowed)
+  heap, _ := NewHandler("heap", 0)
+  heap.Insert(5, 1, 3)
+  if actualValue, expectedValue := do(t, heap, "POST", "/remove", http.StatusOK).Size, 2; actualValue != expectedValue {
+    t.Errorf("Got %v expected %v", actualValue, expectedValue)
+  }
+}
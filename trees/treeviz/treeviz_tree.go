@@ -0,0 +1,105 @@
+package treeviz
+
+import (
+  "fmt"
+
+  "github.com/fmorenovr/gods/trees"
+  "github.com/fmorenovr/gods/trees/avltree"
+  "github.com/fmorenovr/gods/trees/binaryheap"
+  "github.com/fmorenovr/gods/trees/bstree"
+  "github.com/fmorenovr/gods/trees/btree"
+  "github.com/fmorenovr/gods/trees/redblacktree"
+  "github.com/fmorenovr/gods/utils"
+)
+
+// Kinds lists the structures a Handler can host
+var Kinds = []string{"avl", "bst", "btree", "redblack", "heap"}
+
+// tree is the structure hosted by a Handler, one of the trees or the heap over int keys
+type tree interface {
+  Insert(key int)
+  Remove(key int) // the heap pops its top, whatever the key
+  Clear()
+  Size() int
+  Keys() []int
+  Graph(opts gotree.ExportOptions[int]) *gotree.Graph
+  SetTracer(tracer gotree.Tracer)
+}
+
+// newTree returns an empty structure of the given kind, order is only used by btree
+func newTree(kind string, order int) (tree, error) {
+  switch kind {
+  case "avl":
+    return avlTree{avltree.NewAVLTree[int, int]()}, nil
+  case "bst":
+    return bsTree{bstree.NewBSTree[int, int]()}, nil
+  case "btree":
+    t, err := btree.New[int, int](order, utils.OrderedComparator[int]())
+    if err != nil {
+      return nil, fmt.Errorf("treeviz: %w", err)
+    }
+    return bTree{t}, nil
+  case "redblack":
+    return redBlackTree{redblacktree.New[int, int]()}, nil
+  case "heap":
+    return heap{binaryheap.New[int]()}, nil
+  }
+  return nil, fmt.Errorf("treeviz: unknown kind %q, want one of %v", kind, Kinds)
+}
+
+type avlTree struct {
+  *avltree.AVLTree[int, int]
+}
+
+func (t avlTree) Insert(key int) {
+  t.AVLTree.Insert(key, key)
+}
+
+type bsTree struct {
+  *bstree.BSTree[int, int]
+}
+
+func (t bsTree) Insert(key int) {
+  t.BSTree.Insert(key, key)
+}
+
+// Remove takes away one duplicate of key, so that counts go down one at a time
+func (t bsTree) Remove(key int) {
+  t.BSTree.RemoveOne(key)
+}
+
+// SetTracer does nothing, a plain BST has no balancing steps to report
+func (t bsTree) SetTracer(tracer gotree.Tracer) {
+}
+
+type bTree struct {
+  *btree.BTree[int, int]
+}
+
+func (t bTree) Insert(key int) {
+  t.BTree.Put(key, key)
+}
+
+type redBlackTree struct {
+  *redblacktree.Tree[int, int]
+}
+
+func (t redBlackTree) Insert(key int) {
+  t.Tree.Put(key, key)
+}
+
+type heap struct {
+  *binaryheap.Heap[int]
+}
+
+func (h heap) Insert(key int) {
+  h.Heap.Push(key)
+}
+
+func (h heap) Remove(key int) {
+  h.Heap.Pop()
+}
+
+func (h heap) Keys() []int {
+  return h.Heap.Values()
+}
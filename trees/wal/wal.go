@@ -0,0 +1,303 @@
+package wal
+
+import (
+  "encoding"
+  "errors"
+  "fmt"
+  "os"
+  "path/filepath"
+  "time"
+
+  "github.com/fmorenovr/gods/trees"
+  "github.com/fmorenovr/gods/utils"
+)
+
+// Names of the files in the directory of a Log
+const (
+  SnapshotFile = "snapshot"
+  LogFile      = "wal"
+)
+
+// DefaultInterval is the sync interval of SyncInterval when Options.Interval is zero
+const DefaultInterval = time.Second
+
+// ErrClosed is returned by every operation on a closed log
+var ErrClosed = errors.New("wal: log is closed")
+
+// SyncPolicy tells when appended records are flushed to stable storage
+type SyncPolicy int
+
+const (
+  // SyncAlways flushes every record before Put or Remove returns: nothing acknowledged is lost
+  SyncAlways SyncPolicy = iota
+  // SyncInterval flushes with the first append made once Options.Interval has passed since the
+  // last flush. Nothing flushes an idle log: records appended before a pause stay unflushed until
+  // the next append, Sync or Close, call Sync when going idle to bound what a crash loses
+  SyncInterval
+  // SyncNever leaves flushing to Sync, Compact, Close and the operating system
+  SyncNever
+)
+
+// Tree is a sorted map whose binary form is its snapshot, as BTree, AVLTree and redblacktree.Tree
+type Tree[K, V any] interface {
+  gotree.OrderedMap[K, V]
+  encoding.BinaryMarshaler
+  encoding.BinaryUnmarshaler
+}
+
+// Options of Open, zero fields take the defaults
+type Options[K, V any] struct {
+  Sync       SyncPolicy     // When records are flushed, SyncAlways by default
+  Interval   time.Duration  // Flush interval of SyncInterval, DefaultInterval when zero
+  KeyCodec   utils.Codec[K] // Encodes keys in the records, nil for utils.BinaryCodec
+  ValueCodec utils.Codec[V] // Encodes values in the records, nil for utils.BinaryCodec
+}
+
+// Op is an operation of a batch: a Put of Key and Value, or a Remove of Key
+type Op[K, V any] struct {
+  Key    K
+  Value  V
+  Remove bool
+}
+
+// Log writes ahead the Put and Remove operations of a tree
+type Log[K, V any] struct {
+  dir       string
+  tree      Tree[K, V]
+  file      *os.File
+  options   Options[K, V]
+  records   int       // records in the log since the snapshot
+  truncated int64     // bytes of a torn record dropped on open
+  lastSync  time.Time // time of the last flush
+  err       error     // first write error, every later operation returns it
+}
+
+// Open loads the snapshot of the directory dir into tree and replays the log on it, then returns
+// the Log through which the tree must be modified from then on. The directory is created if it
+// does not exist; without a snapshot the log is replayed on tree as it is, usually empty. The
+// snapshot is read with the codecs of the tree, the records with those of options. A damaged log
+// is an error that leaves the tree with the snapshot alone, none of its records applied.
+func Open[K, V any](dir string, tree Tree[K, V], options Options[K, V]) (*Log[K, V], error) {
+  if options.Interval == 0 {
+    options.Interval = DefaultInterval
+  }
+  if options.KeyCodec == nil {
+    options.KeyCodec = utils.BinaryCodec[K]{}
+  }
+  if options.ValueCodec == nil {
+    options.ValueCodec = utils.BinaryCodec[V]{}
+  }
+  if err := os.MkdirAll(dir, 0o755); err != nil {
+    return nil, err
+  }
+  snapshot, err := os.ReadFile(filepath.Join(dir, SnapshotFile))
+  if err == nil {
+    if err := tree.UnmarshalBinary(snapshot); err != nil {
+      return nil, fmt.Errorf("wal: %v: %w", SnapshotFile, err)
+    }
+  } else if !errors.Is(err, os.ErrNotExist) {
+    return nil, err
+  }
+  file, err := os.OpenFile(filepath.Join(dir, LogFile), os.O_RDWR|os.O_CREATE|os.O_APPEND, 0o644)
+  if err != nil {
+    return nil, err
+  }
+  l := &Log[K, V]{dir: dir, tree: tree, file: file, options: options, lastSync: time.Now()}
+  if err := l.recover(); err != nil {
+    file.Close()
+    return nil, fmt.Errorf("wal: %v: %w", LogFile, err)
+  }
+  return l, nil
+}
+
+// recover replays the log on the tree and truncates a torn record at its end
+func (l *Log[K, V]) recover() error {
+  data, err := os.ReadFile(l.file.Name())
+  if err != nil {
+    return err
+  }
+  // every record is decoded before the first is applied, a damaged log leaves the tree untouched
+  ops, valid, records, err := replay(data, l.options.KeyCodec, l.options.ValueCodec)
+  if err != nil {
+    return err
+  }
+  for _, op := range ops {
+    op.apply(l.tree)
+  }
+  l.records = records
+  if valid < len(data) {
+    l.truncated = int64(len(data) - valid)
+    if err := l.file.Truncate(int64(valid)); err != nil {
+      return err
+    }
+    return l.file.Sync()
+  }
+  return nil
+}
+
+// Tree returns the tree of the log, to be read but modified through the log only
+func (l *Log[K, V]) Tree() Tree[K, V] {
+  return l.tree
+}
+
+// Records returns the number of records in the log since the last snapshot
+func (l *Log[K, V]) Records() int {
+  return l.records
+}
+
+// Truncated returns the size in bytes of the torn record dropped when the log was opened, 0 if none
+func (l *Log[K, V]) Truncated() int64 {
+  return l.truncated
+}
+
+// Put logs the operation then puts key and value in the tree
+func (l *Log[K, V]) Put(key K, value V) error {
+  record, err := encodeRecord(putRecord, key, value, l.options.KeyCodec, l.options.ValueCodec)
+  if err != nil {
+    return err
+  }
+  if err := l.append(record); err != nil {
+    return err
+  }
+  l.tree.Put(key, value)
+  return nil
+}
+
+// Remove logs the operation then removes key from the tree
+func (l *Log[K, V]) Remove(key K) error {
+  var value V
+  record, err := encodeRecord(removeRecord, key, value, l.options.KeyCodec, l.options.ValueCodec)
+  if err != nil {
+    return err
+  }
+  if err := l.append(record); err != nil {
+    return err
+  }
+  l.tree.Remove(key)
+  return nil
+}
+
+// Apply logs the operations as a single record then applies them to the tree in order: after a
+// crash either all of them are replayed or none. An empty batch logs nothing.
+func (l *Log[K, V]) Apply(ops ...Op[K, V]) error {
+  if len(ops) == 0 {
+    return l.err
+  }
+  record, err := encodeBatch(ops, l.options.KeyCodec, l.options.ValueCodec)
+  if err != nil {
+    return err
+  }
+  if err := l.append(record); err != nil {
+    return err
+  }
+  for _, op := range ops {
+    op.apply(l.tree)
+  }
+  return nil
+}
+
+// append writes record at the end of the log and flushes it as the policy says
+func (l *Log[K, V]) append(record []byte) error {
+  if l.err != nil {
+    return l.err
+  }
+  if _, err := l.file.Write(record); err != nil {
+    return l.fail(err)
+  }
+  l.records++
+  switch l.options.Sync {
+  case SyncAlways:
+    return l.Sync()
+  case SyncInterval:
+    if time.Since(l.lastSync) >= l.options.Interval {
+      return l.Sync()
+    }
+  }
+  return nil
+}
+
+// Sync flushes the log to stable storage
+func (l *Log[K, V]) Sync() error {
+  if l.err != nil {
+    return l.err
+  }
+  if err := l.file.Sync(); err != nil {
+    return l.fail(err)
+  }
+  l.lastSync = time.Now()
+  return nil
+}
+
+// Compact writes the tree as the new snapshot and empties the log. The snapshot replaces the old
+// one atomically, by renaming; a crash before the log is emptied replays it on the new snapshot,
+// which changes nothing since the snapshot already holds the outcome of every record.
+func (l *Log[K, V]) Compact() error {
+  if l.err != nil {
+    return l.err
+  }
+  data, err := l.tree.MarshalBinary()
+  if err != nil {
+    return err
+  }
+  // the old snapshot and the log stay valid until the rename, a failure leaves them as they were
+  if err := WriteFile(filepath.Join(l.dir, SnapshotFile), data); err != nil {
+    return err
+  }
+  if err := l.file.Truncate(0); err != nil {
+    return l.fail(err)
+  }
+  l.records = 0
+  return l.Sync()
+}
+
+// Close flushes and closes the log, the tree stays as it is
+func (l *Log[K, V]) Close() error {
+  if l.err == ErrClosed {
+    return ErrClosed
+  }
+  err := l.Sync()
+  if closeErr := l.file.Close(); err == nil {
+    err = closeErr
+  }
+  l.err = ErrClosed
+  return err
+}
+
+// fail makes err the sticky error of the log: after a failed write the end of the log is unknown
+func (l *Log[K, V]) fail(err error) error {
+  if l.err == nil {
+    l.err = err
+  }
+  return err
+}
+
+// WriteFile replaces the file at path with data, through a synced temporary file renamed over it:
+// a crash leaves either the old file or the new one, never a part of it
+func WriteFile(path string, data []byte) error {
+  temporary := path + ".tmp"
+  file, err := os.Create(temporary)
+  if err != nil {
+    return err
+  }
+  if _, err := file.Write(data); err != nil {
+    file.Close()
+    return err
+  }
+  if err := file.Sync(); err != nil {
+    file.Close()
+    return err
+  }
+  if err := file.Close(); err != nil {
+    return err
+  }
+  if err := os.Rename(temporary, path); err != nil {
+    return err
+  }
+  // the rename is durable once the directory is
+  dir, err := os.Open(filepath.Dir(path))
+  if err != nil {
+    return err
+  }
+  defer dir.Close()
+  return dir.Sync()
+}
@@ -0,0 +1,14 @@
+// Package wal makes the mutations of a tree durable with a write-ahead log.
+//
+// A Log owns a directory holding the last snapshot of the tree, its MarshalBinary form, and the
+// log of the Put and Remove operations made since, each record checksummed; Apply logs a batch of
+// them as one record, replayed all or not at all. Opening the directory loads the snapshot and
+// replays the log into the tree, a BTree, AVLTree or redblacktree.Tree; Compact writes a new
+// snapshot and empties the log. Records reach stable storage according to the SyncPolicy of the Log.
+//
+// A crash in the middle of an append leaves a torn record at the end of the log: it is dropped on
+// open, the operation it held was never acknowledged. A damaged record followed by a valid one is
+// an error wrapping gotree.ErrCorruptData, and the log is left as it is.
+//
+// Structure is not thread safe.
+package wal
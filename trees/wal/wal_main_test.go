@@ -0,0 +1,244 @@
+package wal
+
+import (
+  "encoding/binary"
+  "errors"
+  "math/rand"
+  "os"
+  "path/filepath"
+  "slices"
+  "strconv"
+  "testing"
+  "time"
+
+  "github.com/fmorenovr/gods/trees"
+  "github.com/fmorenovr/gods/trees/avltree"
+  "github.com/fmorenovr/gods/trees/btree"
+  "github.com/fmorenovr/gods/trees/redblacktree"
+)
+
+func openLog(t *testing.T, dir string, tree Tree[int, string], options Options[int, string]) *Log[int, string] {
+  t.Helper()
+  l, err := Open(dir, tree, options)
+  if err != nil {
+    t.Fatalf("Got %v expected %v", err, nil)
+  }
+  t.Cleanup(func() { l.Close() })
+  return l
+}
+
+// fill runs random puts and removes through l and returns the expected keys and values
+func fill(t *testing.T, l *Log[int, string], seed int64, model map[int]string) {
+  t.Helper()
+  r := rand.New(rand.NewSource(seed))
+  for i := 0; i < 500; i++ {
+    key := r.Intn(200)
+    if r.Intn(3) == 0 {
+      if err := l.Remove(key); err != nil {
+        t.Fatalf("Got %v expected %v", err, nil)
+      }
+      delete(model, key)
+    } else {
+      if err := l.Put(key, strconv.Itoa(i)); err != nil {
+        t.Fatalf("Got %v expected %v", err, nil)
+      }
+      model[key] = strconv.Itoa(i)
+    }
+  }
+}
+
+func assertTree(t *testing.T, tree Tree[int, string], model map[int]string) {
+  t.Helper()
+  keys := make([]int, 0, len(model))
+  for key := range model {
+    keys = append(keys, key)
+  }
+  slices.Sort(keys)
+  var actualKeys []int
+  for key, value := range tree.All() {
+    if value != model[key] {
+      t.Errorf("Got %v expected %v for key %v", value, model[key], key)
+    }
+    actualKeys = append(actualKeys, key)
+  }
+  if !slices.Equal(actualKeys, keys) {
+    t.Errorf("Got %v expected %v", actualKeys, keys)
+  }
+}
+
+func TestLogReopen(t *testing.T) {
+  trees := map[string]func() Tree[int, string]{
+    "btree":        func() Tree[int, string] { return btree.NewBTree[int, string](4) },
+    "avltree":      func() Tree[int, string] { return avltree.NewAVLTree[int, string]() },
+    "redblacktree": func() Tree[int, string] { return redblacktree.New[int, string]() },
+  }
+  for name, newTree := range trees {
+    t.Run(name, func(t *testing.T) {
+      dir := t.TempDir()
+      model := map[int]string{}
+      l := openLog(t, dir, newTree(), Options[int, string]{})
+      fill(t, l, 1, model)
+      if err := l.Close(); err != nil {
+        t.Errorf("Got %v expected %v", err, nil)
+      }
+      if err := l.Put(1, "1"); !errors.Is(err, ErrClosed) {
+        t.Errorf("Got %v expected %v", err, ErrClosed)
+      }
+
+      l = openLog(t, dir, newTree(), Options[int, string]{Sync: SyncNever})
+      assertTree(t, l.Tree(), model)
+      if actualValue, expectedValue := l.Records(), 500; actualValue != expectedValue {
+        t.Errorf("Got %v expected %v", actualValue, expectedValue)
+      }
+      if err := l.Compact(); err != nil {
+        t.Errorf("Got %v expected %v", err, nil)
+      }
+      if info, _ := os.Stat(filepath.Join(dir, LogFile)); info.Size() != 0 || l.Records() != 0 {
+        t.Errorf("Got %v bytes expected an empty log after Compact", info.Size())
+      }
+      fill(t, l, 2, model)
+      l.Close()
+
+      l = openLog(t, dir, newTree(), Options[int, string]{})
+      assertTree(t, l.Tree(), model)
+    })
+  }
+}
+
+func TestLogTornTail(t *testing.T) {
+  dir := t.TempDir()
+  l := openLog(t, dir, btree.NewBTree[int, string](3), Options[int, string]{})
+  model := map[int]string{}
+  for key := 0; key < 10; key++ {
+    l.Put(key, strconv.Itoa(key))
+    model[key] = strconv.Itoa(key)
+  }
+  l.Close()
+  path := filepath.Join(dir, LogFile)
+  data, _ := os.ReadFile(path)
+
+  for _, torn := range [][]byte{
+    data[:len(data)-3], // the end of the last record never written
+    data[:len(data)-len(data)/10/2],
+    append(slices.Clone(data[:len(data)-2]), 0, 0),  // its last bytes zeroed
+    append(slices.Clone(data), 0, 0, 0),             // a header cut short
+    append(slices.Clone(data), make([]byte, 20)...), // a header never written
+  } {
+    os.WriteFile(path, torn, 0o644)
+    l = openLog(t, dir, btree.NewBTree[int, string](3), Options[int, string]{})
+    expected := map[int]string{}
+    for key, value := range model {
+      expected[key] = value
+    }
+    if len(torn) <= len(data) {
+      delete(expected, 9)
+    }
+    assertTree(t, l.Tree(), expected)
+    if l.Truncated() == 0 {
+      t.Errorf("Got %v expected the torn record dropped", l.Truncated())
+    }
+    if err := l.Put(20, "20"); err != nil {
+      t.Errorf("Got %v expected %v", err, nil)
+    }
+    l.Close()
+    l = openLog(t, dir, btree.NewBTree[int, string](3), Options[int, string]{})
+    expected[20] = "20"
+    assertTree(t, l.Tree(), expected)
+    if l.Truncated() != 0 {
+      t.Errorf("Got %v expected nothing to drop", l.Truncated())
+    }
+    l.Close()
+  }
+}
+
+func TestLogCorrupt(t *testing.T) {
+  dir := t.TempDir()
+  l := openLog(t, dir, avltree.NewAVLTree[int, string](), Options[int, string]{})
+  for key := 0; key < 10; key++ {
+    l.Put(key, strconv.Itoa(key))
+  }
+  l.Close()
+  path := filepath.Join(dir, LogFile)
+  data, _ := os.ReadFile(path)
+  data[recordHeaderSize+1] ^= 1 // in the payload of the first record
+  os.WriteFile(path, data, 0o644)
+
+  _, err := Open[int, string](dir, avltree.NewAVLTree[int, string](), Options[int, string]{})
+  var decodeError *gotree.DecodeError
+  if !errors.Is(err, gotree.ErrCorruptData) || !errors.As(err, &decodeError) {
+    t.Fatalf("Got %v expected a *gotree.DecodeError wrapping %v", err, gotree.ErrCorruptData)
+  }
+  if decodeError.Entry != 0 || decodeError.Offset != 0 {
+    t.Errorf("Got entry %v at offset %v expected entry 0 at offset 0", decodeError.Entry, decodeError.Offset)
+  }
+
+  // a damaged length in the middle of the log is no torn tail, nothing is dropped nor applied
+  data[recordHeaderSize+1] ^= 1
+  second := recordHeaderSize + int(binary.BigEndian.Uint32(data))
+  data[second+3] ^= 0x40
+  os.WriteFile(path, data, 0o644)
+  tree := avltree.NewAVLTree[int, string]()
+  _, err = Open[int, string](dir, tree, Options[int, string]{})
+  if !errors.As(err, &decodeError) || decodeError.Entry != 1 || decodeError.Offset != int64(second) {
+    t.Errorf("Got %v expected a *gotree.DecodeError of entry 1 at offset %v", err, second)
+  }
+  if info, _ := os.Stat(path); info.Size() != int64(len(data)) || tree.Size() != 0 {
+    t.Errorf("Got %v bytes and %v keys expected the log kept and the tree untouched", info.Size(), tree.Size())
+  }
+
+  os.WriteFile(filepath.Join(dir, SnapshotFile), []byte("not a snapshot"), 0o644)
+  if _, err := Open[int, string](dir, avltree.NewAVLTree[int, string](), Options[int, string]{}); !errors.Is(err, gotree.ErrCorruptData) {
+    t.Errorf("Got %v expected %v", err, gotree.ErrCorruptData)
+  }
+}
+
+func TestLogApply(t *testing.T) {
+  dir := t.TempDir()
+  l := openLog(t, dir, btree.NewBTree[int, string](3), Options[int, string]{})
+  l.Put(1, "1")
+  if err := l.Apply(Op[int, string]{Key: 2, Value: "2"}, Op[int, string]{Key: 1, Remove: true}, Op[int, string]{Key: 3, Value: "3"}); err != nil {
+    t.Errorf("Got %v expected %v", err, nil)
+  }
+  if err := l.Apply(); err != nil {
+    t.Errorf("Got %v expected %v", err, nil)
+  }
+  model := map[int]string{2: "2", 3: "3"}
+  assertTree(t, l.Tree(), model)
+  if actualValue, expectedValue := l.Records(), 2; actualValue != expectedValue {
+    t.Errorf("Got %v expected %v", actualValue, expectedValue)
+  }
+  l.Close()
+
+  l = openLog(t, dir, btree.NewBTree[int, string](3), Options[int, string]{})
+  assertTree(t, l.Tree(), model)
+  l.Apply(Op[int, string]{Key: 4, Value: "4"}, Op[int, string]{Key: 2, Remove: true})
+  l.Close()
+
+  // a torn batch is dropped whole
+  path := filepath.Join(dir, LogFile)
+  data, _ := os.ReadFile(path)
+  os.WriteFile(path, data[:len(data)-2], 0o644)
+  l = openLog(t, dir, btree.NewBTree[int, string](3), Options[int, string]{})
+  assertTree(t, l.Tree(), model)
+  if l.Truncated() == 0 {
+    t.Errorf("Got %v expected the torn batch dropped", l.Truncated())
+  }
+}
+
+func TestLogCompactCrash(t *testing.T) {
+  dir := t.TempDir()
+  model := map[int]string{}
+  l := openLog(t, dir, redblacktree.New[int, string](), Options[int, string]{Sync: SyncInterval, Interval: time.Millisecond})
+  fill(t, l, 3, model)
+  l.Sync()
+  data, _ := os.ReadFile(filepath.Join(dir, LogFile))
+  if err := l.Compact(); err != nil {
+    t.Errorf("Got %v expected %v", err, nil)
+  }
+  l.Close()
+
+  // a crash between the new snapshot and the truncation of the log replays the log again
+  os.WriteFile(filepath.Join(dir, LogFile), data, 0o644)
+  l = openLog(t, dir, redblacktree.New[int, string](), Options[int, string]{})
+  assertTree(t, l.Tree(), model)
+}
@@ -0,0 +1,198 @@
+package wal
+
+import (
+  "encoding/binary"
+  "fmt"
+  "hash/crc32"
+
+  "github.com/fmorenovr/gods/trees"
+  "github.com/fmorenovr/gods/utils"
+)
+
+// Version is the version of the log record format.
+//
+// Version 1 records are a header of the uint32 length of the payload, the CRC-32 (IEEE) of the
+// payload and the CRC-32 of these first 8 bytes, all big endian, then the payload: the kind byte of the operation, 1 for Put and 2 for
+// Remove, a uvarint length and the bytes of the key, then for a Put the bytes of the value up to
+// the end of the payload. A batch payload is the kind byte 3 followed by the payloads of its
+// operations, each behind its uvarint length.
+const Version = 1
+
+// kinds of record
+const (
+  putRecord, removeRecord, batchRecord byte = 1, 2, 3
+)
+
+const recordHeaderSize = 12
+
+// encodeRecord returns the record of an operation, value is only written for a Put
+func encodeRecord[K, V any](kind byte, key K, value V, keys utils.Codec[K], values utils.Codec[V]) ([]byte, error) {
+  payload, err := encodePayload(kind, key, value, keys, values)
+  if err != nil {
+    return nil, err
+  }
+  return frame(payload), nil
+}
+
+// encodeBatch returns the single record of the operations of a batch
+func encodeBatch[K, V any](ops []Op[K, V], keys utils.Codec[K], values utils.Codec[V]) ([]byte, error) {
+  payload := []byte{batchRecord}
+  for _, op := range ops {
+    kind := putRecord
+    if op.Remove {
+      kind = removeRecord
+    }
+    opPayload, err := encodePayload(kind, op.Key, op.Value, keys, values)
+    if err != nil {
+      return nil, err
+    }
+    payload = binary.AppendUvarint(payload, uint64(len(opPayload)))
+    payload = append(payload, opPayload...)
+  }
+  return frame(payload), nil
+}
+
+// encodePayload returns the payload of an operation, value is only written for a Put
+func encodePayload[K, V any](kind byte, key K, value V, keys utils.Codec[K], values utils.Codec[V]) ([]byte, error) {
+  keyData, err := keys.Encode(key)
+  if err != nil {
+    return nil, fmt.Errorf("wal: key %v: %w", key, err)
+  }
+  payload := binary.AppendUvarint([]byte{kind}, uint64(len(keyData)))
+  payload = append(payload, keyData...)
+  if kind == putRecord {
+    valueData, err := values.Encode(value)
+    if err != nil {
+      return nil, fmt.Errorf("wal: value of key %v: %w", key, err)
+    }
+    payload = append(payload, valueData...)
+  }
+  return payload, nil
+}
+
+// frame puts the header of payload in front of it
+func frame(payload []byte) []byte {
+  record := binary.BigEndian.AppendUint32(nil, uint32(len(payload)))
+  record = binary.BigEndian.AppendUint32(record, crc32.ChecksumIEEE(payload))
+  record = binary.BigEndian.AppendUint32(record, crc32.ChecksumIEEE(record))
+  return append(record, payload...)
+}
+
+// replay decodes the records of data in order and returns their operations, the length of the
+// valid records, shorter than data when the last record is torn, and their number. Only the end
+// of the log may be torn: a damaged record followed by a valid one is a *gotree.DecodeError
+// wrapping gotree.ErrCorruptData.
+func replay[K, V any](data []byte, keys utils.Codec[K], values utils.Codec[V]) (ops []Op[K, V], valid int, records int, err error) {
+  for offset := 0; offset < len(data); records++ {
+    if len(data)-offset < recordHeaderSize {
+      return ops, offset, records, nil
+    }
+    header := data[offset : offset+recordHeaderSize]
+    if crc32.ChecksumIEEE(header[:8]) != binary.BigEndian.Uint32(header[8:]) {
+      // the length can't be trusted: the record is torn unless a valid one follows
+      if recordFollows(data, offset+1) {
+        return nil, 0, 0, gotree.CorruptEntry(records, int64(offset), fmt.Errorf("header checksum mismatch"))
+      }
+      return ops, offset, records, nil
+    }
+    length := binary.BigEndian.Uint32(header)
+    if uint64(length) > uint64(len(data)-offset-recordHeaderSize) {
+      // a valid header whose payload runs past the end is the last record, torn
+      return ops, offset, records, nil
+    }
+    end := offset + recordHeaderSize + int(length)
+    payload := data[offset+recordHeaderSize : end]
+    if crc32.ChecksumIEEE(payload) != binary.BigEndian.Uint32(header[4:]) {
+      // a torn write may leave the tail of the last record unwritten
+      if end == len(data) {
+        return ops, offset, records, nil
+      }
+      return nil, 0, 0, gotree.CorruptEntry(records, int64(offset), fmt.Errorf("checksum mismatch"))
+    }
+    if ops, err = decodeRecord(payload, ops, keys, values); err != nil {
+      return nil, 0, 0, gotree.CorruptEntry(records, int64(offset), err)
+    }
+    offset = end
+  }
+  return ops, len(data), records, nil
+}
+
+// recordFollows tells whether a whole record with valid checksums starts at or after from
+func recordFollows(data []byte, from int) bool {
+  for offset := from; offset+recordHeaderSize <= len(data); offset++ {
+    header := data[offset : offset+recordHeaderSize]
+    if crc32.ChecksumIEEE(header[:8]) != binary.BigEndian.Uint32(header[8:]) {
+      continue
+    }
+    length := binary.BigEndian.Uint32(header)
+    if uint64(length) > uint64(len(data)-offset-recordHeaderSize) {
+      continue
+    }
+    payload := data[offset+recordHeaderSize : offset+recordHeaderSize+int(length)]
+    if crc32.ChecksumIEEE(payload) == binary.BigEndian.Uint32(header[4:]) {
+      return true
+    }
+  }
+  return false
+}
+
+// decodeRecord appends the operations of a record payload to ops, all those of a batch or none
+func decodeRecord[K, V any](payload []byte, ops []Op[K, V], keys utils.Codec[K], values utils.Codec[V]) ([]Op[K, V], error) {
+  if len(payload) > 0 && payload[0] == batchRecord {
+    var batch []Op[K, V]
+    for rest := payload[1:]; len(rest) > 0; {
+      length, read := binary.Uvarint(rest)
+      if read <= 0 || length > uint64(len(rest)-read) {
+        return ops, fmt.Errorf("batch operation %v: truncated", len(batch))
+      }
+      op, err := decodeOp(rest[read:read+int(length)], keys, values)
+      if err != nil {
+        return ops, fmt.Errorf("batch operation %v: %w", len(batch), err)
+      }
+      batch = append(batch, op)
+      rest = rest[read+int(length):]
+    }
+    return append(ops, batch...), nil
+  }
+  op, err := decodeOp(payload, keys, values)
+  if err != nil {
+    return ops, err
+  }
+  return append(ops, op), nil
+}
+
+// decodeOp decodes the payload of a Put or a Remove
+func decodeOp[K, V any](payload []byte, keys utils.Codec[K], values utils.Codec[V]) (op Op[K, V], err error) {
+  if len(payload) == 0 {
+    return op, fmt.Errorf("empty record")
+  }
+  kind := payload[0]
+  length, read := binary.Uvarint(payload[1:])
+  if read <= 0 || length > uint64(len(payload)-1-read) {
+    return op, fmt.Errorf("truncated key")
+  }
+  keyData := payload[1+read : 1+read+int(length)]
+  if op.Key, err = keys.Decode(keyData); err != nil {
+    return op, fmt.Errorf("key: %w", err)
+  }
+  switch kind {
+  case putRecord:
+    if op.Value, err = values.Decode(payload[1+read+int(length):]); err != nil {
+      return op, fmt.Errorf("key %v, value: %w", op.Key, err)
+    }
+  case removeRecord:
+    op.Remove = true
+  default:
+    return op, fmt.Errorf("record of kind %v", kind)
+  }
+  return op, nil
+}
+
+// apply applies the operation to tree
+func (op Op[K, V]) apply(tree Tree[K, V]) {
+  if op.Remove {
+    tree.Remove(op.Key)
+  } else {
+    tree.Put(op.Key, op.Value)
+  }
+}
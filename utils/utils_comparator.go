@@ -0,0 +1,50 @@
+package utils
+
+import (
+  "cmp"
+  "reflect"
+)
+
+// Comparator compares a and b, returns a negative number if a < b,
+// zero if a == b and a positive number if a > b
+type Comparator[T any] func(a, b T) int
+
+// Operator combines a and b into a new value, used to aggregate keys
+type Operator[T any] func(a, b T) T
+
+// OrderedComparator returns the comparator of the natural order of T
+func OrderedComparator[T cmp.Ordered]() Comparator[T] {
+  return cmp.Compare[T]
+}
+
+// AddOperator returns the operator that adds (or concatenates) a and b
+func AddOperator[T cmp.Ordered]() Operator[T] {
+  return func(a, b T) T {
+    return a + b
+  }
+}
+
+// DefaultComparator returns the natural order of T when its underlying type is a number
+// or a string, so that named types like time.Duration work too, and nil otherwise.
+// Zero-value structures fall back on it when they are decoded.
+func DefaultComparator[T any]() Comparator[T] {
+  switch reflect.TypeFor[T]().Kind() {
+  case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+    return func(a, b T) int {
+      return cmp.Compare(reflect.ValueOf(a).Int(), reflect.ValueOf(b).Int())
+    }
+  case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+    return func(a, b T) int {
+      return cmp.Compare(reflect.ValueOf(a).Uint(), reflect.ValueOf(b).Uint())
+    }
+  case reflect.Float32, reflect.Float64:
+    return func(a, b T) int {
+      return cmp.Compare(reflect.ValueOf(a).Float(), reflect.ValueOf(b).Float())
+    }
+  case reflect.String:
+    return func(a, b T) int {
+      return cmp.Compare(reflect.ValueOf(a).String(), reflect.ValueOf(b).String())
+    }
+  }
+  return nil
+}
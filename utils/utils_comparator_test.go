@@ -0,0 +1,24 @@
+package utils
+
+import (
+  "testing"
+  "time"
+)
+
+func TestDefaultComparator(t *testing.T) {
+  if actualValue, expectedValue := DefaultComparator[time.Duration]()(time.Second, time.Minute), -1; actualValue != expectedValue {
+    t.Errorf("Got %v expected %v", actualValue, expectedValue)
+  }
+  if actualValue, expectedValue := DefaultComparator[uint8]()(7, 3), 1; actualValue != expectedValue {
+    t.Errorf("Got %v expected %v", actualValue, expectedValue)
+  }
+  if actualValue, expectedValue := DefaultComparator[float32]()(1.5, 1.5), 0; actualValue != expectedValue {
+    t.Errorf("Got %v expected %v", actualValue, expectedValue)
+  }
+  if actualValue, expectedValue := DefaultComparator[string]()("a", "b"), -1; actualValue != expectedValue {
+    t.Errorf("Got %v expected %v", actualValue, expectedValue)
+  }
+  if comp := DefaultComparator[struct{ X int }](); comp != nil {
+    t.Errorf("Got a comparator expected nil for a struct")
+  }
+}
@@ -0,0 +1,9 @@
+package utils
+
+// Container is the base interface that all data structures implement
+type Container[T any] interface {
+  IsEmpty() bool
+  Size() int
+  Clear()
+  Values() []T
+}
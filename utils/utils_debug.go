@@ -0,0 +1,51 @@
+package utils
+
+import (
+  "fmt"
+)
+
+// CheckComparator spot-checks comp over values: every value equals itself,
+// comp(a, b) and comp(b, a) have opposite signs, the order is transitive and
+// keys that compare equal compare the same way against every other value.
+// It returns an error describing the first violation, nil otherwise.
+func CheckComparator[T any](comp Comparator[T], values ...T) error {
+  for _, a := range values {
+    if ab := comp(a, a); ab != 0 {
+      return fmt.Errorf("comparator is not reflexive: compare(%v, %v) = %v", a, a, ab)
+    }
+  }
+  for _, a := range values {
+    for _, b := range values {
+      ab, ba := sign(comp(a, b)), sign(comp(b, a))
+      if ab != -ba {
+        return fmt.Errorf("comparator is not antisymmetric: compare(%v, %v) = %v but compare(%v, %v) = %v", a, b, ab, b, a, ba)
+      }
+      for _, c := range values {
+        bc, ac := sign(comp(b, c)), sign(comp(a, c))
+        if ab == 0 && bc != ac {
+          return fmt.Errorf("comparator is inconsistent on equal keys: %v == %v but compare(%v, %v) = %v and compare(%v, %v) = %v", a, b, a, c, ac, b, c, bc)
+        }
+        if ab < 0 && bc < 0 && ac >= 0 {
+          return fmt.Errorf("comparator is not transitive: %v < %v < %v but compare(%v, %v) = %v", a, b, c, a, c, ac)
+        }
+      }
+    }
+  }
+  return nil
+}
+
+// DebugFailure panics with a report of the operation that broke structure,
+// dump is the state of the structure afterwards. Only called when Debug is true.
+func DebugFailure(structure, operation string, err error, dump fmt.Stringer) {
+  panic(fmt.Errorf("gods debug: %v.%v: %w\n%v", structure, operation, err, dump))
+}
+
+func sign(n int) int {
+  switch {
+  case n < 0:
+    return -1
+  case n > 0:
+    return 1
+  }
+  return 0
+}
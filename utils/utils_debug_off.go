@@ -0,0 +1,7 @@
+//go:build !godsdebug
+
+package utils
+
+// Debug is true in builds with the godsdebug tag: every mutation of a tree or heap
+// re-validates the structure and spot-checks its comparator, panicking on failure
+const Debug = false
@@ -0,0 +1,48 @@
+package utils
+
+import (
+  "strings"
+  "testing"
+)
+
+func TestCheckComparator(t *testing.T) {
+  tests := []struct {
+    name     string
+    comp     Comparator[int]
+    expected string
+  }{
+    {"ordered", OrderedComparator[int](), ""},
+    {"scaled", func(a, b int) int { return 10 * (a - b) }, ""},
+    {"irreflexive", func(a, b int) int { return -1 }, "not reflexive"},
+    {"asymmetric", func(a, b int) int {
+      if a == b {
+        return 0
+      }
+      return 1
+    }, "not antisymmetric"},
+    {"tolerant", func(a, b int) int {
+      if a-b <= 1 && b-a <= 1 {
+        return 0
+      }
+      return a - b
+    }, "inconsistent on equal keys"},
+    {"rock-paper-scissors", func(a, b int) int {
+      switch {
+      case a == b:
+        return 0
+      case (a+1)%3 == b:
+        return -1
+      }
+      return 1
+    }, "not transitive"},
+  }
+  for _, test := range tests {
+    err := CheckComparator(test.comp, 0, 1, 2, 3)
+    if test.expected == "" && err != nil {
+      t.Errorf("%v: got %v expected %v", test.name, err, nil)
+    }
+    if test.expected != "" && (err == nil || !strings.Contains(err.Error(), test.expected)) {
+      t.Errorf("%v: got %v expected %q", test.name, err, test.expected)
+    }
+  }
+}
@@ -0,0 +1,5 @@
+// Package utils holds the contracts (comparators, operators, containers,
+// iterators and serializers) shared by every data structure in gods.
+//
+// See Readme.md for more info.
+package utils
@@ -0,0 +1,53 @@
+package utils
+
+// IteratorWithIndex is a stateful iterator over ordered containers whose values can be fetched by an index
+type IteratorWithIndex[T any] interface {
+  // Next moves the iterator to the next element and returns true if there was a next element
+  Next() bool
+  // Value returns the current element's value
+  Value() T
+  // Index returns the current element's index
+  Index() int
+  // Begin resets the iterator to its initial state (one-before-first)
+  Begin()
+  // First moves the iterator to the first element and returns true if there was a first element
+  First() bool
+}
+
+// IteratorWithKey is a stateful iterator over ordered containers whose elements are key/value pairs
+type IteratorWithKey[K, V any] interface {
+  // Next moves the iterator to the next element and returns true if there was a next element
+  Next() bool
+  // Value returns the current element's value
+  Value() V
+  // Key returns the current element's key
+  Key() K
+  // Begin resets the iterator to its initial state (one-before-first)
+  Begin()
+  // First moves the iterator to the first element and returns true if there was a first element
+  First() bool
+}
+
+// ReverseIteratorWithIndex is an IteratorWithIndex that can also move backwards
+type ReverseIteratorWithIndex[T any] interface {
+  // Prev moves the iterator to the previous element and returns true if there was a previous element
+  Prev() bool
+  // End moves the iterator past the last element (one-past-the-end)
+  End()
+  // Last moves the iterator to the last element and returns true if there was a last element
+  Last() bool
+
+  IteratorWithIndex[T]
+}
+
+// ReverseIteratorWithKey is an IteratorWithKey that can also move backwards
+type ReverseIteratorWithKey[K, V any] interface {
+  // Prev moves the iterator to the previous element and returns true if there was a previous element
+  Prev() bool
+  // End moves the iterator past the last element (one-past-the-end)
+  End()
+  // Last moves the iterator to the last element and returns true if there was a last element
+  Last() bool
+
+  IteratorWithKey[K, V]
+}
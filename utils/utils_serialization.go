@@ -0,0 +1,152 @@
+package utils
+
+import (
+  "encoding"
+  "encoding/binary"
+  "encoding/json"
+  "fmt"
+  "math"
+  "reflect"
+)
+
+// JSONSerializer provides JSON serialization
+type JSONSerializer interface {
+  // ToJSON outputs the JSON representation of containers's elements
+  ToJSON() ([]byte, error)
+}
+
+// JSONDeserializer provides JSON deserialization
+type JSONDeserializer interface {
+  // FromJSON populates containers's elements from the input JSON representation
+  FromJSON([]byte) error
+}
+
+// Codec encodes values of T into bytes and back, structures use it to serialize keys and
+// values whose default encoding does not fit. In JSON the bytes must be a JSON value.
+type Codec[T any] interface {
+  Encode(value T) ([]byte, error)
+  Decode(data []byte) (T, error)
+}
+
+// JSONCodec is the Codec of encoding/json, the default of every structure
+type JSONCodec[T any] struct{}
+
+// Encode returns the JSON encoding of value
+func (JSONCodec[T]) Encode(value T) ([]byte, error) {
+  return json.Marshal(value)
+}
+
+// Decode parses the JSON encoding of a T
+func (JSONCodec[T]) Decode(data []byte) (value T, err error) {
+  err = json.Unmarshal(data, &value)
+  return value, err
+}
+
+// CodecFuncs builds a Codec from a pair of functions
+type CodecFuncs[T any] struct {
+  EncodeFunc func(value T) ([]byte, error)
+  DecodeFunc func(data []byte) (T, error)
+}
+
+// Encode calls EncodeFunc
+func (c CodecFuncs[T]) Encode(value T) ([]byte, error) {
+  return c.EncodeFunc(value)
+}
+
+// Decode calls DecodeFunc
+func (c CodecFuncs[T]) Decode(data []byte) (T, error) {
+  return c.DecodeFunc(data)
+}
+
+// BinaryCodec is the compact Codec of MarshalBinary when no codec is set: integers are varints,
+// floats their IEEE 754 bits, bools one byte, strings and byte slices their raw bytes, types
+// implementing encoding.BinaryMarshaler and encoding.BinaryUnmarshaler their own encoding,
+// anything else falls back to encoding/json
+type BinaryCodec[T any] struct{}
+
+// Encode returns the binary encoding of value
+func (BinaryCodec[T]) Encode(value T) ([]byte, error) {
+  if marshaler, ok := any(value).(encoding.BinaryMarshaler); ok {
+    if _, ok := any(new(T)).(encoding.BinaryUnmarshaler); ok {
+      return marshaler.MarshalBinary()
+    }
+  }
+  v := reflect.ValueOf(&value).Elem()
+  switch v.Kind() {
+  case reflect.Bool:
+    if v.Bool() {
+      return []byte{1}, nil
+    }
+    return []byte{0}, nil
+  case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+    return binary.AppendVarint(nil, v.Int()), nil
+  case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+    return binary.AppendUvarint(nil, v.Uint()), nil
+  case reflect.Float32:
+    return binary.BigEndian.AppendUint32(nil, math.Float32bits(float32(v.Float()))), nil
+  case reflect.Float64:
+    return binary.BigEndian.AppendUint64(nil, math.Float64bits(v.Float())), nil
+  case reflect.String:
+    return []byte(v.String()), nil
+  case reflect.Slice:
+    if v.Type().Elem().Kind() == reflect.Uint8 {
+      return append([]byte{}, v.Bytes()...), nil
+    }
+  }
+  return json.Marshal(value)
+}
+
+// Decode parses the binary encoding of a T
+func (BinaryCodec[T]) Decode(data []byte) (value T, err error) {
+  if unmarshaler, ok := any(&value).(encoding.BinaryUnmarshaler); ok {
+    if _, ok := any(value).(encoding.BinaryMarshaler); ok {
+      err = unmarshaler.UnmarshalBinary(data)
+      return value, err
+    }
+  }
+  v := reflect.ValueOf(&value).Elem()
+  switch v.Kind() {
+  case reflect.Bool:
+    if len(data) != 1 || data[0] > 1 {
+      return value, fmt.Errorf("utils: invalid bool % x", data)
+    }
+    v.SetBool(data[0] == 1)
+    return value, nil
+  case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+    n, read := binary.Varint(data)
+    if read <= 0 || read != len(data) || v.OverflowInt(n) {
+      return value, fmt.Errorf("utils: invalid %v % x", v.Type(), data)
+    }
+    v.SetInt(n)
+    return value, nil
+  case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+    n, read := binary.Uvarint(data)
+    if read <= 0 || read != len(data) || v.OverflowUint(n) {
+      return value, fmt.Errorf("utils: invalid %v % x", v.Type(), data)
+    }
+    v.SetUint(n)
+    return value, nil
+  case reflect.Float32:
+    if len(data) != 4 {
+      return value, fmt.Errorf("utils: invalid %v % x", v.Type(), data)
+    }
+    v.SetFloat(float64(math.Float32frombits(binary.BigEndian.Uint32(data))))
+    return value, nil
+  case reflect.Float64:
+    if len(data) != 8 {
+      return value, fmt.Errorf("utils: invalid %v % x", v.Type(), data)
+    }
+    v.SetFloat(math.Float64frombits(binary.BigEndian.Uint64(data)))
+    return value, nil
+  case reflect.String:
+    v.SetString(string(data))
+    return value, nil
+  case reflect.Slice:
+    if v.Type().Elem().Kind() == reflect.Uint8 {
+      v.SetBytes(append([]byte{}, data...))
+      return value, nil
+    }
+  }
+  err = json.Unmarshal(data, &value)
+  return value, err
+}
@@ -0,0 +1,40 @@
+package utils
+
+import (
+  "bytes"
+  "testing"
+  "time"
+)
+
+func TestBinaryCodec(t *testing.T) {
+  assertRoundTrip(t, BinaryCodec[int]{}, -300, []byte{0xd7, 0x04})
+  assertRoundTrip(t, BinaryCodec[time.Duration]{}, time.Second, nil)
+  assertRoundTrip(t, BinaryCodec[uint16]{}, 65535, []byte{0xff, 0xff, 0x03})
+  assertRoundTrip(t, BinaryCodec[float64]{}, 1.5, []byte{0x3f, 0xf8, 0, 0, 0, 0, 0, 0})
+  assertRoundTrip(t, BinaryCodec[string]{}, "gods", []byte("gods"))
+  assertRoundTrip(t, BinaryCodec[bool]{}, true, []byte{1})
+  assertRoundTrip(t, BinaryCodec[time.Time]{}, time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC), nil)
+  assertRoundTrip(t, BinaryCodec[struct{ X int }]{}, struct{ X int }{7}, []byte(`{"X":7}`))
+  if _, err := (BinaryCodec[int8]{}).Decode([]byte{0x80, 0x04}); err == nil {
+    t.Errorf("Got %v expected an overflow error", err)
+  }
+  if _, err := (BinaryCodec[float32]{}).Decode([]byte{1}); err == nil {
+    t.Errorf("Got %v expected an error for a short float", err)
+  }
+}
+
+// assertRoundTrip encodes value with codec, checks the bytes unless expected is nil, and decodes them back
+func assertRoundTrip[T comparable](t *testing.T, codec Codec[T], value T, expected []byte) {
+  t.Helper()
+  data, err := codec.Encode(value)
+  if err != nil {
+    t.Errorf("Got %v expected %v", err, nil)
+  }
+  if expected != nil && !bytes.Equal(data, expected) {
+    t.Errorf("Got % x expected % x", data, expected)
+  }
+  decoded, err := codec.Decode(data)
+  if err != nil || decoded != value {
+    t.Errorf("Got %v, %v expected %v", decoded, err, value)
+  }
+}